@@ -8,6 +8,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"sort"
 
 	pb "github.com/google/go-tpm-tools/proto/tpm"
 	"github.com/google/go-tpm/tpm2"
@@ -49,6 +50,9 @@ func (t TLV) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary unmarshal a byte slice to a TLV.
 func (t *TLV) UnmarshalBinary(data []byte) error {
+	if len(data) < tlvTypeFieldLength+tlvLengthFieldLength {
+		return fmt.Errorf("TLV is too short to contain a Type and Length")
+	}
 	valueLength := binary.BigEndian.Uint32(data[tlvTypeFieldLength : tlvTypeFieldLength+tlvLengthFieldLength])
 
 	if valueLength != uint32(len(data[tlvTypeFieldLength+tlvLengthFieldLength:])) {
@@ -98,6 +102,21 @@ func UnmarshalFirstTLV(buf *bytes.Buffer) (tlv TLV, err error) {
 	return tlv, nil
 }
 
+// ParseContent parses the TLV into the Content matching its content type,
+// which is one of CosTlv, PCClientStd or IMATemplate.
+func (t TLV) ParseContent() (Content, error) {
+	switch t.Type {
+	case CosEventType:
+		return t.ParseToCosTlv()
+	case PCClientStdType:
+		return t.ParseToPCClientStd()
+	case IMATemplateType:
+		return t.ParseToIMATemplate()
+	default:
+		return nil, fmt.Errorf("unsupported CEL content type %d", t.Type)
+	}
+}
+
 // Record represents a Canonical Eventlog Record.
 type Record struct {
 	RecNum  uint64
@@ -197,9 +216,25 @@ func unmarshalPCR(tlv TLV) (pcrNum uint8, err error) {
 	return tlv.Value[0], nil
 }
 
+// sortedHashes returns the hash algorithms of the digests, ordered by their
+// TPM algorithm ID, so that encodings of a record are deterministic.
+func sortedHashes(digestMap map[crypto.Hash][]byte) []crypto.Hash {
+	hashes := make([]crypto.Hash, 0, len(digestMap))
+	for hashAlgo := range digestMap {
+		hashes = append(hashes, hashAlgo)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		algI, _ := tpm2.HashToAlgorithm(hashes[i])
+		algJ, _ := tpm2.HashToAlgorithm(hashes[j])
+		return algI < algJ
+	})
+	return hashes
+}
+
 func createDigestField(digestMap map[crypto.Hash][]byte) (TLV, error) {
 	var buf bytes.Buffer
-	for hashAlgo, hash := range digestMap {
+	for _, hashAlgo := range sortedHashes(digestMap) {
+		hash := digestMap[hashAlgo]
 		if len(hash) != hashAlgo.Size() {
 			return TLV{}, fmt.Errorf("digest length [%d] doesn't match the expected length [%d] for the hash algorithm",
 				len(hash), hashAlgo.Size())
@@ -320,7 +355,12 @@ func DecodeToCEL(buf *bytes.Buffer) (CEL, error) {
 // DecodeToCELR will read the buf for the next CELR, will return err if
 // failed to unmarshal a correct CELR TLV from the buffer.
 func DecodeToCELR(buf *bytes.Buffer) (r Record, err error) {
-	recnum, err := UnmarshalFirstTLV(buf)
+	return decodeRecord(func() (TLV, error) { return UnmarshalFirstTLV(buf) })
+}
+
+// decodeRecord decodes a CELR from the four TLVs returned by nextTLV.
+func decodeRecord(nextTLV func() (TLV, error)) (r Record, err error) {
+	recnum, err := nextTLV()
 	if err != nil {
 		return Record{}, err
 	}
@@ -329,7 +369,7 @@ func DecodeToCELR(buf *bytes.Buffer) (r Record, err error) {
 		return Record{}, err
 	}
 
-	pcr, err := UnmarshalFirstTLV(buf)
+	pcr, err := nextTLV()
 	if err != nil {
 		return Record{}, err
 	}
@@ -338,7 +378,7 @@ func DecodeToCELR(buf *bytes.Buffer) (r Record, err error) {
 		return Record{}, err
 	}
 
-	digests, err := UnmarshalFirstTLV(buf)
+	digests, err := nextTLV()
 	if err != nil {
 		return Record{}, err
 	}
@@ -347,7 +387,7 @@ func DecodeToCELR(buf *bytes.Buffer) (r Record, err error) {
 		return Record{}, err
 	}
 
-	r.Content, err = UnmarshalFirstTLV(buf)
+	r.Content, err = nextTLV()
 	if err != nil {
 		return Record{}, err
 	}
@@ -358,43 +398,16 @@ func DecodeToCELR(buf *bytes.Buffer) (r Record, err error) {
 // extend sequence for each PCR in the log. It then compares the final digests
 // against a bank of PCR values to see if they match.
 func (c *CEL) Replay(bank *pb.PCRs) error {
-	tpm2Alg := tpm2.Algorithm(bank.GetHash())
-	cryptoHash, err := tpm2Alg.Hash()
+	replayer, err := NewReplayer(bank)
 	if err != nil {
 		return err
 	}
-	replayed := make(map[uint8][]byte)
 	for _, record := range c.Records {
-		if _, ok := replayed[record.PCR]; !ok {
-			replayed[record.PCR] = make([]byte, cryptoHash.Size())
-		}
-		hasher := cryptoHash.New()
-		digestsMap := record.Digests
-		digest, ok := digestsMap[cryptoHash]
-		if !ok {
-			return fmt.Errorf("the CEL record did not contain a %v digest", cryptoHash)
-		}
-		hasher.Write(replayed[record.PCR])
-		hasher.Write(digest)
-		replayed[record.PCR] = hasher.Sum(nil)
-	}
-
-	var failedReplayPcrs []uint8
-	for replayPcr, replayDigest := range replayed {
-		bankDigest, ok := bank.Pcrs[uint32(replayPcr)]
-		if !ok {
-			return fmt.Errorf("the CEL contained record(s) for PCR%d without a matching PCR in the bank to verify", replayPcr)
-		}
-		if !bytes.Equal(bankDigest, replayDigest) {
-			failedReplayPcrs = append(failedReplayPcrs, replayPcr)
+		if err := replayer.Extend(record); err != nil {
+			return err
 		}
 	}
-
-	if len(failedReplayPcrs) == 0 {
-		return nil
-	}
-
-	return fmt.Errorf("CEL replay failed for these PCRs in bank %v: %v", cryptoHash, failedReplayPcrs)
+	return replayer.Verify()
 }
 
 // VerifyDigests checks the digest generated by the given record's content to make sure they are equal to
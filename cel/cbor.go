@@ -0,0 +1,371 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/google/go-tpm/tpm2"
+)
+
+// CBOR major types (RFC 8949 section 3.1) used by the CEL CBOR encoding.
+const (
+	cborUnsigned uint8 = 0
+	cborBytes    uint8 = 2
+	cborText     uint8 = 3
+	cborArray    uint8 = 4
+	cborMap      uint8 = 5
+)
+
+// Keys of the CBOR map representing a CEL record. The first three match the
+// field types of the TLV encoding.
+const (
+	cborRecnumKey      uint64 = uint64(recnumTypeValue)
+	cborPCRKey         uint64 = uint64(pcrTypeValue)
+	cborDigestsKey     uint64 = uint64(digestsTypeValue)
+	cborContentTypeKey uint64 = 4
+	cborContentKey     uint64 = 5
+)
+
+// EncodeCBOR encodes the CEL to a CBOR array of records and writes it to the
+// bytes buffer. Each record is a map from the keys recnum (0), pcr (1),
+// digests (3), content_type (4) and content (5) to their values. The digests
+// are a map from TPM algorithm IDs to digests. Content of the PCClient-STD,
+// IMA-TEMPLATE and COS types is a map from the nested field type to its value;
+// any other content is kept as the raw TLV value byte string.
+func (c *CEL) EncodeCBOR(buf *bytes.Buffer) error {
+	var out []byte
+	out = appendCBORHead(out, cborArray, uint64(len(c.Records)))
+	for _, record := range c.Records {
+		var err error
+		out, err = record.appendCBOR(out)
+		if err != nil {
+			return fmt.Errorf("failed to encode CEL record %d: %v", record.RecNum, err)
+		}
+	}
+	_, err := buf.Write(out)
+	return err
+}
+
+// DecodeCBOR reads a CEL in the CBOR encoding from the buffer. Only the
+// definite length, ascending key encoding produced by EncodeCBOR is accepted.
+func DecodeCBOR(buf *bytes.Buffer) (CEL, error) {
+	d := cborDecoder{buf}
+	numRecords, err := d.readHead(cborArray)
+	if err != nil {
+		return CEL{}, err
+	}
+	var cel CEL
+	for i := uint64(0); i < numRecords; i++ {
+		record, err := d.readRecord()
+		if err != nil {
+			return CEL{}, fmt.Errorf("failed to decode CBOR CEL record %d: %v", i, err)
+		}
+		cel.Records = append(cel.Records, record)
+	}
+	if buf.Len() != 0 {
+		return CEL{}, fmt.Errorf("found %d trailing bytes after CBOR CEL", buf.Len())
+	}
+	return cel, nil
+}
+
+func (r Record) appendCBOR(out []byte) ([]byte, error) {
+	out = appendCBORHead(out, cborMap, 5)
+	out = appendCBORHead(out, cborUnsigned, cborRecnumKey)
+	out = appendCBORHead(out, cborUnsigned, r.RecNum)
+	out = appendCBORHead(out, cborUnsigned, cborPCRKey)
+	out = appendCBORHead(out, cborUnsigned, uint64(r.PCR))
+
+	out = appendCBORHead(out, cborUnsigned, cborDigestsKey)
+	out = appendCBORHead(out, cborMap, uint64(len(r.Digests)))
+	for _, hashAlgo := range sortedHashes(r.Digests) {
+		tpmHashAlg, err := tpm2.HashToAlgorithm(hashAlgo)
+		if err != nil {
+			return nil, err
+		}
+		out = appendCBORHead(out, cborUnsigned, uint64(tpmHashAlg))
+		out = appendCBORBytes(out, r.Digests[hashAlgo])
+	}
+
+	out = appendCBORHead(out, cborUnsigned, cborContentTypeKey)
+	out = appendCBORHead(out, cborUnsigned, uint64(r.Content.Type))
+	out = appendCBORHead(out, cborUnsigned, cborContentKey)
+	// Content which fails to parse is encoded raw, to keep the encoding lossless.
+	switch r.Content.Type {
+	case PCClientStdType:
+		if p, err := r.Content.ParseToPCClientStd(); err == nil {
+			out = appendCBORHead(out, cborMap, 2)
+			out = appendCBORHead(out, cborUnsigned, uint64(pcClientEventTypeField))
+			out = appendCBORHead(out, cborUnsigned, uint64(p.EventType))
+			out = appendCBORHead(out, cborUnsigned, uint64(pcClientEventDataField))
+			return appendCBORBytes(out, p.EventData), nil
+		}
+	case IMATemplateType:
+		if i, err := r.Content.ParseToIMATemplate(); err == nil {
+			out = appendCBORHead(out, cborMap, 2)
+			out = appendCBORHead(out, cborUnsigned, uint64(imaTemplateNameField))
+			out = appendCBORHead(out, cborText, uint64(len(i.TemplateName)))
+			out = append(out, i.TemplateName...)
+			out = appendCBORHead(out, cborUnsigned, uint64(imaTemplateDataField))
+			return appendCBORBytes(out, i.TemplateData), nil
+		}
+	case CosEventType:
+		if c, err := r.Content.ParseToCosTlv(); err == nil {
+			out = appendCBORHead(out, cborMap, 1)
+			out = appendCBORHead(out, cborUnsigned, uint64(c.EventType))
+			return appendCBORBytes(out, c.EventContent), nil
+		}
+	}
+	return appendCBORBytes(out, r.Content.Value), nil
+}
+
+// appendCBORHead appends the initial byte and argument of a CBOR data item,
+// using the shortest encoding of the argument.
+func appendCBORHead(out []byte, major uint8, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return append(out, major|uint8(arg))
+	case arg <= 0xff:
+		return append(out, major|24, uint8(arg))
+	case arg <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(out, major|25), uint16(arg))
+	case arg <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(out, major|26), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(out, major|27), arg)
+	}
+}
+
+func appendCBORBytes(out []byte, data []byte) []byte {
+	return append(appendCBORHead(out, cborBytes, uint64(len(data))), data...)
+}
+
+type cborDecoder struct {
+	buf *bytes.Buffer
+}
+
+// readHead reads the head of the next data item, checks it has the expected
+// major type, and returns its argument.
+func (d cborDecoder) readHead(wantMajor uint8) (uint64, error) {
+	initial, err := d.buf.ReadByte()
+	if err != nil {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if major := initial >> 5; major != wantMajor {
+		return 0, fmt.Errorf("CBOR data item has major type %d, expected %d", major, wantMajor)
+	}
+	var size int
+	switch info := initial & 0x1f; {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, fmt.Errorf("unsupported CBOR additional information %d", info)
+	}
+	argBytes := d.buf.Next(size)
+	if len(argBytes) != size {
+		return 0, io.ErrUnexpectedEOF
+	}
+	var arg uint64
+	for _, b := range argBytes {
+		arg = arg<<8 | uint64(b)
+	}
+	return arg, nil
+}
+
+func (d cborDecoder) readUint(max uint64) (uint64, error) {
+	value, err := d.readHead(cborUnsigned)
+	if err != nil {
+		return 0, err
+	}
+	if value > max {
+		return 0, fmt.Errorf("CBOR unsigned integer %d exceeds maximum %d", value, max)
+	}
+	return value, nil
+}
+
+func (d cborDecoder) readKey(wantKey uint64) error {
+	key, err := d.readHead(cborUnsigned)
+	if err != nil {
+		return err
+	}
+	if key != wantKey {
+		return fmt.Errorf("CBOR map has key %d, expected %d", key, wantKey)
+	}
+	return nil
+}
+
+func (d cborDecoder) readString(major uint8) ([]byte, error) {
+	length, err := d.readHead(major)
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(d.buf.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, length)
+	copy(data, d.buf.Next(int(length)))
+	return data, nil
+}
+
+func (d cborDecoder) readRecord() (Record, error) {
+	if numFields, err := d.readHead(cborMap); err != nil {
+		return Record{}, err
+	} else if numFields != 5 {
+		return Record{}, fmt.Errorf("CBOR record has %d fields, expected 5", numFields)
+	}
+
+	var r Record
+	if err := d.readKey(cborRecnumKey); err != nil {
+		return Record{}, err
+	}
+	recnum, err := d.readUint(^uint64(0))
+	if err != nil {
+		return Record{}, err
+	}
+	r.RecNum = recnum
+
+	if err := d.readKey(cborPCRKey); err != nil {
+		return Record{}, err
+	}
+	pcr, err := d.readUint(0xff)
+	if err != nil {
+		return Record{}, err
+	}
+	r.PCR = uint8(pcr)
+
+	if err := d.readKey(cborDigestsKey); err != nil {
+		return Record{}, err
+	}
+	numDigests, err := d.readHead(cborMap)
+	if err != nil {
+		return Record{}, err
+	}
+	r.Digests = make(map[crypto.Hash][]byte)
+	for i := uint64(0); i < numDigests; i++ {
+		alg, err := d.readUint(0xffff)
+		if err != nil {
+			return Record{}, err
+		}
+		hashAlgo, err := tpm2.Algorithm(alg).Hash()
+		if err != nil {
+			return Record{}, err
+		}
+		digest, err := d.readString(cborBytes)
+		if err != nil {
+			return Record{}, err
+		}
+		if len(digest) != hashAlgo.Size() {
+			return Record{}, fmt.Errorf("%v digest has length %d, expected %d", hashAlgo, len(digest), hashAlgo.Size())
+		}
+		r.Digests[hashAlgo] = digest
+	}
+
+	if err := d.readKey(cborContentTypeKey); err != nil {
+		return Record{}, err
+	}
+	contentType, err := d.readUint(0xff)
+	if err != nil {
+		return Record{}, err
+	}
+	if err := d.readKey(cborContentKey); err != nil {
+		return Record{}, err
+	}
+	r.Content, err = d.readContent(uint8(contentType))
+	if err != nil {
+		return Record{}, err
+	}
+	return r, nil
+}
+
+func (d cborDecoder) readContent(contentType uint8) (TLV, error) {
+	// Raw content is a byte string, parsed content is a map.
+	initial, err := d.buf.ReadByte()
+	if err != nil {
+		return TLV{}, io.ErrUnexpectedEOF
+	}
+	d.buf.UnreadByte()
+	if initial>>5 == cborBytes {
+		value, err := d.readString(cborBytes)
+		if err != nil {
+			return TLV{}, err
+		}
+		return TLV{contentType, value}, nil
+	}
+
+	numFields, err := d.readHead(cborMap)
+	if err != nil {
+		return TLV{}, err
+	}
+	var content Content
+	switch contentType {
+	case PCClientStdType:
+		if numFields != 2 {
+			return TLV{}, fmt.Errorf("PCClient-STD content has %d fields, expected 2", numFields)
+		}
+		if err := d.readKey(uint64(pcClientEventTypeField)); err != nil {
+			return TLV{}, err
+		}
+		eventType, err := d.readUint(0xffffffff)
+		if err != nil {
+			return TLV{}, err
+		}
+		if err := d.readKey(uint64(pcClientEventDataField)); err != nil {
+			return TLV{}, err
+		}
+		eventData, err := d.readString(cborBytes)
+		if err != nil {
+			return TLV{}, err
+		}
+		content = PCClientStd{uint32(eventType), eventData}
+	case IMATemplateType:
+		if numFields != 2 {
+			return TLV{}, fmt.Errorf("IMA-TEMPLATE content has %d fields, expected 2", numFields)
+		}
+		if err := d.readKey(uint64(imaTemplateNameField)); err != nil {
+			return TLV{}, err
+		}
+		name, err := d.readString(cborText)
+		if err != nil {
+			return TLV{}, err
+		}
+		if !utf8.Valid(name) {
+			return TLV{}, fmt.Errorf("IMA template name contains non-utf8 characters: %q", name)
+		}
+		if err := d.readKey(uint64(imaTemplateDataField)); err != nil {
+			return TLV{}, err
+		}
+		data, err := d.readString(cborBytes)
+		if err != nil {
+			return TLV{}, err
+		}
+		content = IMATemplate{string(name), data}
+	case CosEventType:
+		if numFields != 1 {
+			return TLV{}, fmt.Errorf("COS content has %d fields, expected 1", numFields)
+		}
+		cosType, err := d.readUint(0xff)
+		if err != nil {
+			return TLV{}, err
+		}
+		eventContent, err := d.readString(cborBytes)
+		if err != nil {
+			return TLV{}, err
+		}
+		content = CosTlv{CosType(cosType), eventContent}
+	default:
+		return TLV{}, fmt.Errorf("content type %d must be encoded as a byte string", contentType)
+	}
+	return content.GetTLV()
+}
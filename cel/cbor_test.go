@@ -0,0 +1,92 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+)
+
+func TestCELCBOREncodingDecoding(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	cel := generateMixedCEL(t, tpm)
+
+	var buf bytes.Buffer
+	if err := cel.EncodeCBOR(&buf); err != nil {
+		t.Fatal(err)
+	}
+	encoded := append([]byte(nil), buf.Bytes()...)
+	decoded, err := DecodeCBOR(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLosslessDecoding(t, tpm, cel, &decoded)
+
+	// The encoding must not depend on the map iteration order.
+	buf.Reset()
+	if err := decoded.EncodeCBOR(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), encoded) {
+		t.Error("re-encoding the decoded CEL gave a different CBOR encoding")
+	}
+}
+
+func TestCBOREncodingFormat(t *testing.T) {
+	cel := CEL{Records: []Record{{
+		RecNum:  1,
+		PCR:     13,
+		Digests: map[crypto.Hash][]byte{crypto.SHA1: make([]byte, 20)},
+		Content: TLV{Type: 9, Value: []byte{0xaa}},
+	}}}
+	var buf bytes.Buffer
+	if err := cel.EncodeCBOR(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "81" + // array(1)
+		"a5" + // map(5)
+		"0001" + // recnum: 1
+		"010d" + // pcr: 13
+		"03a10454" + strings.Repeat("00", 20) + // digests: {sha1: bytes(20)}
+		"0409" + // content_type: 9
+		"0541aa" // content: bytes(1)
+	if got := hex.EncodeToString(buf.Bytes()); got != want {
+		t.Errorf("EncodeCBOR() = %s, want %s", got, want)
+	}
+}
+
+func TestDecodeCBORFail(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		cbor string
+	}{
+		{"Empty", ""},
+		{"NotAnArray", "a0"},
+		{"IndefiniteArray", "9f"},
+		{"TruncatedRecord", "81a5"},
+		{"WrongFieldCount", "81a400010100"},
+		{"OutOfOrderKeys", "81a5010d0001"},
+		{"PCROverflow", "81a500010119010003a00409" + "0540"},
+		{"UnknownHash", "81a50001010d03a1010004090540"},
+		{"BadDigestLength", "81a50001010d03a104410004090540"},
+		{"ParsedUnknownContent", "81a50001010d03a00409" + "05a0"},
+		{"BadPCClientStd", "81a50001010d03a00405" + "05a100" + "00"},
+		{"TrailingBytes", "80" + "00"},
+		{"ByteStringTooLong", "81a50001010d03a00409" + "0545aa"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := hex.DecodeString(c.cbor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := DecodeCBOR(bytes.NewBuffer(data)); err == nil {
+				t.Error("expected DecodeCBOR to fail")
+			}
+		})
+	}
+}
@@ -2,8 +2,14 @@ package cel
 
 import (
 	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 )
@@ -31,8 +37,69 @@ const (
 	OverrideEnvType
 	// EventContent is empty on success, or contains an error message on failure.
 	LaunchSeparatorType
+	// EventContent is a measurement requested by the workload after launch,
+	// see FormatWorkloadMeasurement.
+	WorkloadMeasurementType
+	// EventContent is the identity of the key that signed the launch spec,
+	// see FormatLaunchSpecSigner.
+	LaunchSpecSignerType
+	// EventContent is the identity of the key whose signature on the image
+	// digest was verified, see FormatImageSigner.
+	ImageSignerType
+	// EventContent is a mount of the container requested by the operator,
+	// see FormatMount.
+	MountType
+	// EventContent is the index of a sidecar container, see
+	// FormatContainerIndex. The container events following it are of that
+	// sidecar, while those before the first ContainerIndexType event are of
+	// the main container (index 0).
+	ContainerIndexType
+	// EventContent is the number of times the launcher restarted the main
+	// container after launch, see FormatRestartCount.
+	RestartCountType
+	// EventContent is the signal the launcher received to stop the workload,
+	// see FormatTerminationSignal.
+	TerminationType
+	// EventContent is a capability added to the container by the operator,
+	// see FormatCapability.
+	CapabilityType
+	// EventContent is whether the container uses the host network, see
+	// FormatHostNetwork.
+	HostNetworkType
+	// EventContent is the memory limit of the container set by the operator,
+	// see FormatMemoryLimit.
+	MemoryLimitType
 )
 
+// Types of the fields nested in a WorkloadMeasurementType event content.
+const (
+	workloadMeasurementTypeField uint8 = 0
+	workloadMeasurementDataField uint8 = 1
+)
+
+// Types of the fields nested in a MountType event content.
+const (
+	mountTypeField        uint8 = 0
+	mountSourceField      uint8 = 1
+	mountDestinationField uint8 = 2
+	mountReadOnlyField    uint8 = 3
+	mountSizeField        uint8 = 4
+)
+
+// Mount is the content of a MountType event.
+type Mount struct {
+	// Type is "bind", "tmpfs", or "luks" for an encrypted disk unlocked by
+	// the launcher.
+	Type string
+	// Source is the host path of a bind mount or the device of a luks mount,
+	// and empty for a tmpfs mount.
+	Source      string
+	Destination string
+	ReadOnly    bool
+	// SizeBytes is the size of a tmpfs mount, and 0 otherwise.
+	SizeBytes uint64
+}
+
 // CosTlv is a specific event type created for the COS (Google Container-Optimized OS),
 // used as a CEL content.
 type CosTlv struct {
@@ -109,6 +176,277 @@ func FormatEnvVar(name string, value string) (string, error) {
 	return name + "=" + value, nil
 }
 
+// FormatWorkloadMeasurement takes in a workload measurement type and its
+// content, checks the type is valid, and returns the event content for a
+// WorkloadMeasurementType event: the type and content as nested TLVs.
+func FormatWorkloadMeasurement(measurementType string, content []byte) ([]byte, error) {
+	var measurementTypeRegexp = regexp.MustCompile("^[a-zA-Z0-9_.-]{1,64}$")
+	if !measurementTypeRegexp.MatchString(measurementType) {
+		return nil, fmt.Errorf("malformed workload measurement type [%s], must be 1 to 64 alphanumeric characters, '_', '.' or '-' (%s)", measurementType, measurementTypeRegexp)
+	}
+	return marshalNestedTLVs(TLV{workloadMeasurementTypeField, []byte(measurementType)}, TLV{workloadMeasurementDataField, content})
+}
+
+// ParseWorkloadMeasurement takes in the event content of a
+// WorkloadMeasurementType event, and returns the measurement type and content,
+// or an error if it fails the validation check.
+func ParseWorkloadMeasurement(eventContent []byte) (string, []byte, error) {
+	fields, err := unmarshalNestedTLVs(eventContent, workloadMeasurementTypeField, workloadMeasurementDataField)
+	if err != nil {
+		return "", nil, fmt.Errorf("malformed workload measurement: %v", err)
+	}
+	measurementType := string(fields[0].Value)
+	if _, err := FormatWorkloadMeasurement(measurementType, fields[1].Value); err != nil {
+		return "", nil, err
+	}
+	return measurementType, fields[1].Value, nil
+}
+
+// FormatMount takes in a mount of the container, checks it is valid, and
+// returns the event content for a MountType event: its fields as nested TLVs.
+func FormatMount(m Mount) ([]byte, error) {
+	if err := checkMount(m); err != nil {
+		return nil, err
+	}
+	readOnly := []byte{0}
+	if m.ReadOnly {
+		readOnly = []byte{1}
+	}
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, m.SizeBytes)
+	return marshalNestedTLVs(
+		TLV{mountTypeField, []byte(m.Type)},
+		TLV{mountSourceField, []byte(m.Source)},
+		TLV{mountDestinationField, []byte(m.Destination)},
+		TLV{mountReadOnlyField, readOnly},
+		TLV{mountSizeField, size},
+	)
+}
+
+// ParseMount takes in the event content of a MountType event, and returns the
+// mount, or an error if it fails the validation check.
+func ParseMount(eventContent []byte) (Mount, error) {
+	fields, err := unmarshalNestedTLVs(eventContent, mountTypeField, mountSourceField, mountDestinationField, mountReadOnlyField, mountSizeField)
+	if err != nil {
+		return Mount{}, fmt.Errorf("malformed mount: %v", err)
+	}
+	if len(fields[3].Value) != 1 || fields[3].Value[0] > 1 {
+		return Mount{}, fmt.Errorf("malformed mount read-only field %v", fields[3].Value)
+	}
+	if len(fields[4].Value) != 8 {
+		return Mount{}, fmt.Errorf("malformed mount size field %v", fields[4].Value)
+	}
+	m := Mount{
+		Type:        string(fields[0].Value),
+		Source:      string(fields[1].Value),
+		Destination: string(fields[2].Value),
+		ReadOnly:    fields[3].Value[0] == 1,
+		SizeBytes:   binary.BigEndian.Uint64(fields[4].Value),
+	}
+	if err := checkMount(m); err != nil {
+		return Mount{}, err
+	}
+	return m, nil
+}
+
+func checkMount(m Mount) error {
+	if !path.IsAbs(m.Destination) || path.Clean(m.Destination) != m.Destination {
+		return fmt.Errorf("malformed mount destination [%s], must be an absolute clean path", m.Destination)
+	}
+	switch m.Type {
+	case "bind", "luks":
+		if !path.IsAbs(m.Source) || path.Clean(m.Source) != m.Source {
+			return fmt.Errorf("malformed %s mount source [%s], must be an absolute clean path", m.Type, m.Source)
+		}
+		if m.SizeBytes != 0 {
+			return fmt.Errorf("%s mount cannot have a size", m.Type)
+		}
+	case "tmpfs":
+		if m.Source != "" {
+			return fmt.Errorf("tmpfs mount cannot have a source")
+		}
+	default:
+		return fmt.Errorf("unknown mount type [%s]", m.Type)
+	}
+	return nil
+}
+
+// FormatContainerIndex takes in the index of a sidecar container, starting at
+// 1, and returns the event content for a ContainerIndexType event: the index
+// in decimal.
+func FormatContainerIndex(index int) ([]byte, error) {
+	return formatPositive(index, "container index")
+}
+
+// ParseContainerIndex takes in the event content of a ContainerIndexType
+// event, and returns the container index, or an error if it fails the
+// validation check.
+func ParseContainerIndex(eventContent []byte) (int, error) {
+	return parsePositive(eventContent, "container index")
+}
+
+// FormatRestartCount takes in the number of restarts of the main container,
+// starting at 1, and returns the event content for a RestartCountType event:
+// the count in decimal.
+func FormatRestartCount(count int) ([]byte, error) {
+	return formatPositive(count, "restart count")
+}
+
+// ParseRestartCount takes in the event content of a RestartCountType event,
+// and returns the restart count, or an error if it fails the validation check.
+func ParseRestartCount(eventContent []byte) (int, error) {
+	return parsePositive(eventContent, "restart count")
+}
+
+// FormatTerminationSignal takes in the name of the signal stopping the
+// workload (e.g. "SIGTERM"), checks it is valid, and returns the event
+// content for a TerminationType event.
+func FormatTerminationSignal(name string) ([]byte, error) {
+	var signalRegexp = regexp.MustCompile("^SIG[A-Z0-9]{1,16}$")
+	if !signalRegexp.MatchString(name) {
+		return nil, fmt.Errorf("malformed termination signal [%s], must match %s", name, signalRegexp)
+	}
+	return []byte(name), nil
+}
+
+// ParseTerminationSignal takes in the event content of a TerminationType
+// event, and returns the signal name, or an error if it fails the validation
+// check.
+func ParseTerminationSignal(eventContent []byte) (string, error) {
+	if _, err := FormatTerminationSignal(string(eventContent)); err != nil {
+		return "", err
+	}
+	return string(eventContent), nil
+}
+
+// FormatCapability takes in a capability added to the container in its
+// canonical form (e.g. "CAP_NET_ADMIN"), checks it is valid, and returns the
+// event content for a CapabilityType event.
+func FormatCapability(name string) ([]byte, error) {
+	var capabilityRegexp = regexp.MustCompile("^CAP_[A-Z_]{1,32}$")
+	if !capabilityRegexp.MatchString(name) {
+		return nil, fmt.Errorf("malformed capability [%s], must match %s", name, capabilityRegexp)
+	}
+	return []byte(name), nil
+}
+
+// ParseCapability takes in the event content of a CapabilityType event, and
+// returns the capability, or an error if it fails the validation check.
+func ParseCapability(eventContent []byte) (string, error) {
+	if _, err := FormatCapability(string(eventContent)); err != nil {
+		return "", err
+	}
+	return string(eventContent), nil
+}
+
+// FormatHostNetwork takes in whether the container uses the host network, and
+// returns the event content for a HostNetworkType event: "true" or "false".
+func FormatHostNetwork(hostNetwork bool) []byte {
+	return []byte(strconv.FormatBool(hostNetwork))
+}
+
+// ParseHostNetwork takes in the event content of a HostNetworkType event, and
+// returns whether the container uses the host network, or an error if it
+// fails the validation check.
+func ParseHostNetwork(eventContent []byte) (bool, error) {
+	switch string(eventContent) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("malformed host network [%s], must be true or false", eventContent)
+}
+
+// FormatMemoryLimit takes in the memory limit of the container in MiB, and
+// returns the event content for a MemoryLimitType event: the limit in
+// decimal. Containers without a memory limit have no MemoryLimitType event.
+func FormatMemoryLimit(limitMB uint64) ([]byte, error) {
+	if limitMB == 0 {
+		return nil, fmt.Errorf("malformed memory limit %d, must be positive", limitMB)
+	}
+	return []byte(strconv.FormatUint(limitMB, 10)), nil
+}
+
+// ParseMemoryLimit takes in the event content of a MemoryLimitType event, and
+// returns the memory limit in MiB, or an error if it fails the validation
+// check.
+func ParseMemoryLimit(eventContent []byte) (uint64, error) {
+	limitMB, err := strconv.ParseUint(string(eventContent), 10, 64)
+	if err != nil || strconv.FormatUint(limitMB, 10) != string(eventContent) {
+		return 0, fmt.Errorf("malformed memory limit [%s]", eventContent)
+	}
+	if _, err := FormatMemoryLimit(limitMB); err != nil {
+		return 0, err
+	}
+	return limitMB, nil
+}
+
+// formatPositive returns a positive number in decimal, without leading zeros.
+func formatPositive(n int, name string) ([]byte, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("malformed %s %d, must be positive", name, n)
+	}
+	return []byte(strconv.Itoa(n)), nil
+}
+
+func parsePositive(eventContent []byte, name string) (int, error) {
+	n, err := strconv.Atoi(string(eventContent))
+	if err != nil || strconv.Itoa(n) != string(eventContent) {
+		return 0, fmt.Errorf("malformed %s [%s]", name, eventContent)
+	}
+	if _, err := formatPositive(n, name); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// FormatLaunchSpecSigner takes in the public key that signed the launch spec,
+// and returns its identity as the event content of a LaunchSpecSignerType
+// event, see formatSigner.
+func FormatLaunchSpecSigner(pub crypto.PublicKey) (string, error) {
+	return formatSigner(pub, "launch spec signer")
+}
+
+// ParseLaunchSpecSigner takes in the event content of a LaunchSpecSignerType
+// event, and returns the signer identity, or an error if it fails the
+// validation check.
+func ParseLaunchSpecSigner(eventContent []byte) (string, error) {
+	return parseSigner(eventContent, "launch spec signer")
+}
+
+// FormatImageSigner takes in the public key that signed the image digest, and
+// returns its identity as the event content of an ImageSignerType event, see
+// formatSigner.
+func FormatImageSigner(pub crypto.PublicKey) (string, error) {
+	return formatSigner(pub, "image signer")
+}
+
+// ParseImageSigner takes in the event content of an ImageSignerType event, and
+// returns the signer identity, or an error if it fails the validation check.
+func ParseImageSigner(eventContent []byte) (string, error) {
+	return parseSigner(eventContent, "image signer")
+}
+
+// formatSigner returns the identity of a signing key: "sha256:" followed by
+// the hex SHA-256 digest of the PKIX DER encoding of the key.
+func formatSigner(pub crypto.PublicKey, name string) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s: %v", name, err)
+	}
+	digest := sha256.Sum256(der)
+	return "sha256:" + hex.EncodeToString(digest[:]), nil
+}
+
+func parseSigner(eventContent []byte, name string) (string, error) {
+	var signerRegexp = regexp.MustCompile("^sha256:[0-9a-f]{64}$")
+	if !signerRegexp.Match(eventContent) {
+		return "", fmt.Errorf("malformed %s [%s], must match %s", name, eventContent, signerRegexp)
+	}
+	return string(eventContent), nil
+}
+
 // ParseEnvVar takes in environment variable as a string (foo=bar), parses it and returns its name
 // and value, or an error if it fails the validation check.
 func ParseEnvVar(envvar string) (string, string, error) {
@@ -2,6 +2,10 @@ package cel
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"strings"
 	"testing"
 
@@ -126,3 +130,249 @@ func TestParseEnvVar(t *testing.T) {
 		})
 	}
 }
+
+func TestParseWorkloadMeasurement(t *testing.T) {
+	validContent, err := FormatWorkloadMeasurement("model_digest", []byte("sha256:abcd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	measurementType, content, err := ParseWorkloadMeasurement(validContent)
+	if err != nil {
+		t.Fatalf("expected no error, but got [%s]", err)
+	}
+	if measurementType != "model_digest" || string(content) != "sha256:abcd" {
+		t.Errorf("got measurement [%s]=[%s], want [model_digest]=[sha256:abcd]", measurementType, content)
+	}
+
+	badType, err := marshalNestedTLVs(TLV{workloadMeasurementTypeField, []byte("a b")}, TLV{workloadMeasurementDataField, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		testName             string
+		eventContent         []byte
+		expectedErrSubstring string
+	}{
+		{"empty", nil, "malformed workload measurement"},
+		{"trailing bytes", append(validContent, 0), "trailing bytes"},
+		{"bad type", badType, "malformed workload measurement type"},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			_, _, err := ParseWorkloadMeasurement(test.eventContent)
+			if err == nil {
+				t.Errorf("expected error substring [%s], but got no error", test.expectedErrSubstring)
+			} else if !strings.Contains(err.Error(), test.expectedErrSubstring) {
+				t.Errorf("expected error substring [%s], but got [%v]", test.expectedErrSubstring, err)
+			}
+		})
+	}
+
+	for _, measurementType := range []string{"", strings.Repeat("a", 65), "a/b", "é"} {
+		if _, err := FormatWorkloadMeasurement(measurementType, nil); err == nil {
+			t.Errorf("FormatWorkloadMeasurement(%q) should fail", measurementType)
+		}
+	}
+}
+
+func TestParseSigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers := []struct {
+		name   string
+		format func(crypto.PublicKey) (string, error)
+		parse  func([]byte) (string, error)
+	}{
+		{"LaunchSpecSigner", FormatLaunchSpecSigner, ParseLaunchSpecSigner},
+		{"ImageSigner", FormatImageSigner, ParseImageSigner},
+	}
+	for _, s := range signers {
+		t.Run(s.name, func(t *testing.T) {
+			signer, err := s.format(key.Public())
+			if err != nil {
+				t.Fatalf("Format%s() failed: %v", s.name, err)
+			}
+			got, err := s.parse([]byte(signer))
+			if err != nil {
+				t.Fatalf("expected no error, but got [%s]", err)
+			}
+			if got != signer {
+				t.Errorf("got signer [%s], want [%s]", got, signer)
+			}
+
+			if _, err := s.format(struct{}{}); err == nil {
+				t.Errorf("Format%s() with an invalid key should fail", s.name)
+			}
+			for _, content := range []string{"", "sha256:abcd", strings.ToUpper(signer), signer + "0", "sha384:" + signer[len("sha256:"):]} {
+				if _, err := s.parse([]byte(content)); err == nil {
+					t.Errorf("Parse%s(%q) should fail", s.name, content)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMount(t *testing.T) {
+	mounts := []Mount{
+		{Type: "bind", Source: "/mnt/disks/data", Destination: "/data", ReadOnly: true},
+		{Type: "tmpfs", Destination: "/scratch", SizeBytes: 1 << 30},
+		{Type: "luks", Source: "/dev/disk/by-id/google-data", Destination: "/secrets", ReadOnly: true},
+	}
+	for _, m := range mounts {
+		content, err := FormatMount(m)
+		if err != nil {
+			t.Fatalf("FormatMount(%+v) failed: %v", m, err)
+		}
+		got, err := ParseMount(content)
+		if err != nil {
+			t.Fatalf("expected no error, but got [%s]", err)
+		}
+		if got != m {
+			t.Errorf("got mount %+v, want %+v", got, m)
+		}
+	}
+
+	for _, m := range []Mount{
+		{Type: "volume", Source: "/a", Destination: "/b"},
+		{Type: "bind", Source: "a", Destination: "/b"},
+		{Type: "bind", Source: "/a", Destination: "/b/../c"},
+		{Type: "bind", Source: "/a", Destination: "/b", SizeBytes: 1},
+		{Type: "tmpfs", Source: "/a", Destination: "/b"},
+		{Type: "luks", Destination: "/b"},
+		{Type: "luks", Source: "/dev/sdb", Destination: "/b", SizeBytes: 1},
+		{Type: "tmpfs", Destination: "b"},
+	} {
+		if _, err := FormatMount(m); err == nil {
+			t.Errorf("FormatMount(%+v) should fail", m)
+		}
+	}
+
+	valid, err := FormatMount(mounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	badReadOnly, err := marshalNestedTLVs(
+		TLV{mountTypeField, []byte("bind")},
+		TLV{mountSourceField, []byte("/a")},
+		TLV{mountDestinationField, []byte("/b")},
+		TLV{mountReadOnlyField, []byte{2}},
+		TLV{mountSizeField, make([]byte, 8)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string][]byte{
+		"empty":          nil,
+		"trailing bytes": append(valid, 0),
+		"bad read-only":  badReadOnly,
+	} {
+		if _, err := ParseMount(content); err == nil {
+			t.Errorf("ParseMount() with %s content should fail", name)
+		}
+	}
+}
+
+func TestParsePositive(t *testing.T) {
+	numbers := []struct {
+		name   string
+		format func(int) ([]byte, error)
+		parse  func([]byte) (int, error)
+	}{
+		{"ContainerIndex", FormatContainerIndex, ParseContainerIndex},
+		{"RestartCount", FormatRestartCount, ParseRestartCount},
+	}
+	for _, num := range numbers {
+		t.Run(num.name, func(t *testing.T) {
+			for _, n := range []int{1, 2, 10, 255} {
+				content, err := num.format(n)
+				if err != nil {
+					t.Fatalf("Format%s(%d) failed: %v", num.name, n, err)
+				}
+				got, err := num.parse(content)
+				if err != nil {
+					t.Fatalf("expected no error, but got [%s]", err)
+				}
+				if got != n {
+					t.Errorf("got %d, want %d", got, n)
+				}
+			}
+
+			for _, n := range []int{0, -1} {
+				if _, err := num.format(n); err == nil {
+					t.Errorf("Format%s(%d) should fail", num.name, n)
+				}
+			}
+			for _, content := range []string{"", "0", "01", "+1", "-1", "1.0", " 1", "a"} {
+				if _, err := num.parse([]byte(content)); err == nil {
+					t.Errorf("Parse%s(%q) should fail", num.name, content)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTerminationSignal(t *testing.T) {
+	for _, name := range []string{"SIGTERM", "SIGINT", "SIGRTMIN1"} {
+		content, err := FormatTerminationSignal(name)
+		if err != nil {
+			t.Fatalf("FormatTerminationSignal(%q) failed: %v", name, err)
+		}
+		got, err := ParseTerminationSignal(content)
+		if err != nil {
+			t.Fatalf("expected no error, but got [%s]", err)
+		}
+		if got != name {
+			t.Errorf("got signal %q, want %q", got, name)
+		}
+	}
+
+	for _, content := range []string{"", "SIG", "TERM", "sigterm", "SIGTERM ", "15"} {
+		if _, err := ParseTerminationSignal([]byte(content)); err == nil {
+			t.Errorf("ParseTerminationSignal(%q) should fail", content)
+		}
+	}
+}
+
+func TestParseResources(t *testing.T) {
+	content, err := FormatCapability("CAP_NET_ADMIN")
+	if err != nil {
+		t.Fatalf("FormatCapability() failed: %v", err)
+	}
+	if got, err := ParseCapability(content); err != nil || got != "CAP_NET_ADMIN" {
+		t.Errorf("ParseCapability() = %q, %v, want CAP_NET_ADMIN", got, err)
+	}
+	for _, content := range []string{"", "NET_ADMIN", "CAP_", "cap_net_admin", "CAP_NET_ADMIN,CAP_SYS_ADMIN"} {
+		if _, err := ParseCapability([]byte(content)); err == nil {
+			t.Errorf("ParseCapability(%q) should fail", content)
+		}
+	}
+
+	for _, hostNetwork := range []bool{true, false} {
+		if got, err := ParseHostNetwork(FormatHostNetwork(hostNetwork)); err != nil || got != hostNetwork {
+			t.Errorf("ParseHostNetwork() = %v, %v, want %v", got, err, hostNetwork)
+		}
+	}
+	for _, content := range []string{"", "1", "TRUE", "yes"} {
+		if _, err := ParseHostNetwork([]byte(content)); err == nil {
+			t.Errorf("ParseHostNetwork(%q) should fail", content)
+		}
+	}
+
+	content, err = FormatMemoryLimit(512)
+	if err != nil {
+		t.Fatalf("FormatMemoryLimit() failed: %v", err)
+	}
+	if got, err := ParseMemoryLimit(content); err != nil || got != 512 {
+		t.Errorf("ParseMemoryLimit() = %d, %v, want 512", got, err)
+	}
+	if _, err := FormatMemoryLimit(0); err == nil {
+		t.Error("FormatMemoryLimit(0) should fail")
+	}
+	for _, content := range []string{"", "0", "-1", "0512", "512MB", " 512"} {
+		if _, err := ParseMemoryLimit([]byte(content)); err == nil {
+			t.Errorf("ParseMemoryLimit(%q) should fail", content)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package cel
+
+import (
+	"crypto"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	// IMATemplateType indicates the CELR content is a Linux IMA template
+	// entry, as found in the IMA runtime measurement log.
+	IMATemplateType uint8 = 7
+
+	// Types of the fields nested in an IMA-TEMPLATE content.
+	imaTemplateNameField uint8 = 0
+	imaTemplateDataField uint8 = 1
+)
+
+// IMATemplate is a CEL content containing a Linux IMA measurement, encoded as
+// a TLV with a nested template name and template data.
+type IMATemplate struct {
+	// The IMA template name (e.g. "ima-ng" or "ima-sig").
+	TemplateName string
+	// The data IMA hashes to compute the template hash. For all templates
+	// other than the legacy "ima" template, this is the template data as
+	// found in the binary runtime measurements.
+	TemplateData []byte
+}
+
+// GetTLV returns the TLV representation of the IMA-TEMPLATE content.
+func (i IMATemplate) GetTLV() (TLV, error) {
+	value, err := marshalNestedTLVs(TLV{imaTemplateNameField, []byte(i.TemplateName)}, TLV{imaTemplateDataField, i.TemplateData})
+	if err != nil {
+		return TLV{}, err
+	}
+	return TLV{Type: IMATemplateType, Value: value}, nil
+}
+
+// GenerateDigest generates the template hash of the IMA template data, which
+// is the digest IMA extends into the PCR.
+func (i IMATemplate) GenerateDigest(hashAlgo crypto.Hash) ([]byte, error) {
+	hash := hashAlgo.New()
+	if _, err := hash.Write(i.TemplateData); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// ParseToIMATemplate constructs an IMATemplate from a TLV. It will check for
+// the correct content type, and unmarshal the nested fields.
+func (t TLV) ParseToIMATemplate() (IMATemplate, error) {
+	if !t.IsIMATemplate() {
+		return IMATemplate{}, fmt.Errorf("TLV type %v is not an IMA-TEMPLATE event", t.Type)
+	}
+	fields, err := unmarshalNestedTLVs(t.Value, imaTemplateNameField, imaTemplateDataField)
+	if err != nil {
+		return IMATemplate{}, fmt.Errorf("failed to parse IMA-TEMPLATE content: %v", err)
+	}
+	if !utf8.Valid(fields[0].Value) {
+		return IMATemplate{}, fmt.Errorf("IMA template name contains non-utf8 characters: %q", fields[0].Value)
+	}
+	return IMATemplate{
+		TemplateName: string(fields[0].Value),
+		TemplateData: fields[1].Value,
+	}, nil
+}
+
+// IsIMATemplate check whether a TLV is an IMA-TEMPLATE TLV by its Type value.
+func (t TLV) IsIMATemplate() bool {
+	return t.Type == IMATemplateType
+}
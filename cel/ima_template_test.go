@@ -0,0 +1,66 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+// An ima-ng template data for "boot_aggregate" with an all-zero SHA-256 digest.
+var bootAggregateTemplateData = append(append(
+	[]byte("\x28\x00\x00\x00sha256:\x00"), make([]byte, 32)...),
+	[]byte("\x0f\x00\x00\x00boot_aggregate\x00")...)
+
+func TestIMATemplateEventlog(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	if err := tpm2.PCRReset(tpm, tpmutil.Handle(test.DebugPCR)); err != nil {
+		t.Fatal(err)
+	}
+
+	events := []IMATemplate{
+		{TemplateName: "ima-ng", TemplateData: bootAggregateTemplateData},
+		{TemplateName: "ima-sig", TemplateData: []byte("some template data")},
+	}
+	cel := &CEL{}
+	for _, event := range events {
+		appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, event)
+	}
+
+	var buf bytes.Buffer
+	if err := cel.EncodeCEL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decodedcel, err := DecodeToCEL(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay(t, &decodedcel, tpm, measuredHashes, []int{test.DebugPCR}, true /*shouldSucceed*/)
+
+	for i, record := range decodedcel.Records {
+		content, err := record.Content.ParseContent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(content, events[i]); diff != "" {
+			t.Errorf("unexpected IMA-TEMPLATE content:\n%v", diff)
+		}
+		if err := VerifyDigests(content, record.Digests); err != nil {
+			t.Errorf("failed to verify digests for record %d: %v", i, err)
+		}
+	}
+
+	// The SHA-1 digest must be the template hash found in the IMA log.
+	templateHash := sha1.Sum(bootAggregateTemplateData)
+	if got := decodedcel.Records[0].Digests[crypto.SHA1]; !bytes.Equal(got, templateHash[:]) {
+		t.Errorf("got SHA-1 digest %x, want template hash %x", got, templateHash)
+	}
+}
@@ -0,0 +1,189 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Content type names used in the JSON encoding of a CEL record.
+const (
+	pcClientStdJSONName = "pcclient_std"
+	imaTemplateJSONName = "ima_template"
+	cosJSONName         = "cos"
+	// rawJSONName is used for content this package cannot parse, which is
+	// kept as the TLV type and value so the encoding stays lossless.
+	rawJSONName = "raw"
+)
+
+var jsonHashNames = map[crypto.Hash]string{
+	crypto.SHA1:   "sha1",
+	crypto.SHA256: "sha256",
+	crypto.SHA384: "sha384",
+	crypto.SHA512: "sha512",
+}
+
+type jsonDigest struct {
+	HashAlg string `json:"hashAlg"`
+	Digest  string `json:"digest"`
+}
+
+type jsonRecord struct {
+	RecNum      uint64          `json:"recnum"`
+	PCR         uint8           `json:"pcr"`
+	Digests     []jsonDigest    `json:"digests"`
+	ContentType string          `json:"content_type"`
+	Content     json.RawMessage `json:"content"`
+}
+
+type jsonPCClientStd struct {
+	EventType uint32 `json:"event_type"`
+	EventData []byte `json:"event_data"`
+}
+
+type jsonIMATemplate struct {
+	TemplateName string `json:"template_name"`
+	TemplateData []byte `json:"template_data"`
+}
+
+// jsonTypedValue is used for both COS content (with the nested COS type) and
+// raw content (with the TLV type).
+type jsonTypedValue struct {
+	Type  uint8  `json:"type"`
+	Value []byte `json:"value"`
+}
+
+// EncodeJSON encodes the CEL to a JSON array of records, following the JSON
+// encoding of the CEL spec, and writes it to the bytes buffer. Digests are
+// hex encoded and binary content fields are base64 encoded.
+func (c *CEL) EncodeJSON(buf *bytes.Buffer) error {
+	records := make([]jsonRecord, 0, len(c.Records))
+	for _, record := range c.Records {
+		jsonRec, err := record.toJSON()
+		if err != nil {
+			return fmt.Errorf("failed to encode CEL record %d: %v", record.RecNum, err)
+		}
+		records = append(records, jsonRec)
+	}
+	return json.NewEncoder(buf).Encode(records)
+}
+
+// DecodeJSON reads a CEL in the JSON encoding from the buffer.
+func DecodeJSON(buf *bytes.Buffer) (CEL, error) {
+	var records []jsonRecord
+	if err := json.NewDecoder(buf).Decode(&records); err != nil {
+		return CEL{}, fmt.Errorf("failed to decode JSON CEL: %v", err)
+	}
+	var cel CEL
+	for i, jsonRec := range records {
+		record, err := jsonRec.toRecord()
+		if err != nil {
+			return CEL{}, fmt.Errorf("failed to decode JSON CEL record %d: %v", i, err)
+		}
+		cel.Records = append(cel.Records, record)
+	}
+	return cel, nil
+}
+
+func (r Record) toJSON() (jsonRecord, error) {
+	jsonRec := jsonRecord{RecNum: r.RecNum, PCR: r.PCR}
+	for _, hashAlgo := range sortedHashes(r.Digests) {
+		name, ok := jsonHashNames[hashAlgo]
+		if !ok {
+			return jsonRecord{}, fmt.Errorf("unsupported hash algorithm %v", hashAlgo)
+		}
+		jsonRec.Digests = append(jsonRec.Digests, jsonDigest{name, hex.EncodeToString(r.Digests[hashAlgo])})
+	}
+
+	jsonRec.ContentType = rawJSONName
+	var content interface{} = jsonTypedValue{r.Content.Type, r.Content.Value}
+	switch r.Content.Type {
+	case PCClientStdType:
+		if p, err := r.Content.ParseToPCClientStd(); err == nil {
+			jsonRec.ContentType = pcClientStdJSONName
+			content = jsonPCClientStd{p.EventType, p.EventData}
+		}
+	case IMATemplateType:
+		if i, err := r.Content.ParseToIMATemplate(); err == nil {
+			jsonRec.ContentType = imaTemplateJSONName
+			content = jsonIMATemplate{i.TemplateName, i.TemplateData}
+		}
+	case CosEventType:
+		if c, err := r.Content.ParseToCosTlv(); err == nil {
+			jsonRec.ContentType = cosJSONName
+			content = jsonTypedValue{uint8(c.EventType), c.EventContent}
+		}
+	}
+	var err error
+	jsonRec.Content, err = json.Marshal(content)
+	if err != nil {
+		return jsonRecord{}, err
+	}
+	return jsonRec, nil
+}
+
+func (j jsonRecord) toRecord() (Record, error) {
+	r := Record{RecNum: j.RecNum, PCR: j.PCR, Digests: make(map[crypto.Hash][]byte)}
+	for _, d := range j.Digests {
+		hashAlgo, err := jsonHashByName(d.HashAlg)
+		if err != nil {
+			return Record{}, err
+		}
+		digest, err := hex.DecodeString(d.Digest)
+		if err != nil {
+			return Record{}, fmt.Errorf("invalid %s digest: %v", d.HashAlg, err)
+		}
+		if len(digest) != hashAlgo.Size() {
+			return Record{}, fmt.Errorf("%s digest has length %d, expected %d", d.HashAlg, len(digest), hashAlgo.Size())
+		}
+		r.Digests[hashAlgo] = digest
+	}
+
+	var content Content
+	switch j.ContentType {
+	case pcClientStdJSONName:
+		var p jsonPCClientStd
+		if err := json.Unmarshal(j.Content, &p); err != nil {
+			return Record{}, err
+		}
+		content = PCClientStd{p.EventType, p.EventData}
+	case imaTemplateJSONName:
+		var i jsonIMATemplate
+		if err := json.Unmarshal(j.Content, &i); err != nil {
+			return Record{}, err
+		}
+		content = IMATemplate{i.TemplateName, i.TemplateData}
+	case cosJSONName:
+		var c jsonTypedValue
+		if err := json.Unmarshal(j.Content, &c); err != nil {
+			return Record{}, err
+		}
+		content = CosTlv{CosType(c.Type), c.Value}
+	case rawJSONName:
+		var raw jsonTypedValue
+		if err := json.Unmarshal(j.Content, &raw); err != nil {
+			return Record{}, err
+		}
+		r.Content = TLV{raw.Type, raw.Value}
+		return r, nil
+	default:
+		return Record{}, fmt.Errorf("unknown content type %q", j.ContentType)
+	}
+	var err error
+	r.Content, err = content.GetTLV()
+	if err != nil {
+		return Record{}, err
+	}
+	return r, nil
+}
+
+func jsonHashByName(name string) (crypto.Hash, error) {
+	for hashAlgo, hashName := range jsonHashNames {
+		if hashName == name {
+			return hashAlgo, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported hash algorithm %q", name)
+}
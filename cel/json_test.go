@@ -0,0 +1,105 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+// opaqueContent is a content with a type unknown to this package.
+type opaqueContent TLV
+
+func (o opaqueContent) GetTLV() (TLV, error) {
+	return TLV(o), nil
+}
+
+func (o opaqueContent) GenerateDigest(hashAlgo crypto.Hash) ([]byte, error) {
+	hash := hashAlgo.New()
+	hash.Write(o.Value)
+	return hash.Sum(nil), nil
+}
+
+// generateMixedCEL measures events of every content type, including a
+// malformed COS event and an unknown content type, to the TPM.
+func generateMixedCEL(t *testing.T, tpm io.ReadWriteCloser) *CEL {
+	t.Helper()
+	for _, pcr := range []int{test.DebugPCR, test.ApplicationPCR} {
+		if err := tpm2.PCRReset(tpm, tpmutil.Handle(pcr)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cel := &CEL{}
+	appendOrFatal(t, cel, tpm, test.ApplicationPCR, measuredHashes, CosTlv{ImageRefType, []byte("docker.io/bazel/experimental/test:latest")})
+	appendOrFatal(t, cel, tpm, test.ApplicationPCR, measuredHashes, CosTlv{LaunchSeparatorType, nil})
+	appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, PCClientStd{EventType: 0x80000001, EventData: []byte("SecureBoot\x01")})
+	appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, IMATemplate{TemplateName: "ima-ng", TemplateData: bootAggregateTemplateData})
+	appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, opaqueContent{Type: 9, Value: []byte{1, 2, 3}})
+	appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, opaqueContent{Type: CosEventType, Value: []byte{0}})
+	return cel
+}
+
+// checkLosslessDecoding checks the decoded CEL has the same TLV encoding as
+// the original one, and that it replays against the TPM.
+func checkLosslessDecoding(t *testing.T, tpm io.ReadWriteCloser, original *CEL, decoded *CEL) {
+	t.Helper()
+	var want, got bytes.Buffer
+	if err := original.EncodeCEL(&want); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.EncodeCEL(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Errorf("TLV encoding of the decoded CEL differs from the original:\ngot  %x\nwant %x", got.Bytes(), want.Bytes())
+	}
+	replay(t, decoded, tpm, measuredHashes, []int{test.DebugPCR, test.ApplicationPCR}, true /*shouldSucceed*/)
+}
+
+func TestCELJSONEncodingDecoding(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	cel := generateMixedCEL(t, tpm)
+
+	var buf bytes.Buffer
+	if err := cel.EncodeJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"content_type":"cos"`, `"content_type":"pcclient_std"`, `"content_type":"ima_template"`, `"content_type":"raw"`, `"hashAlg":"sha256"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("JSON CEL %s does not contain %s", buf.String(), want)
+		}
+	}
+	decoded, err := DecodeJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLosslessDecoding(t, tpm, cel, &decoded)
+}
+
+func TestDecodeJSONFail(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		json string
+	}{
+		{"NotAnArray", `{}`},
+		{"UnknownHash", `[{"recnum":0,"pcr":1,"digests":[{"hashAlg":"md5","digest":"00"}],"content_type":"raw","content":{"type":1,"value":""}}]`},
+		{"BadDigestLength", `[{"recnum":0,"pcr":1,"digests":[{"hashAlg":"sha1","digest":"00"}],"content_type":"raw","content":{"type":1,"value":""}}]`},
+		{"BadDigestHex", `[{"recnum":0,"pcr":1,"digests":[{"hashAlg":"sha1","digest":"zz"}],"content_type":"raw","content":{"type":1,"value":""}}]`},
+		{"UnknownContentType", `[{"recnum":0,"pcr":1,"digests":[],"content_type":"foo","content":{}}]`},
+		{"BadContent", `[{"recnum":0,"pcr":1,"digests":[],"content_type":"pcclient_std","content":{"event_type":"x"}}]`},
+		{"PCROverflow", `[{"recnum":0,"pcr":256,"digests":[],"content_type":"raw","content":{"type":1,"value":""}}]`},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := DecodeJSON(bytes.NewBufferString(c.json)); err == nil {
+				t.Error("expected DecodeJSON to fail")
+			}
+		})
+	}
+}
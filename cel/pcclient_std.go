@@ -0,0 +1,108 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// PCClientStdType indicates the CELR content is a TCG PC Client event, as
+	// found in the firmware event log.
+	PCClientStdType uint8 = 5
+
+	// Types of the fields nested in a PCClient-STD content.
+	pcClientEventTypeField uint8 = 0
+	pcClientEventDataField uint8 = 1
+)
+
+// PCClientStd is a CEL content containing an event from the TCG PC Client
+// Platform Firmware Profile, encoded as a TLV with a nested event type and
+// event data.
+type PCClientStd struct {
+	EventType uint32
+	EventData []byte
+}
+
+// GetTLV returns the TLV representation of the PCClient-STD content.
+func (p PCClientStd) GetTLV() (TLV, error) {
+	eventType := make([]byte, 4)
+	binary.BigEndian.PutUint32(eventType, p.EventType)
+	value, err := marshalNestedTLVs(TLV{pcClientEventTypeField, eventType}, TLV{pcClientEventDataField, p.EventData})
+	if err != nil {
+		return TLV{}, err
+	}
+	return TLV{Type: PCClientStdType, Value: value}, nil
+}
+
+// GenerateDigest generates the digest of the event data. Note that for some
+// PC Client event types (such as EV_EFI_BOOT_SERVICES_APPLICATION), the digest
+// extended into the PCR is not the digest of the event data, so it cannot be
+// verified from the content alone.
+func (p PCClientStd) GenerateDigest(hashAlgo crypto.Hash) ([]byte, error) {
+	hash := hashAlgo.New()
+	if _, err := hash.Write(p.EventData); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// ParseToPCClientStd constructs a PCClientStd from a TLV. It will check for
+// the correct content type, and unmarshal the nested fields.
+func (t TLV) ParseToPCClientStd() (PCClientStd, error) {
+	if !t.IsPCClientStd() {
+		return PCClientStd{}, fmt.Errorf("TLV type %v is not a PCClient-STD event", t.Type)
+	}
+	fields, err := unmarshalNestedTLVs(t.Value, pcClientEventTypeField, pcClientEventDataField)
+	if err != nil {
+		return PCClientStd{}, fmt.Errorf("failed to parse PCClient-STD content: %v", err)
+	}
+	if len(fields[0].Value) != 4 {
+		return PCClientStd{}, fmt.Errorf("PCClient-STD event type has length %d, expected 4", len(fields[0].Value))
+	}
+	return PCClientStd{
+		EventType: binary.BigEndian.Uint32(fields[0].Value),
+		EventData: fields[1].Value,
+	}, nil
+}
+
+// IsPCClientStd check whether a TLV is a PCClient-STD TLV by its Type value.
+func (t TLV) IsPCClientStd() bool {
+	return t.Type == PCClientStdType
+}
+
+// marshalNestedTLVs marshals the TLVs and concatenates them, to be used as
+// the value of a content TLV.
+func marshalNestedTLVs(tlvs ...TLV) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, tlv := range tlvs {
+		data, err := tlv.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalNestedTLVs parses the value of a content TLV, which must consist of
+// exactly one nested TLV for each of the given types, in order.
+func unmarshalNestedTLVs(value []byte, types ...uint8) ([]TLV, error) {
+	buf := bytes.NewBuffer(value)
+	tlvs := make([]TLV, 0, len(types))
+	for _, wantType := range types {
+		tlv, err := UnmarshalFirstTLV(buf)
+		if err != nil {
+			return nil, err
+		}
+		if tlv.Type != wantType {
+			return nil, fmt.Errorf("nested TLV has type %d, expected %d", tlv.Type, wantType)
+		}
+		tlvs = append(tlvs, tlv)
+	}
+	if buf.Len() != 0 {
+		return nil, fmt.Errorf("found %d trailing bytes after nested TLVs", buf.Len())
+	}
+	return tlvs, nil
+}
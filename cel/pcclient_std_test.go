@@ -0,0 +1,82 @@
+package cel
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+func TestPCClientStdEventlog(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	if err := tpm2.PCRReset(tpm, tpmutil.Handle(test.DebugPCR)); err != nil {
+		t.Fatal(err)
+	}
+
+	events := []PCClientStd{
+		// EV_SEPARATOR
+		{EventType: 0x4, EventData: []byte{0, 0, 0, 0}},
+		// EV_IPL
+		{EventType: 0xD, EventData: []byte("grub_cmd: linux /vmlinuz\x00")},
+		{EventType: 0xD, EventData: []byte{}},
+	}
+	cel := &CEL{}
+	for _, event := range events {
+		appendOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, event)
+	}
+
+	var buf bytes.Buffer
+	if err := cel.EncodeCEL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decodedcel, err := DecodeToCEL(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay(t, &decodedcel, tpm, measuredHashes, []int{test.DebugPCR}, true /*shouldSucceed*/)
+
+	for i, record := range decodedcel.Records {
+		content, err := record.Content.ParseContent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(content, events[i]); diff != "" {
+			t.Errorf("unexpected PCClient-STD content:\n%v", diff)
+		}
+		if err := VerifyDigests(content, record.Digests); err != nil {
+			t.Errorf("failed to verify digests for record %d: %v", i, err)
+		}
+	}
+}
+
+func TestParseToPCClientStdFail(t *testing.T) {
+	cosTLV, err := CosTlv{ImageRefType, []byte("image")}.GetTLV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	badEventType, err := marshalNestedTLVs(TLV{pcClientEventTypeField, []byte{1}}, TLV{pcClientEventDataField, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	missingData, err := marshalNestedTLVs(TLV{pcClientEventTypeField, []byte{0, 0, 0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tlv := range []TLV{
+		cosTLV,
+		{PCClientStdType, badEventType},
+		{PCClientStdType, missingData},
+		{PCClientStdType, append(missingData, 0xff)},
+	} {
+		if _, err := tlv.ParseToPCClientStd(); err == nil {
+			t.Errorf("ParseToPCClientStd(%v) should fail", tlv)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// Decoder reads CEL records in the TLV encoding one at a time from an
+// io.Reader, so a log can be processed without holding it all in memory.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder returns a Decoder reading records from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r}
+}
+
+// Next reads the next record. It returns io.EOF if the reader ends before the
+// record starts, and io.ErrUnexpectedEOF if it ends within the record.
+func (d *Decoder) Next() (Record, error) {
+	first := true
+	return decodeRecord(func() (TLV, error) {
+		tlv, err := readTLV(d.r)
+		if err == io.EOF && !first {
+			err = io.ErrUnexpectedEOF
+		}
+		first = false
+		return tlv, err
+	})
+}
+
+// readTLV reads a single TLV from the reader. It returns io.EOF if no bytes
+// were read. The value is read incrementally, so a corrupted length field
+// cannot cause a huge allocation.
+func readTLV(r io.Reader) (TLV, error) {
+	header := make([]byte, tlvTypeFieldLength+tlvLengthFieldLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return TLV{}, err
+	}
+	valueLength := int64(binary.BigEndian.Uint32(header[tlvTypeFieldLength:]))
+	var value bytes.Buffer
+	if _, err := io.CopyN(&value, r, valueLength); err == io.EOF {
+		return TLV{}, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return TLV{}, err
+	}
+	return TLV{Type: header[0], Value: value.Bytes()}, nil
+}
+
+// Encoder writes CEL records in the TLV encoding one at a time to an
+// io.Writer.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns an Encoder writing records to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w}
+}
+
+// Encode writes the record.
+func (e *Encoder) Encode(r Record) error {
+	var buf bytes.Buffer
+	if err := r.EncodeCELR(&buf); err != nil {
+		return err
+	}
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+// Replayer carries out the extend sequence of a Canonical Event Log one
+// record at a time, and compares the result against a bank of PCR values.
+// Nothing in the records extended should be trusted until Verify succeeds.
+type Replayer struct {
+	bank     *pb.PCRs
+	hash     crypto.Hash
+	replayed map[uint8][]byte
+}
+
+// NewReplayer returns a Replayer verifying against the given PCR bank.
+func NewReplayer(bank *pb.PCRs) (*Replayer, error) {
+	cryptoHash, err := tpm2.Algorithm(bank.GetHash()).Hash()
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{bank: bank, hash: cryptoHash, replayed: make(map[uint8][]byte)}, nil
+}
+
+// Extend extends the record's digest for the bank's hash algorithm into the
+// replayed value of the record's PCR.
+func (r *Replayer) Extend(record Record) error {
+	digest, ok := record.Digests[r.hash]
+	if !ok {
+		return fmt.Errorf("the CEL record did not contain a %v digest", r.hash)
+	}
+	replayed, ok := r.replayed[record.PCR]
+	if !ok {
+		replayed = make([]byte, r.hash.Size())
+	}
+	hasher := r.hash.New()
+	hasher.Write(replayed)
+	hasher.Write(digest)
+	r.replayed[record.PCR] = hasher.Sum(nil)
+	return nil
+}
+
+// Verify compares the replayed values of all PCRs with records against the
+// bank.
+func (r *Replayer) Verify() error {
+	var failedReplayPcrs []uint8
+	for replayPcr, replayDigest := range r.replayed {
+		bankDigest, ok := r.bank.Pcrs[uint32(replayPcr)]
+		if !ok {
+			return fmt.Errorf("the CEL contained record(s) for PCR%d without a matching PCR in the bank to verify", replayPcr)
+		}
+		if !bytes.Equal(bankDigest, replayDigest) {
+			failedReplayPcrs = append(failedReplayPcrs, replayPcr)
+		}
+	}
+
+	if len(failedReplayPcrs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("CEL replay failed for these PCRs in bank %v: %v", r.hash, failedReplayPcrs)
+}
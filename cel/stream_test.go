@@ -0,0 +1,130 @@
+package cel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+)
+
+func TestStreamingEncodingDecoding(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	cel := generateMixedCEL(t, tpm)
+
+	pr, pw := io.Pipe()
+	go func() {
+		encoder := NewEncoder(pw)
+		for _, record := range cel.Records {
+			if err := encoder.Encode(record); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.Close()
+	}()
+
+	pcrs, err := tpm2.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{test.DebugPCR, test.ApplicationPCR}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bank := &pb.PCRs{Hash: pb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{}}
+	for index, val := range pcrs {
+		bank.Pcrs[uint32(index)] = val
+	}
+	replayer, err := NewReplayer(bank)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := &CEL{}
+	decoder := NewDecoder(pr)
+	for {
+		record, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := replayer.Extend(record); err != nil {
+			t.Fatal(err)
+		}
+		decoded.Records = append(decoded.Records, record)
+	}
+	if err := replayer.Verify(); err != nil {
+		t.Errorf("incremental replay failed: %v", err)
+	}
+	checkLosslessDecoding(t, tpm, cel, decoded)
+}
+
+func TestReplayerFailsBeforeAllRecords(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	cel := generateMixedCEL(t, tpm)
+
+	pcrs, err := tpm2.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA1, PCRs: []int{test.DebugPCR, test.ApplicationPCR}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bank := &pb.PCRs{Hash: pb.HashAlgo_SHA1, Pcrs: map[uint32][]byte{}}
+	for index, val := range pcrs {
+		bank.Pcrs[uint32(index)] = val
+	}
+	replayer, err := NewReplayer(bank)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range cel.Records[:len(cel.Records)-1] {
+		if err := replayer.Extend(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := replayer.Verify(); err == nil {
+		t.Error("expected replay of a truncated CEL to fail")
+	}
+}
+
+func TestDecoderFail(t *testing.T) {
+	var buf bytes.Buffer
+	record := Record{RecNum: 0, PCR: 13, Digests: nil, Content: TLV{CosEventType, []byte{1, 2, 3}}}
+	if err := NewEncoder(&buf).Encode(record); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+
+	for _, c := range []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"Empty", nil, io.EOF},
+		{"TruncatedHeader", encoded[:3], io.ErrUnexpectedEOF},
+		{"TruncatedRecord", encoded[:len(encoded)-4], io.ErrUnexpectedEOF},
+		{"MissingContent", encoded[:len(encoded)-8], io.ErrUnexpectedEOF},
+		// A corrupted length must not cause the whole length to be allocated.
+		{"HugeLength", []byte{recnumTypeValue, 0xff, 0xff, 0xff, 0xff, 0}, io.ErrUnexpectedEOF},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewDecoder(bytes.NewReader(c.data)).Next()
+			if !errors.Is(err, c.wantErr) {
+				t.Errorf("Next() = %v, want %v", err, c.wantErr)
+			}
+		})
+	}
+
+	decoder := NewDecoder(bytes.NewReader(append(encoded, encoded...)))
+	for i := 0; i < 2; i++ {
+		if _, err := decoder.Next(); err != nil {
+			t.Fatalf("Next() failed for record %d: %v", i, err)
+		}
+	}
+	if _, err := decoder.Next(); err != io.EOF {
+		t.Errorf("Next() = %v after the last record, want io.EOF", err)
+	}
+}
@@ -44,6 +44,11 @@ type AttestOpts struct {
 	// firmware event log, where PCRs 0-9 and 14 are often measured. If the two
 	// logs overlap, server-side verification using this library may fail.
 	CanonicalEventLog []byte
+	// If true, Attest adds the Linux IMA runtime measurement log (see
+	// GetIMAEventLog) to the attestation. The log is read after quoting, so it
+	// may contain trailing entries not covered by the quotes. Server-side
+	// verification ignores such entries.
+	IncludeIMAEventLog bool
 	// If non-nil, will be used to fetch the AK certificate chain for validation.
 	// Key.Attest() will construct the certificate chain by making GET requests to
 	// the contents of Key.cert.IssuingCertificateURL using this client.
@@ -115,8 +120,15 @@ func fetchIssuingCertificate(client *http.Client, cert *x509.Certificate) (*x509
 // Constructs the certificate chain for the key's certificate.
 // If an error is encountered in the process, return what has been constructed so far.
 func (k *Key) getCertificateChain(client *http.Client) ([][]byte, error) {
+	return FetchCertificateChain(client, k.cert)
+}
+
+// FetchCertificateChain constructs the certificate chain of the certificate
+// by following its IssuingCertificateURLs, like Key.Attest does with the
+// CertChainFetcher. It lets callers fetch the chain without holding the TPM.
+func FetchCertificateChain(client *http.Client, cert *x509.Certificate) ([][]byte, error) {
 	var certs [][]byte
-	currentCert := k.cert
+	currentCert := cert
 	for len(certs) <= maxCertChainLength {
 		issuingCert, err := fetchIssuingCertificate(client, currentCert)
 		if err != nil {
@@ -242,6 +254,11 @@ func (k *Key) Attest(opts AttestOpts) (*pb.Attestation, error) {
 	if len(opts.CanonicalEventLog) != 0 {
 		attestation.CanonicalEventLog = opts.CanonicalEventLog
 	}
+	if opts.IncludeIMAEventLog {
+		if attestation.ImaEventLog, err = GetIMAEventLog(k.rw); err != nil {
+			return nil, fmt.Errorf("failed to retrieve IMA event log: %w", err)
+		}
+	}
 
 	// Attempt to construct certificate chain. fetchIssuingCertificate checks if
 	// AK cert is present and contains intermediate cert URLs.
@@ -17,3 +17,19 @@ func GetEventLog(rw io.ReadWriter) ([]byte, error) {
 type EventLogGetter interface {
 	EventLog() ([]byte, error)
 }
+
+// GetIMAEventLog grabs the Linux IMA runtime measurement log (in the binary
+// format) for the system. The TPM can override this implementation by
+// implementing IMAEventLogGetter.
+func GetIMAEventLog(rw io.ReadWriter) ([]byte, error) {
+	if elg, ok := rw.(IMAEventLogGetter); ok {
+		return elg.IMAEventLog()
+	}
+	return getRealIMAEventLog()
+}
+
+// IMAEventLogGetter allows a TPM (io.ReadWriter) to specify a particular
+// implementation for GetIMAEventLog(). This is useful for testing.
+type IMAEventLogGetter interface {
+	IMAEventLog() ([]byte, error)
+}
@@ -8,3 +8,7 @@ import "errors"
 func getRealEventLog() ([]byte, error) {
 	return nil, errors.New("failed to get event log: only Linux supported")
 }
+
+func getRealIMAEventLog() ([]byte, error) {
+	return nil, errors.New("failed to get IMA event log: only Linux supported")
+}
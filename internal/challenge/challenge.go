@@ -0,0 +1,107 @@
+// Package challenge keeps track of the single-use nonces handed out by
+// attestation verifiers, so an attestation cannot be replayed.
+package challenge
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NonceSize is the size of the challenge nonces.
+const NonceSize = 32
+
+// DefaultMaxChallenges is the default number of outstanding challenges of a
+// Store.
+const DefaultMaxChallenges = 10000
+
+// ErrTooManyChallenges is returned by Store.Create when the Store already
+// has its maximum number of outstanding challenges. Challenges are created
+// by unauthenticated requests, so they cannot be allowed to exhaust the
+// memory of the server.
+var ErrTooManyChallenges = errors.New("too many outstanding challenges, retry later")
+
+// Store creates challenges and checks their use. It is safe for concurrent
+// use.
+type Store struct {
+	prefix   string
+	lifetime time.Duration
+	max      int
+
+	mu sync.Mutex
+	// queue holds the outstanding challenges in order of creation, so also
+	// in order of expiry, and challenges indexes them by name.
+	queue      *list.List
+	challenges map[string]*list.Element
+}
+
+type challenge struct {
+	name    string
+	nonce   []byte
+	expires time.Time
+}
+
+// NewStore returns a Store whose challenge names start with the given prefix
+// (e.g. "challenges/"), which can be used for the given lifetime. At most max
+// challenges are outstanding (created, and neither used nor expired) at once.
+func NewStore(prefix string, lifetime time.Duration, max int) *Store {
+	return &Store{
+		prefix:     prefix,
+		lifetime:   lifetime,
+		max:        max,
+		queue:      list.New(),
+		challenges: make(map[string]*list.Element),
+	}
+}
+
+// Create creates a challenge, and returns its name and random nonce. It
+// returns ErrTooManyChallenges if max challenges are outstanding.
+func (s *Store) Create() (string, []byte, error) {
+	id := make([]byte, 16)
+	nonce := make([]byte, NonceSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	name := s.prefix + hex.EncodeToString(id)
+
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for e := s.queue.Front(); e != nil && now.After(e.Value.(*challenge).expires); e = s.queue.Front() {
+		s.remove(e)
+	}
+	if s.queue.Len() >= s.max {
+		return "", nil, ErrTooManyChallenges
+	}
+	s.challenges[name] = s.queue.PushBack(&challenge{name: name, nonce: nonce, expires: now.Add(s.lifetime)})
+	return name, nonce, nil
+}
+
+// Use returns the nonce of the named challenge. Each challenge can only be
+// used once.
+func (s *Store) Use(name string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.challenges[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown or already used challenge %q", name)
+	}
+	s.remove(e)
+	c := e.Value.(*challenge)
+	if time.Now().After(c.expires) {
+		return nil, fmt.Errorf("challenge %q expired", name)
+	}
+	return c.nonce, nil
+}
+
+func (s *Store) remove(e *list.Element) {
+	s.queue.Remove(e)
+	delete(s.challenges, e.Value.(*challenge).name)
+}
@@ -0,0 +1,75 @@
+package challenge
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	s := NewStore("challenges/", time.Minute, DefaultMaxChallenges)
+	name, nonce, err := s.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(name, "challenges/") || len(nonce) != NonceSize {
+		t.Errorf("Create() got name %q and a %d byte nonce", name, len(nonce))
+	}
+	got, err := s.Use(name)
+	if err != nil {
+		t.Fatalf("Use() failed: %v", err)
+	}
+	if !bytes.Equal(got, nonce) {
+		t.Errorf("Use() got nonce %x, want %x", got, nonce)
+	}
+	if _, err := s.Use(name); err == nil {
+		t.Error("Use() succeeded twice")
+	}
+	if _, err := s.Use("challenges/unknown"); err == nil {
+		t.Error("Use() of an unknown challenge succeeded")
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	s := NewStore("", -time.Second, DefaultMaxChallenges)
+	name, _, err := s.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Use(name); err == nil {
+		t.Error("Use() of an expired challenge succeeded")
+	}
+}
+
+func TestStoreMaxChallenges(t *testing.T) {
+	s := NewStore("", time.Minute, 2)
+	first, _, err := s.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Create(); !errors.Is(err, ErrTooManyChallenges) {
+		t.Fatalf("Create() over the maximum got error %v, want ErrTooManyChallenges", err)
+	}
+	// Using a challenge makes room for another one.
+	if _, err := s.Use(first); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Create(); err != nil {
+		t.Errorf("Create() after a challenge was used failed: %v", err)
+	}
+}
+
+func TestStoreMaxChallengesExpiry(t *testing.T) {
+	s := NewStore("", -time.Second, 1)
+	for i := 0; i < 3; i++ {
+		// The expired challenges do not count toward the maximum.
+		if _, _, err := s.Create(); err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+	}
+}
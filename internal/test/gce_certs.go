@@ -0,0 +1,108 @@
+package test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// gceInstanceInfoOID is the OID of the GCE instance info extension of GCE EK
+// and AK certificates.
+var gceInstanceInfoOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 1, 21}
+
+// tcgKpEKCertificateOID is the TCG EK certificate extended key usage, which
+// GCE sets on both its EK and AK certificates.
+var tcgKpEKCertificateOID = asn1.ObjectIdentifier{2, 23, 133, 8, 1}
+
+type gceSecurityProperties struct {
+	SecurityVersion int64 `asn1:"explicit,tag:0,optional"`
+	IsProduction    bool  `asn1:"explicit,tag:1,optional"`
+}
+
+type gceInstanceInfo struct {
+	Zone               string `asn1:"utf8"`
+	ProjectNumber      int64
+	ProjectID          string `asn1:"utf8"`
+	InstanceID         int64
+	InstanceName       string                `asn1:"utf8"`
+	SecurityProperties gceSecurityProperties `asn1:"explicit,optional"`
+}
+
+// CA is a test certificate authority issuing certificates like the GCE EK and
+// AK certificates.
+type CA struct {
+	Cert *x509.Certificate
+	Key  *ecdsa.PrivateKey
+}
+
+// NewCA returns a CA with a new self-signed root certificate.
+func NewCA(tb testing.TB) *CA {
+	tb.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test EK/AK Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return &CA{Cert: cert, Key: key}
+}
+
+// IssueGCECert returns a certificate for the public key with the GCE instance
+// info of the instance ID, the key usage and the TCG EK certificate extended
+// key usage.
+func (ca *CA) IssueGCECert(tb testing.TB, pub crypto.PublicKey, instanceID int64, usage x509.KeyUsage) *x509.Certificate {
+	tb.Helper()
+	info, err := asn1.Marshal(gceInstanceInfo{
+		Zone:               "us-central1-a",
+		ProjectID:          "test-project",
+		InstanceID:         instanceID,
+		InstanceName:       "test-instance",
+		SecurityProperties: gceSecurityProperties{IsProduction: true},
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:       serial,
+		NotBefore:          time.Now().Add(-time.Hour),
+		NotAfter:           time.Now().Add(time.Hour),
+		ExtraExtensions:    []pkix.Extension{{Id: gceInstanceInfoOID, Value: info}},
+		KeyUsage:           usage,
+		UnknownExtKeyUsage: []asn1.ObjectIdentifier{tcgKpEKCertificateOID},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, pub, ca.Key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return cert
+}
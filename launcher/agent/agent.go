@@ -9,14 +9,20 @@ import (
 	"bytes"
 	"context"
 	"crypto"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"sync"
 
 	"github.com/google/go-tpm-tools/cel"
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/launcher/verifier"
 	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
 )
 
 var defaultCELHashAlgo = []crypto.Hash{crypto.SHA256, crypto.SHA1}
@@ -26,18 +32,38 @@ type principalIDTokenFetcher func(audience string) ([][]byte, error)
 
 // AttestationAgent is an agent that interacts with GCE's Attestation Service
 // to Verify an attestation message. It is an interface instead of a concrete
-// struct to make testing easier.
+// struct to make testing easier. Its methods are safe for concurrent use.
 type AttestationAgent interface {
 	MeasureEvent(cel.Content) error
-	Attest(context.Context) ([]byte, error)
+	Attest(context.Context, AttestAgentOpts) ([]byte, error)
+	AttestationEvidence(nonce []byte) (*pb.Attestation, error)
+	MeasuredEvents() ([]cel.CosTlv, error)
+	Unseal(*tpmpb.SealedBytes) ([]byte, error)
+	EKCert() ([]byte, error)
+	ImportSecret(*tpmpb.ImportBlob) ([]byte, error)
+}
+
+// AttestAgentOpts contains user-specified options for Attest.
+type AttestAgentOpts struct {
+	// Aud is the audience of the returned token. If empty, the verifier's
+	// default audience is used.
+	Aud string
+	// Nonces are bound into the returned token, see verifier.TokenOptions.
+	Nonces []string
 }
 
 type agent struct {
+	// mu serializes TPM access and cosCel mutations, so an attestation always
+	// contains an eventlog matching the quoted PCRs.
+	mu               sync.Mutex
 	tpm              io.ReadWriteCloser
 	akFetcher        tpmKeyFetcher
 	client           verifier.Client
 	principalFetcher principalIDTokenFetcher
 	cosCel           cel.CEL
+	// celPath is the file the COS eventlog is persisted to, or empty if the
+	// eventlog is only kept in memory.
+	celPath string
 }
 
 // CreateAttestationAgent returns an agent capable of performing remote
@@ -54,16 +80,191 @@ func CreateAttestationAgent(tpm io.ReadWriteCloser, akFetcher tpmKeyFetcher, ver
 	}
 }
 
+// CreatePersistentAttestationAgent returns an agent like
+// CreateAttestationAgent, which also appends every measured record to the CEL
+// file at celPath. PCR extensions survive a launcher restart, so if the file
+// exists, the records of the previous launcher run are loaded from it and
+// replayed against the TPM. An error is returned if they diverge from the
+// CosEventPCR, as all later attestations would fail to verify.
+// celPath should be on a filesystem cleared on reboot (e.g. /run), as the PCRs
+// are.
+func CreatePersistentAttestationAgent(tpm io.ReadWriteCloser, akFetcher tpmKeyFetcher, verifierClient verifier.Client, principalFetcher principalIDTokenFetcher, celPath string) (AttestationAgent, error) {
+	cosCel, err := loadCEL(tpm, celPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load COS eventlog from %v: %v", celPath, err)
+	}
+	return &agent{
+		tpm:              tpm,
+		client:           verifierClient,
+		akFetcher:        akFetcher,
+		principalFetcher: principalFetcher,
+		cosCel:           cosCel,
+		celPath:          celPath,
+	}, nil
+}
+
 // MeasureEvent takes in a cel.Content and appends it to the CEL eventlog
 // under the attestation agent.
 func (a *agent) MeasureEvent(event cel.Content) error {
-	return a.cosCel.AppendEvent(a.tpm, cel.CosEventPCR, defaultCELHashAlgo, event)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.cosCel.AppendEvent(a.tpm, cel.CosEventPCR, defaultCELHashAlgo, event); err != nil {
+		return err
+	}
+	if a.celPath == "" {
+		return nil
+	}
+	// The PCR is already extended, so failing to persist the record leaves
+	// the file diverged from the TPM until the next reboot.
+	if err := appendRecord(a.celPath, a.cosCel.Records[len(a.cosCel.Records)-1]); err != nil {
+		return fmt.Errorf("failed to persist COS eventlog record: %v", err)
+	}
+	return nil
+}
+
+// MeasuredEvents returns the COS events of the eventlog, including the ones
+// loaded from the eventlog persisted by a previous launcher run.
+func (a *agent) MeasuredEvents() ([]cel.CosTlv, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var events []cel.CosTlv
+	for _, record := range a.cosCel.Records {
+		event, err := record.Content.ParseToCosTlv()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Unseal unseals data sealed to the TPM by client.Key.Seal, with the storage
+// root key of the type it was sealed with.
+func (a *agent) Unseal(sealed *tpmpb.SealedBytes) ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var srk *client.Key
+	var err error
+	switch sealed.GetSrk() {
+	case tpmpb.ObjectType_RSA:
+		srk, err = client.StorageRootKeyRSA(a.tpm)
+	case tpmpb.ObjectType_ECC:
+		srk, err = client.StorageRootKeyECC(a.tpm)
+	default:
+		return nil, fmt.Errorf("unsupported sealed SRK type %v", sealed.GetSrk())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the SRK: %v", err)
+	}
+	defer srk.Close()
+	return srk.Unseal(sealed, client.UnsealOpts{})
+}
+
+// EKCert returns the DER certificate of the ECC EK, which receives the
+// secrets of ImportSecret.
+func (a *agent) EKCert() ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ek, err := client.EndorsementKeyECC(a.tpm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the EK: %v", err)
+	}
+	defer ek.Close()
+	ekCert := ek.CertDERBytes()
+	if ekCert == nil {
+		return nil, errors.New("EK has no certificate")
+	}
+	return ekCert, nil
+}
+
+// ImportSecret imports a secret released to the ECC EK by server.CreateImportBlob.
+func (a *agent) ImportSecret(blob *tpmpb.ImportBlob) ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ek, err := client.EndorsementKeyECC(a.tpm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the EK: %v", err)
+	}
+	defer ek.Close()
+	return ek.Import(blob)
+}
+
+// loadCEL reads the persisted CEL, and checks it replays to the current
+// CosEventPCR values in all banks measured by the agent.
+func loadCEL(tpm io.ReadWriter, celPath string) (cel.CEL, error) {
+	var cosCel cel.CEL
+	f, err := os.Open(celPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return cel.CEL{}, err
+	}
+	if err == nil {
+		defer f.Close()
+		decoder := cel.NewDecoder(f)
+		for {
+			record, err := decoder.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return cel.CEL{}, err
+			}
+			if record.RecNum != uint64(len(cosCel.Records)) {
+				return cel.CEL{}, fmt.Errorf("found record number %d, expected %d", record.RecNum, len(cosCel.Records))
+			}
+			if record.PCR != cel.CosEventPCR {
+				return cel.CEL{}, fmt.Errorf("found unexpected PCR %d", record.PCR)
+			}
+			cosCel.Records = append(cosCel.Records, record)
+		}
+	}
+
+	for _, hash := range defaultCELHashAlgo {
+		tpm2Alg, err := tpm2.HashToAlgorithm(hash)
+		if err != nil {
+			return cel.CEL{}, err
+		}
+		pcrs, err := tpm2.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2Alg, PCRs: []int{cel.CosEventPCR}})
+		if err != nil {
+			return cel.CEL{}, err
+		}
+		pcrValue := pcrs[cel.CosEventPCR]
+		// Replay only checks PCRs with records, so an empty eventlog
+		// requires the PCR to still be in its reset state.
+		if len(cosCel.Records) == 0 {
+			if !bytes.Equal(pcrValue, make([]byte, hash.Size())) {
+				return cel.CEL{}, fmt.Errorf("PCR%d was extended, but the eventlog is empty", cel.CosEventPCR)
+			}
+			continue
+		}
+		bank := &tpmpb.PCRs{Hash: tpmpb.HashAlgo(tpm2Alg), Pcrs: map[uint32][]byte{cel.CosEventPCR: pcrValue}}
+		if err := cosCel.Replay(bank); err != nil {
+			return cel.CEL{}, err
+		}
+	}
+	return cosCel, nil
+}
+
+// appendRecord durably appends the record to the CEL file.
+func appendRecord(celPath string, record cel.Record) error {
+	f, err := os.OpenFile(celPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := cel.NewEncoder(f).Encode(record); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
 // Attest fetches the nonce and connection ID from the Attestation Service,
 // creates an attestation message, and returns the resultant
 // principalIDTokens and Metadata Server-generated ID tokens for the instance.
-func (a *agent) Attest(ctx context.Context) ([]byte, error) {
+func (a *agent) Attest(ctx context.Context, opts AttestAgentOpts) ([]byte, error) {
 	challenge, err := a.client.CreateChallenge(ctx)
 	if err != nil {
 		return nil, err
@@ -83,6 +284,10 @@ func (a *agent) Attest(ctx context.Context) ([]byte, error) {
 		Challenge:      challenge,
 		GcpCredentials: principalTokens,
 		Attestation:    attestation,
+		TokenOptions: verifier.TokenOptions{
+			Audience: opts.Aud,
+			Nonces:   opts.Nonces,
+		},
 	})
 	if err != nil {
 		return nil, err
@@ -90,21 +295,46 @@ func (a *agent) Attest(ctx context.Context) ([]byte, error) {
 	return resp.ClaimsToken, nil
 }
 
+// AttestationEvidence returns an attestation over the given nonce, including
+// the COS eventlog and the AK certificate chain, so it can be verified with
+// server.VerifyAttestation without an attestation service.
+func (a *agent) AttestationEvidence(nonce []byte) (*pb.Attestation, error) {
+	return a.getAttestation(nonce)
+}
+
 func (a *agent) getAttestation(nonce []byte) (*pb.Attestation, error) {
+	attestation, akCert, err := a.quote(nonce)
+	if err != nil {
+		return nil, err
+	}
+	// The certificate chain is fetched over the network, so it is done
+	// without holding the lock.
+	attestation.IntermediateCerts, err = client.FetchCertificateChain(http.DefaultClient, akCert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attest: fetching certificate chain: %v", err)
+	}
+	return attestation, nil
+}
+
+// quote returns an attestation over the nonce with a snapshot of the COS
+// eventlog, without the certificate chain, and the AK certificate.
+func (a *agent) quote(nonce []byte) (*pb.Attestation, *x509.Certificate, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	ak, err := a.akFetcher(a.tpm)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get AK: %v", err)
+		return nil, nil, fmt.Errorf("failed to get AK: %v", err)
 	}
 	defer ak.Close()
 
 	var buf bytes.Buffer
 	if err := a.cosCel.EncodeCEL(&buf); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce, CanonicalEventLog: buf.Bytes(), CertChainFetcher: http.DefaultClient})
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce, CanonicalEventLog: buf.Bytes()})
 	if err != nil {
-		return nil, fmt.Errorf("failed to attest: %v", err)
+		return nil, nil, fmt.Errorf("failed to attest: %v", err)
 	}
-	return attestation, nil
+	return attestation, ak.Cert(), nil
 }
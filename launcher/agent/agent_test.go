@@ -1,16 +1,25 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
 	"testing"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/cel"
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/internal/test"
 	"github.com/google/go-tpm-tools/launcher/verifier/fake"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server/token"
 )
 
 func TestAttest(t *testing.T) {
@@ -21,40 +30,194 @@ func TestAttest(t *testing.T) {
 	if err != nil {
 		t.Errorf("Failed to generate signing key %v", err)
 	}
-	verifierClient := fake.NewClient(fakeSigner)
+	verifierClient := fake.NewClient(fakeSigner, akPublicKey(t, tpm))
 	agent := CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, placeholderFetcher)
+	measureOrFatal(t, agent, cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")})
+	measureOrFatal(t, agent, cel.CosTlv{EventType: cel.ImageDigestType, EventContent: []byte("sha256:781d58c14b7d3ac1b27a4adbd3eed1d8a5a3b8a28cf8c3e27a2c3e5fd9d8e65e")})
+	measureOrFatal(t, agent, cel.CosTlv{EventType: cel.RestartPolicyType, EventContent: []byte(attestpb.RestartPolicy_Never.String())})
 
-	tokenBytes, err := agent.Attest(context.Background())
+	tokenBytes, err := agent.Attest(context.Background(), AttestAgentOpts{})
 	if err != nil {
 		t.Errorf("failed to attest to Attestation Service: %v", err)
 	}
 
-	registeredClaims := &jwt.RegisteredClaims{}
+	claims := &token.Claims{}
 	keyFunc := func(token *jwt.Token) (interface{}, error) { return fakeSigner.Public(), nil }
-	token, err := jwt.ParseWithClaims(string(tokenBytes), registeredClaims, keyFunc)
+	token, err := jwt.ParseWithClaims(string(tokenBytes), claims, keyFunc)
 	if err != nil {
 		t.Errorf("Failed to parse token %s", err)
 	}
 
-	if err = registeredClaims.Valid(); err != nil {
+	if err = claims.Valid(); err != nil {
 		t.Errorf("Invalid exp, iat, or nbf: %s", err)
 	}
 
-	if !registeredClaims.VerifyAudience("https://sts.googleapis.com/", true) {
+	if !claims.VerifyAudience("https://sts.googleapis.com/", true) {
 		t.Errorf("Invalid aud")
 	}
 
-	if !registeredClaims.VerifyIssuer("https://confidentialcomputing.googleapis.com/", true) {
+	if !claims.VerifyIssuer("https://confidentialcomputing.googleapis.com/", true) {
 		t.Errorf("Invalid iss")
 	}
 
-	if registeredClaims.Subject != "https://www.googleapis.com/compute/v1/projects/fakeProject/zones/fakeZone/instances/fakeInstance" {
+	if claims.Subject != "https://www.googleapis.com/compute/v1/projects/fakeProject/zones/fakeZone/instances/fakeInstance" {
 		t.Errorf("Invalid sub")
 	}
 
+	container := claims.Submods.Container
+	if container == nil {
+		t.Fatalf("Missing container claims")
+	}
+	if container.ImageReference != "docker.io/bazel/experimental/test:latest" {
+		t.Errorf("Invalid container image_reference")
+	}
+	if container.ImageDigest != "sha256:781d58c14b7d3ac1b27a4adbd3eed1d8a5a3b8a28cf8c3e27a2c3e5fd9d8e65e" {
+		t.Errorf("Invalid container image_digest")
+	}
+	if container.RestartPolicy != attestpb.RestartPolicy_Never.String() {
+		t.Errorf("Invalid container restart_policy")
+	}
+
 	fmt.Printf("token.Claims: %v\n", token.Claims)
 }
 
+func TestPersistentCEL(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	celPath := path.Join(t.TempDir(), "cel")
+
+	firstAgent, err := CreatePersistentAttestationAgent(tpm, client.AttestationKeyECC, nil, placeholderFetcher, celPath)
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	measureOrFatal(t, firstAgent, cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")})
+	measureOrFatal(t, firstAgent, cel.CosTlv{EventType: cel.ArgType, EventContent: []byte("arg")})
+
+	// Simulate a launcher restart: a new agent on the same TPM.
+	restartedAgent, err := CreatePersistentAttestationAgent(tpm, client.AttestationKeyECC, nil, placeholderFetcher, celPath)
+	if err != nil {
+		t.Fatalf("failed to reload agent: %v", err)
+	}
+	measureOrFatal(t, restartedAgent, cel.CosTlv{EventType: cel.LaunchSeparatorType})
+
+	cosCel := restartedAgent.(*agent).cosCel
+	if len(cosCel.Records) != 3 {
+		t.Fatalf("got %d records after restart, want 3", len(cosCel.Records))
+	}
+	// The reloaded and extended log must still match the TPM.
+	if _, err := loadCEL(tpm, celPath); err != nil {
+		t.Errorf("persisted CEL does not replay after restart: %v", err)
+	}
+}
+
+func TestPersistentCELDiverged(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	celPath := path.Join(t.TempDir(), "cel")
+
+	a, err := CreatePersistentAttestationAgent(tpm, client.AttestationKeyECC, nil, placeholderFetcher, celPath)
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	measureOrFatal(t, a, cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")})
+	measureOrFatal(t, a, cel.CosTlv{EventType: cel.ArgType, EventContent: []byte("arg")})
+	persisted, err := os.ReadFile(celPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	missingRecordPath := path.Join(t.TempDir(), "cel")
+	var firstRecord bytes.Buffer
+	if err := a.(*agent).cosCel.Records[0].EncodeCELR(&firstRecord); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name string
+		data []byte
+	}{
+		{"MissingFile", nil},
+		{"EmptyFile", []byte{}},
+		{"MissingRecord", firstRecord.Bytes()},
+		{"TruncatedRecord", persisted[:len(persisted)-1]},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			os.Remove(missingRecordPath)
+			if c.data != nil {
+				if err := os.WriteFile(missingRecordPath, c.data, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := CreatePersistentAttestationAgent(tpm, client.AttestationKeyECC, nil, placeholderFetcher, missingRecordPath); err == nil {
+				t.Error("expected creating the agent with a diverged CEL to fail")
+			}
+		})
+	}
+}
+
+func TestConcurrentMeasureAndAttest(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate signing key: %v", err)
+	}
+	// The fake verifier checks every attestation contains an eventlog
+	// matching its quotes.
+	verifierClient := fake.NewClient(fakeSigner, akPublicKey(t, tpm))
+	a := CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, placeholderFetcher)
+
+	const workers = 4
+	const iterations = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers*iterations)
+	for i := 0; i < workers; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				arg := fmt.Sprintf("arg-%d-%d", i, j)
+				errs <- a.MeasureEvent(cel.CosTlv{EventType: cel.ArgType, EventContent: []byte(arg)})
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				_, err := a.Attest(context.Background(), AttestAgentOpts{})
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	if got := len(a.(*agent).cosCel.Records); got != workers*iterations {
+		t.Errorf("got %d CEL records, want %d", got, workers*iterations)
+	}
+}
+
+func measureOrFatal(t *testing.T, a AttestationAgent, event cel.Content) {
+	t.Helper()
+	if err := a.MeasureEvent(event); err != nil {
+		t.Fatalf("failed to measure event: %v", err)
+	}
+}
+
+func akPublicKey(t *testing.T, tpm io.ReadWriter) crypto.PublicKey {
+	t.Helper()
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatalf("failed to get AK: %v", err)
+	}
+	defer ak.Close()
+	return ak.PublicKey()
+}
+
 func placeholderFetcher(audience string) ([][]byte, error) {
 	return [][]byte{}, nil
 }
@@ -2,6 +2,8 @@ package launcher
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/url"
 	"strings"
 
 	"cloud.google.com/go/compute/metadata"
@@ -10,6 +12,65 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// TokenProvider provides the credentials of the identity the launcher runs
+// as.
+type TokenProvider interface {
+	// AccessToken returns an OAuth2 access token, used to pull images from
+	// Artifact Registry and Container Registry.
+	AccessToken() (oauth2.Token, error)
+	// IDToken returns an OIDC ID token with the given audience, which is sent
+	// to the attestation verifier. It returns nil if there is no identity.
+	IDToken(audience string) ([]byte, error)
+}
+
+type mdsTokenProvider struct {
+	client *metadata.Client
+}
+
+// NewMDSTokenProvider returns a TokenProvider for the default service account
+// of a GCE VM, using the metadata server.
+func NewMDSTokenProvider(client *metadata.Client) TokenProvider {
+	return &mdsTokenProvider{client}
+}
+
+func (p *mdsTokenProvider) AccessToken() (oauth2.Token, error) {
+	return RetrieveAuthToken(p.client)
+}
+
+// IDToken fetches an ID token with a specific audience.
+// See https://cloud.google.com/functions/docs/securing/authenticating#functions-bearer-token-example-go.
+func (p *mdsTokenProvider) IDToken(audience string) ([]byte, error) {
+	u := url.URL{
+		Path: "instance/service-accounts/default/identity",
+		RawQuery: url.Values{
+			"audience": {audience},
+			"format":   {"full"},
+		}.Encode(),
+	}
+	idToken, err := p.client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	return []byte(idToken), nil
+}
+
+type noTokenProvider struct{}
+
+// NoTokenProvider returns a TokenProvider without an identity, for machines
+// without a metadata server. Images are pulled without authentication, and
+// attestations are verified without ID tokens.
+func NoTokenProvider() TokenProvider {
+	return noTokenProvider{}
+}
+
+func (noTokenProvider) AccessToken() (oauth2.Token, error) {
+	return oauth2.Token{}, fmt.Errorf("no identity to get an access token for")
+}
+
+func (noTokenProvider) IDToken(string) ([]byte, error) {
+	return nil, nil
+}
+
 // RetrieveAuthToken takes in a metadata server client, and uses it to read the
 // default service account token from a GCE VM and returns the token.
 func RetrieveAuthToken(client *metadata.Client) (oauth2.Token, error) {
@@ -0,0 +1,54 @@
+package launcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/compute/metadata"
+)
+
+func TestMDSTokenProvider(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/service-accounts/default/token":
+			w.Write([]byte(`{"access_token":"access","token_type":"Bearer","expires_in":3600}`))
+		case "/computeMetadata/v1/instance/service-accounts/default/identity":
+			q := r.URL.Query()
+			w.Write([]byte("id-" + q.Get("audience") + "-" + q.Get("format")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(ts.URL, "http://"))
+
+	provider := NewMDSTokenProvider(metadata.NewClient(nil))
+	token, err := provider.AccessToken()
+	if err != nil {
+		t.Fatalf("AccessToken() failed: %v", err)
+	}
+	if token.AccessToken != "access" {
+		t.Errorf("got access token %q, want %q", token.AccessToken, "access")
+	}
+
+	idToken, err := provider.IDToken("https://sts.googleapis.com")
+	if err != nil {
+		t.Fatalf("IDToken() failed: %v", err)
+	}
+	if want := "id-https://sts.googleapis.com-full"; string(idToken) != want {
+		t.Errorf("got ID token %q, want %q", idToken, want)
+	}
+}
+
+func TestNoTokenProvider(t *testing.T) {
+	provider := NoTokenProvider()
+	if _, err := provider.AccessToken(); err == nil {
+		t.Error("AccessToken() succeeded without an identity")
+	}
+	idToken, err := provider.IDToken("https://sts.googleapis.com")
+	if err != nil || idToken != nil {
+		t.Errorf("IDToken() got (%q, %v), want (nil, nil)", idToken, err)
+	}
+}
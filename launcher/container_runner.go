@@ -2,6 +2,7 @@
 package launcher
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -9,25 +10,37 @@ import (
 	"io"
 	"log"
 	"math/rand"
-	"net/url"
+	"net/http"
 	"os"
 	"path"
+	"path/filepath"
+	"syscall"
 	"time"
 
-	"cloud.google.com/go/compute/metadata"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/cio"
 	"github.com/containerd/containerd/content"
 	"github.com/containerd/containerd/images"
 	"github.com/containerd/containerd/oci"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/remotes/docker"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/go-tpm-tools/cel"
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/disk"
+	"github.com/google/go-tpm-tools/launcher/imagesig"
 	"github.com/google/go-tpm-tools/launcher/spec"
+	"github.com/google/go-tpm-tools/launcher/teeserver"
 	"github.com/google/go-tpm-tools/launcher/verifier"
+	"github.com/google/go-tpm-tools/launcher/verifier/grpcverifier"
 	"github.com/google/go-tpm-tools/launcher/verifier/rest"
+	"github.com/google/go-tpm-tools/launcher/verifier/selfhosted"
+	"github.com/google/go-tpm-tools/server/keyrelease"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/opencontainers/go-digest"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"golang.org/x/oauth2"
@@ -38,10 +51,50 @@ import (
 
 // ContainerRunner contains information about the container settings
 type ContainerRunner struct {
-	container   containerd.Container
-	launchSpec  spec.LaunchSpec
+	container  containerd.Container
+	launchSpec spec.LaunchSpec
+	// imageSigner is the identity of the key whose image signature was
+	// verified, or empty if it was not verified.
+	imageSigner string
 	attestAgent agent.AttestationAgent
-	logger      *log.Logger
+	// disks are the encrypted disks to unlock before starting the container.
+	disks []*encryptedDisk
+	// sidecars are the sidecars of the launch spec, in order.
+	sidecars []*sidecar
+	// stopC receives the signal to stop the workload with, see Stop.
+	stopC chan syscall.Signal
+	// stopSignal is the signal the workload was stopped with, so it is not
+	// restarted, or 0 if it was not stopped.
+	stopSignal syscall.Signal
+	// earlierRuns is the number of runs of the workload by the earlier
+	// launcher runs of this boot, found by measureContainerClaims in the
+	// persisted COS eventlog.
+	earlierRuns int
+	// refresherDone is closed once the token refresher stopped.
+	refresherDone chan struct{}
+	logger        *log.Logger
+}
+
+// sidecar is a Sidecar of the launch spec.
+type sidecar struct {
+	spec      spec.Sidecar
+	container containerd.Container
+	// imageSigner is the identity of the key whose image signature was
+	// verified, or empty if it was not verified.
+	imageSigner string
+	// task is the running task of the sidecar, if it was created.
+	task containerd.Task
+	// exited is closed once the task exited, if it was started.
+	exited chan struct{}
+}
+
+// encryptedDisk is a LuksMount of the launch spec.
+type encryptedDisk struct {
+	mount spec.Mount
+	// index is the index of the mount in the launch spec.
+	index  int
+	key    disk.KeySource
+	volume *disk.Volume
 }
 
 const (
@@ -50,9 +103,20 @@ const (
 	// containerTokenMountPath defined the directory in the container stores attestation tokens
 	containerTokenMountPath      = "/run/container_launcher/"
 	attestationVerifierTokenFile = "attestation_verifier_claims_token"
+	// teeServerSocket is the Unix domain socket of the workload API, in the
+	// token directory so the container can reach it through the token mount.
+	teeServerSocket = "teeserver.sock"
+	// hostCELPath is the file in the host persisting the COS eventlog across
+	// launcher restarts. It is under /run, so it is cleared on reboot along
+	// with the PCRs.
+	hostCELPath = "/run/container_launcher/cos_canonical_eventlog"
+	// hostDiskPath is the directory in the host where the unlocked encrypted
+	// disks are mounted, before being bind mounted into the container.
+	hostDiskPath = "/run/container_launcher/disks/"
 )
 
-// Since we only allow one container on a VM, using a deterministic id is probably fine
+// Since we only allow one workload on a VM, using a deterministic id is probably fine.
+// The IDs of the sidecars are suffixed with their names, see sidecarIDs.
 const (
 	containerID = "tee-container"
 	snapshotID  = "tee-snapshot"
@@ -67,6 +131,9 @@ const (
 	// refresh multiplier. The refresher will wait for some time in the range
 	// [defaultRefreshMultiplier-defaultRefreshJitter, defaultRefreshMultiplier+defaultRefreshJitter]
 	defaultRefreshJitter = 0.1
+	// restartBackoffReset is how long the workload task must run for its
+	// restart backoff to be reset.
+	restartBackoffReset = 10 * time.Minute
 )
 
 func fetchImpersonatedToken(ctx context.Context, serviceAccount string, audience string, opts ...option.ClientOption) ([]byte, error) {
@@ -90,14 +157,27 @@ func fetchImpersonatedToken(ctx context.Context, serviceAccount string, audience
 }
 
 // NewRunner returns a runner.
-func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.Token, launchSpec spec.LaunchSpec, mdsClient *metadata.Client, tpm io.ReadWriteCloser, logger *log.Logger) (*ContainerRunner, error) {
-	image, err := initImage(ctx, cdClient, launchSpec, token, logger)
+func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.Token, launchSpec spec.LaunchSpec, tokenProvider TokenProvider, tpm io.ReadWriteCloser, logger *log.Logger) (*ContainerRunner, error) {
+	// The launch policy allows host mounts by path, so check and measure the
+	// paths that are actually mounted.
+	mountSources, err := resolveMountSources(launchSpec.Mounts)
+	if err != nil {
+		return nil, err
+	}
+	launchSpec.Mounts = mountSources
+
+	image, err := initImage(ctx, cdClient, launchSpec.ImageRef, token, logger)
+	if err != nil {
+		return nil, err
+	}
+	imageSigner, err := verifyImageSignature(ctx, imagesig.NewRegistryFetcher(imageResolver(token)), image.Name(), image.Target().Digest, launchSpec, logger)
 	if err != nil {
 		return nil, err
 	}
 
 	mounts := make([]specs.Mount, 0)
 	mounts = appendTokenMounts(mounts)
+	mounts = appendOperatorMounts(mounts, launchSpec.Mounts)
 	envs, err := formatEnvVars(launchSpec.Envs)
 	if err != nil {
 		return nil, err
@@ -140,22 +220,20 @@ func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.To
 		return nil, &RetryableError{fmt.Errorf("cannot get hostname: [%w]", err)}
 	}
 
+	specOpts := []oci.SpecOpts{
+		oci.WithImageConfigArgs(image, launchSpec.Cmd),
+		oci.WithEnv(envs),
+		oci.WithMounts(mounts),
+		oci.WithEnv([]string{fmt.Sprintf("HOSTNAME=%s", hostname)}),
+	}
+	specOpts = append(specOpts, resourceSpecOpts(launchSpec)...)
+
 	container, err = cdClient.NewContainer(
 		ctx,
 		containerID,
 		containerd.WithImage(image),
 		containerd.WithNewSnapshot(snapshotID, image),
-		containerd.WithNewSpec(
-			oci.WithImageConfigArgs(image, launchSpec.Cmd),
-			oci.WithEnv(envs),
-			oci.WithMounts(mounts),
-			// following 4 options are here to allow the container to have
-			// the host network (same effect as --net-host in ctr command)
-			oci.WithHostHostsFile,
-			oci.WithHostResolvconf,
-			oci.WithHostNamespace(specs.NetworkNamespace),
-			oci.WithEnv([]string{fmt.Sprintf("HOSTNAME=%s", hostname)}),
-		),
+		containerd.WithNewSpec(specOpts...),
 	)
 	if err != nil {
 		if container != nil {
@@ -178,22 +256,21 @@ func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.To
 				len(containerSpec.Process.Args), len(launchSpec.Cmd))
 	}
 
-	// Fetch ID token with specific audience.
-	// See https://cloud.google.com/functions/docs/securing/authenticating#functions-bearer-token-example-go.
+	sidecars, err := newSidecars(ctx, cdClient, token, launchSpec, hostname, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	principalFetcher := func(audience string) ([][]byte, error) {
-		u := url.URL{
-			Path: "instance/service-accounts/default/identity",
-			RawQuery: url.Values{
-				"audience": {audience},
-				"format":   {"full"},
-			}.Encode(),
-		}
-		idToken, err := mdsClient.Get(u.String())
+		idToken, err := tokenProvider.IDToken(audience)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get principal tokens: %w", err)
 		}
 
-		tokens := [][]byte{[]byte(idToken)}
+		var tokens [][]byte
+		if idToken != nil {
+			tokens = append(tokens, idToken)
+		}
 
 		// Fetch impersonated ID tokens.
 		for _, sa := range launchSpec.ImpersonateServiceAccounts {
@@ -209,19 +286,133 @@ func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.To
 
 	asAddr := launchSpec.AttestationServiceAddr
 
-	verifierClient, err := getRESTClient(ctx, asAddr, launchSpec)
+	var verifierClient verifier.Client
+	switch launchSpec.AttestationServiceType {
+	case spec.SelfHostedVerifier:
+		verifierClient = selfhosted.NewClient(asAddr, nil)
+	case spec.GRPCVerifier:
+		conn, err := grpcverifier.Dial(asAddr, nil)
+		if err != nil {
+			return nil, err
+		}
+		verifierClient = grpcverifier.NewClient(conn)
+	default:
+		verifierClient, err = getRESTClient(ctx, asAddr, launchSpec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create REST verifier client: %v", err)
+		}
+	}
+
+	if err := os.MkdirAll(path.Dir(hostCELPath), 0700); err != nil {
+		return nil, err
+	}
+	// Machines outside of GCE do not have the GCE AK template, so use the
+	// default AK there.
+	akFetcher := client.GceAttestationKeyECC
+	hasTemplate, err := hasGceAKTemplate(tpm)
+	if err != nil {
+		return nil, err
+	}
+	if !hasTemplate {
+		logger.Println("GCE attestation key template not found, using the default ECC attestation key")
+		akFetcher = client.AttestationKeyECC
+	}
+	attestAgent, err := agent.CreatePersistentAttestationAgent(tpm, akFetcher, verifierClient, principalFetcher, hostCELPath)
+	if err != nil {
+		return nil, err
+	}
+	disks, err := newEncryptedDisks(launchSpec.Mounts, attestAgent)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create REST verifier client: %v", err)
+		return nil, err
 	}
 
 	return &ContainerRunner{
-		container,
-		launchSpec,
-		agent.CreateAttestationAgent(tpm, client.GceAttestationKeyECC, verifierClient, principalFetcher),
-		logger,
+		container:   container,
+		launchSpec:  launchSpec,
+		imageSigner: imageSigner,
+		attestAgent: attestAgent,
+		disks:       disks,
+		sidecars:    sidecars,
+		stopC:       make(chan syscall.Signal, 1),
+		logger:      logger,
 	}, nil
 }
 
+// hasGceAKTemplate reports whether the TPM has the NV index with the GCE ECC
+// AK template. Errors other than the index being absent are returned, so the
+// launcher does not silently attest with another key on GCE.
+func hasGceAKTemplate(tpm io.ReadWriter) (bool, error) {
+	_, err := tpm2.NVReadPublic(tpm, tpmutil.Handle(client.GceAKTemplateNVIndexECC))
+	if err == nil {
+		return true, nil
+	}
+	var handleErr tpm2.HandleError
+	if errors.As(err, &handleErr) && handleErr.Code == tpm2.RCHandle {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to read the GCE AK template NV index: %v", err)
+}
+
+// sidecarIDs returns the container and snapshot IDs of the named sidecar.
+func sidecarIDs(name string) (string, string) {
+	return containerID + "-" + name, snapshotID + "-" + name
+}
+
+// newSidecars pulls the images of the sidecars of the launch spec, verifies
+// them like the workload image, and creates their containers.
+func newSidecars(ctx context.Context, cdClient *containerd.Client, token oauth2.Token, launchSpec spec.LaunchSpec, hostname string, logger *log.Logger) ([]*sidecar, error) {
+	var sidecars []*sidecar
+	for _, s := range launchSpec.Sidecars {
+		image, err := initImage(ctx, cdClient, s.ImageRef, token, logger)
+		if err != nil {
+			return nil, fmt.Errorf("sidecar %s: %w", s.Name, err)
+		}
+		imageSigner, err := verifyImageSignature(ctx, imagesig.NewRegistryFetcher(imageResolver(token)), image.Name(), image.Target().Digest, launchSpec, logger)
+		if err != nil {
+			return nil, fmt.Errorf("sidecar %s: %w", s.Name, err)
+		}
+		envs, err := formatEnvVars(s.Envs)
+		if err != nil {
+			return nil, fmt.Errorf("sidecar %s: %w", s.Name, err)
+		}
+		logger.Printf("Sidecar %s Image Ref  : %v\n", s.Name, image.Name())
+		logger.Printf("Sidecar %s Digest     : %v\n", s.Name, image.Target().Digest)
+
+		imageLabels, err := getImageLabels(ctx, image)
+		if err != nil {
+			logger.Printf("Failed to get sidecar %s image OCI labels %v\n", s.Name, err)
+		}
+		launchPolicy, err := spec.GetLaunchPolicy(imageLabels)
+		if err != nil {
+			return nil, fmt.Errorf("sidecar %s: %w", s.Name, err)
+		}
+		if err := launchPolicy.VerifySidecar(s, launchSpec); err != nil {
+			return nil, err
+		}
+
+		id, snapshot := sidecarIDs(s.Name)
+		if container, err := cdClient.LoadContainer(ctx, id); err == nil {
+			container.Delete(ctx, containerd.WithSnapshotCleanup)
+		}
+		specOpts := append([]oci.SpecOpts{oci.WithImageConfigArgs(image, s.Cmd)}, sidecarSpecOpts(launchSpec, envs, hostname)...)
+		container, err := cdClient.NewContainer(
+			ctx,
+			id,
+			containerd.WithImage(image),
+			containerd.WithNewSnapshot(snapshot, image),
+			containerd.WithNewSpec(specOpts...),
+		)
+		if err != nil {
+			if container != nil {
+				container.Delete(ctx, containerd.WithSnapshotCleanup)
+			}
+			return nil, &RetryableError{fmt.Errorf("failed to create sidecar %s container: [%w]", s.Name, err)}
+		}
+		sidecars = append(sidecars, &sidecar{spec: s, container: container, imageSigner: imageSigner})
+	}
+	return sidecars, nil
+}
+
 // getRESTClient returns a REST verifier.Client that points to the given address.
 // It defaults to the Attestation Verifier instance at
 // https://confidentialcomputing.googleapis.com.
@@ -256,6 +447,20 @@ func formatEnvVars(envVars []spec.EnvVar) ([]string, error) {
 	return result, nil
 }
 
+// sidecarSpecOpts returns the spec options of a sidecar, other than its
+// image. Sidecars have the network, memory limit and capabilities of the
+// workload, but none of its mounts: in particular, they cannot get
+// attestation tokens from the token directory or the teeserver socket.
+// Without the host network, sidecars join the network namespace of the
+// workload task when they are started, see startSidecars.
+func sidecarSpecOpts(launchSpec spec.LaunchSpec, envs []string, hostname string) []oci.SpecOpts {
+	specOpts := []oci.SpecOpts{
+		oci.WithEnv(envs),
+		oci.WithEnv([]string{fmt.Sprintf("HOSTNAME=%s", hostname)}),
+	}
+	return append(specOpts, resourceSpecOpts(launchSpec)...)
+}
+
 // appendTokenMounts appends the default mount specs for the OIDC token
 func appendTokenMounts(mounts []specs.Mount) []specs.Mount {
 	m := specs.Mount{}
@@ -267,71 +472,321 @@ func appendTokenMounts(mounts []specs.Mount) []specs.Mount {
 	return append(mounts, m)
 }
 
+// newEncryptedDisks returns the encrypted disks of the LuksMounts, with the key
+// sources of their keys.
+func newEncryptedDisks(mounts []spec.Mount, attestAgent agent.AttestationAgent) ([]*encryptedDisk, error) {
+	fetchToken := func(ctx context.Context, audience string) ([]byte, error) {
+		return attestAgent.Attest(ctx, agent.AttestAgentOpts{Aud: audience})
+	}
+	var disks []*encryptedDisk
+	for i, m := range mounts {
+		if m.Type != spec.LuksMount {
+			continue
+		}
+		var key disk.KeySource
+		if len(m.SealedKey) > 0 {
+			var err error
+			key, err = disk.NewSealedSource(attestAgent, m.SealedKey)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sealed key for %s: %v", m.Source, err)
+			}
+		} else if m.KeyReleaseSecret != "" {
+			key = disk.NewAttestedReleaseSource(keyrelease.NewClient(m.KeyReleaseEndpoint, nil), m.KeyReleaseSecret, attestAgent)
+		} else {
+			key = disk.NewKeyReleaseSource(m.KeyReleaseEndpoint, m.WrappedKey, fetchToken, nil)
+		}
+		disks = append(disks, &encryptedDisk{mount: m, index: i, key: key})
+	}
+	return disks, nil
+}
+
+// diskName returns the device mapper name of the encrypted disk of the mount
+// at the index of the launch spec.
+func diskName(index int) string {
+	return fmt.Sprintf("tee-disk-%d", index)
+}
+
+// diskMountPoint returns the host directory where the encrypted disk of the
+// mount at the index of the launch spec is mounted.
+func diskMountPoint(index int) string {
+	return path.Join(hostDiskPath, diskName(index))
+}
+
+// resolveMountSources returns the mounts with the symlinks in the host paths
+// of bind mounts resolved, as the mounts follow them.
+func resolveMountSources(mounts []spec.Mount) ([]spec.Mount, error) {
+	resolved := make([]spec.Mount, len(mounts))
+	for i, m := range mounts {
+		if m.Type == spec.BindMount {
+			source, err := filepath.EvalSymlinks(m.Source)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve the source of the mount at %s: %v", m.Destination, err)
+			}
+			m.Source = source
+		}
+		resolved[i] = m
+	}
+	return resolved, nil
+}
+
+// appendOperatorMounts appends the mount specs for the mounts requested by the
+// operator.
+func appendOperatorMounts(mounts []specs.Mount, operatorMounts []spec.Mount) []specs.Mount {
+	for i, om := range operatorMounts {
+		m := specs.Mount{}
+		m.Destination = om.Destination
+		switch om.Type {
+		case spec.TmpfsMount:
+			m.Type = "tmpfs"
+			m.Source = "tmpfs"
+			m.Options = []string{"nosuid", "nodev", fmt.Sprintf("size=%d", om.SizeBytes)}
+		default:
+			m.Type = "bind"
+			m.Source = om.Source
+			if om.Type == spec.LuksMount {
+				// The disk is unlocked and mounted there by unlockDisks.
+				m.Source = diskMountPoint(i)
+			}
+			m.Options = []string{"rbind", "rw"}
+			if om.ReadOnly {
+				m.Options = []string{"rbind", "ro"}
+			}
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts
+}
+
+// resourceSpecOpts returns the spec options for the network, memory limit
+// and capabilities requested by the operator.
+func resourceSpecOpts(launchSpec spec.LaunchSpec) []oci.SpecOpts {
+	var opts []oci.SpecOpts
+	if launchSpec.HostNetwork {
+		opts = append(opts, hostNetworkSpecOpts()...)
+	}
+	if launchSpec.MemoryLimitMB != 0 {
+		opts = append(opts, oci.WithMemoryLimit(launchSpec.MemoryLimitMB*1024*1024))
+	}
+	if len(launchSpec.AddedCapabilities) != 0 {
+		opts = append(opts, oci.WithAddedCapabilities(launchSpec.AddedCapabilities))
+	}
+	return opts
+}
+
+// hostNetworkSpecOpts returns the spec options giving a container the host
+// network.
+func hostNetworkSpecOpts() []oci.SpecOpts {
+	// following 3 options are here to allow the container to have
+	// the host network (same effect as --net-host in ctr command)
+	return []oci.SpecOpts{
+		oci.WithHostHostsFile,
+		oci.WithHostResolvconf,
+		oci.WithHostNamespace(specs.NetworkNamespace),
+	}
+}
+
 // measureContainerClaims will measure various container claims into the COS
 // eventlog in the AttestationAgent.
+// After a launcher restart, the eventlog persisted by the previous run may
+// already contain some or all of the claims. Measuring them again would make
+// the eventlog invalid, so only the missing claims are measured, as long as
+// the measured ones did not change.
 func (r *ContainerRunner) measureContainerClaims(ctx context.Context) error {
-	image, err := r.container.Image(ctx)
+	claims, err := r.containerClaims(ctx)
 	if err != nil {
 		return err
 	}
-	if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte(image.Name())}); err != nil {
+	measured, err := r.attestAgent.MeasuredEvents()
+	if err != nil {
 		return err
 	}
-	if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImageDigestType, EventContent: []byte(image.Target().Digest)}); err != nil {
-		return err
+	launched := launchEvents(measured)
+	if len(launched) > len(claims) || !equalEvents(launched, claims[:len(launched)]) {
+		return errors.New("the container claims differ from the ones measured before the launcher restarted, reboot to launch another workload")
 	}
-	if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.RestartPolicyType, EventContent: []byte(r.launchSpec.RestartPolicy)}); err != nil {
-		return err
+	// An earlier launcher run launched the workload and measured its
+	// restarts: relaunching it is one more restart.
+	if len(launched) == len(claims) {
+		restarts, err := restartCount(measured)
+		if err != nil {
+			return err
+		}
+		r.earlierRuns = restarts + 1
 	}
-	if imageConfig, err := image.Config(ctx); err == nil { // if NO error
-		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImageIDType, EventContent: []byte(imageConfig.Digest)}); err != nil {
+	for _, claim := range claims[len(launched):] {
+		if err := r.attestAgent.MeasureEvent(claim); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	containerSpec, err := r.container.Spec(ctx)
-	if err != nil {
-		return err
+// containerClaims returns the container claims to measure, ending with the
+// LaunchSeparator.
+func (r *ContainerRunner) containerClaims(ctx context.Context) ([]cel.CosTlv, error) {
+	var claims []cel.CosTlv
+	if r.launchSpec.LaunchSpecSigner != "" {
+		claims = append(claims, cel.CosTlv{EventType: cel.LaunchSpecSignerType, EventContent: []byte(r.launchSpec.LaunchSpecSigner)})
 	}
-	for _, arg := range containerSpec.Process.Args {
-		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ArgType, EventContent: []byte(arg)}); err != nil {
-			return err
-		}
+	containerClaims, err := containerEvents(ctx, r.container, r.imageSigner, r.launchSpec.RestartPolicy, r.launchSpec.Envs, r.launchSpec.Cmd)
+	if err != nil {
+		return nil, err
 	}
-	for _, env := range containerSpec.Process.Env {
-		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.EnvVarType, EventContent: []byte(env)}); err != nil {
-			return err
+	claims = append(claims, containerClaims...)
+	for _, m := range r.launchSpec.Mounts {
+		content, err := cel.FormatMount(cel.Mount{
+			Type:        string(m.Type),
+			Source:      m.Source,
+			Destination: m.Destination,
+			ReadOnly:    m.ReadOnly,
+			SizeBytes:   m.SizeBytes,
+		})
+		if err != nil {
+			return nil, err
 		}
+		claims = append(claims, cel.CosTlv{EventType: cel.MountType, EventContent: content})
 	}
-
-	// Measure the input overridden Env Vars and Args separately, these should be subsets of the Env Vars and Args above.
-	envs, err := formatEnvVars(r.launchSpec.Envs)
+	resourceClaims, err := resourceEvents(r.launchSpec)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, env := range envs {
-		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.OverrideEnvType, EventContent: []byte(env)}); err != nil {
-			return err
+	claims = append(claims, resourceClaims...)
+
+	// The events of each sidecar follow its index, the workload being at
+	// index 0.
+	for i, s := range r.sidecars {
+		index, err := cel.FormatContainerIndex(i + 1)
+		if err != nil {
+			return nil, err
 		}
-	}
-	for _, arg := range r.launchSpec.Cmd {
-		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.OverrideArgType, EventContent: []byte(arg)}); err != nil {
-			return err
+		claims = append(claims, cel.CosTlv{EventType: cel.ContainerIndexType, EventContent: index})
+		// The launcher does not restart the sidecars.
+		sidecarClaims, err := containerEvents(ctx, s.container, s.imageSigner, spec.Never, s.spec.Envs, s.spec.Cmd)
+		if err != nil {
+			return nil, err
 		}
+		claims = append(claims, sidecarClaims...)
+		// The sidecars have the resources of the workload, see
+		// sidecarSpecOpts.
+		claims = append(claims, resourceClaims...)
 	}
 
 	separator := cel.CosTlv{
 		EventType:    cel.LaunchSeparatorType,
 		EventContent: nil, // Success
 	}
-	return r.attestAgent.MeasureEvent(separator)
+	return append(claims, separator), nil
+}
+
+// resourceEvents returns the events measuring the capabilities, network and
+// memory limit requested by the operator, as applied by resourceSpecOpts.
+func resourceEvents(launchSpec spec.LaunchSpec) ([]cel.CosTlv, error) {
+	var events []cel.CosTlv
+	for _, c := range launchSpec.AddedCapabilities {
+		content, err := cel.FormatCapability(c)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, cel.CosTlv{EventType: cel.CapabilityType, EventContent: content})
+	}
+	events = append(events, cel.CosTlv{EventType: cel.HostNetworkType, EventContent: cel.FormatHostNetwork(launchSpec.HostNetwork)})
+	if launchSpec.MemoryLimitMB != 0 {
+		content, err := cel.FormatMemoryLimit(launchSpec.MemoryLimitMB)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, cel.CosTlv{EventType: cel.MemoryLimitType, EventContent: content})
+	}
+	return events, nil
+}
+
+// launchEvents returns the events up to and including the LaunchSeparator.
+func launchEvents(events []cel.CosTlv) []cel.CosTlv {
+	for i, event := range events {
+		if event.EventType == cel.LaunchSeparatorType {
+			return events[:i+1]
+		}
+	}
+	return events
+}
+
+// restartCount returns the last RestartCount measured in the events, or 0 if
+// the workload was not restarted.
+func restartCount(events []cel.CosTlv) (int, error) {
+	count := 0
+	for _, event := range events {
+		if event.EventType != cel.RestartCountType {
+			continue
+		}
+		var err error
+		if count, err = cel.ParseRestartCount(event.EventContent); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+func equalEvents(a, b []cel.CosTlv) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].EventType != b[i].EventType || !bytes.Equal(a[i].EventContent, b[i].EventContent) {
+			return false
+		}
+	}
+	return true
+}
+
+// containerEvents returns the events measuring the image, restart policy,
+// args and env vars of the container, and the overrides of the operator.
+func containerEvents(ctx context.Context, container containerd.Container, imageSigner string, restartPolicy spec.RestartPolicy, overrideEnvs []spec.EnvVar, overrideCmd []string) ([]cel.CosTlv, error) {
+	image, err := container.Image(ctx)
+	if err != nil {
+		return nil, err
+	}
+	events := []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte(image.Name())},
+		{EventType: cel.ImageDigestType, EventContent: []byte(image.Target().Digest)},
+	}
+	if imageSigner != "" {
+		events = append(events, cel.CosTlv{EventType: cel.ImageSignerType, EventContent: []byte(imageSigner)})
+	}
+	events = append(events, cel.CosTlv{EventType: cel.RestartPolicyType, EventContent: []byte(restartPolicy)})
+	if imageConfig, err := image.Config(ctx); err == nil { // if NO error
+		events = append(events, cel.CosTlv{EventType: cel.ImageIDType, EventContent: []byte(imageConfig.Digest)})
+	}
+
+	containerSpec, err := container.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range containerSpec.Process.Args {
+		events = append(events, cel.CosTlv{EventType: cel.ArgType, EventContent: []byte(arg)})
+	}
+	for _, env := range containerSpec.Process.Env {
+		events = append(events, cel.CosTlv{EventType: cel.EnvVarType, EventContent: []byte(env)})
+	}
+
+	// Measure the input overridden Env Vars and Args separately, these should be subsets of the Env Vars and Args above.
+	envs, err := formatEnvVars(overrideEnvs)
+	if err != nil {
+		return nil, err
+	}
+	for _, env := range envs {
+		events = append(events, cel.CosTlv{EventType: cel.OverrideEnvType, EventContent: []byte(env)})
+	}
+	for _, arg := range overrideCmd {
+		events = append(events, cel.CosTlv{EventType: cel.OverrideArgType, EventContent: []byte(arg)})
+	}
+	return events, nil
 }
 
 // Retrieves an OIDC token from the attestation service, and returns how long
 // to wait before attemping to refresh it.
 func (r *ContainerRunner) refreshToken(ctx context.Context) (time.Duration, error) {
 	r.logger.Print("refreshing attestation verifier OIDC token")
-	token, err := r.attestAgent.Attest(ctx)
+	token, err := r.attestAgent.Attest(ctx, agent.AttestAgentOpts{})
 	if err != nil {
 		return 0, fmt.Errorf("failed to retrieve attestation service token: %v", err)
 	}
@@ -387,7 +842,10 @@ func (r *ContainerRunner) fetchAndWriteTokenWithRetry(ctx context.Context,
 
 	// Set a timer to refresh the token before it expires.
 	timer := time.NewTimer(duration)
+	done := make(chan struct{})
+	r.refresherDone = done
 	go func() {
+		defer close(done)
 		for {
 			select {
 			case <-ctx.Done():
@@ -402,7 +860,7 @@ func (r *ContainerRunner) fetchAndWriteTokenWithRetry(ctx context.Context,
 						duration, err = r.refreshToken(ctx)
 						return err
 					},
-					retry,
+					backoff.WithContext(retry, ctx),
 					func(err error, t time.Duration) {
 						r.logger.Printf("failed to refresh attestation service token at time %v: %v", t, err)
 					})
@@ -463,11 +921,34 @@ func defaultRetryPolicy() *backoff.ExponentialBackOff {
 	return expBack
 }
 
+// defaultRestartPolicy is the backoff between the restarts of the workload
+// task: 10 seconds doubling up to 5 minutes, like the Kubernetes container
+// restart backoff. It is reset once the task ran for restartBackoffReset.
+func defaultRestartPolicy() *backoff.ExponentialBackOff {
+	expBack := backoff.NewExponentialBackOff()
+	expBack.InitialInterval = 10 * time.Second
+	expBack.RandomizationFactor = 0
+	expBack.Multiplier = 2
+	expBack.MaxInterval = 5 * time.Minute
+	// Never stop restarting.
+	expBack.MaxElapsedTime = 0
+	return expBack
+}
+
 // Run the container
 // Container output will always be redirected to logger writer for now
+// The workload task is restarted in place following the restart policy, so
+// Run only returns once the workload is done or fails to (re)start.
 func (r *ContainerRunner) Run(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	defer func() {
+		cancel()
+		// Stop the token refresher before returning, so it does not write
+		// tokens for a workload that is gone.
+		if r.refresherDone != nil {
+			<-r.refresherDone
+		}
+	}()
 
 	if err := r.measureContainerClaims(ctx); err != nil {
 		return fmt.Errorf("failed to measure container claims: %v", err)
@@ -475,6 +956,24 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 	if err := r.fetchAndWriteToken(ctx); err != nil {
 		return fmt.Errorf("failed to fetch and write OIDC token: %v", err)
 	}
+	// The disk keys are bound to the workload, so they must only be
+	// requested after the LaunchSeparator was measured.
+	if err := r.unlockDisks(ctx); err != nil {
+		return fmt.Errorf("failed to unlock encrypted disks: %v", err)
+	}
+
+	// The workload API must only be served after the LaunchSeparator was
+	// measured by measureContainerClaims.
+	teeServer, err := teeserver.New(path.Join(hostTokenPath, teeServerSocket), r.attestAgent)
+	if err != nil {
+		return fmt.Errorf("failed to create the TEE server: %v", err)
+	}
+	go func() {
+		if err := teeServer.Serve(); !errors.Is(err, http.ErrServerClosed) {
+			r.logger.Printf("TEE server stopped: %v", err)
+		}
+	}()
+	defer teeServer.Shutdown(ctx)
 
 	var streamOpt cio.Opt
 	if r.launchSpec.LogRedirect {
@@ -485,6 +984,82 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 		r.logger.Println("container stdout/stderr will not be redirected")
 	}
 
+	// The sidecars are started by the first runTask, so they serve the
+	// workload once it starts, and are stopped once it exits.
+	defer r.stopSidecars(ctx)
+
+	return r.runWorkloadWithBackoff(ctx, streamOpt, defaultRestartPolicy())
+}
+
+// runWorkloadWithBackoff runs the workload task, and restarts it following
+// the restart policy of the launch spec, waiting for the restart backoff
+// before each restart. Each restart is measured into the COS eventlog by
+// runTask, before the restarted task starts, counting the runs of the earlier
+// launcher runs. It returns the error of the last run, or of a failed
+// restart.
+func (r *ContainerRunner) runWorkloadWithBackoff(ctx context.Context, streamOpt cio.Opt, restartBackoff *backoff.ExponentialBackOff) error {
+	restartBackoff.Reset()
+	for restarts := r.earlierRuns; ; {
+		started := time.Now()
+		err := r.runTask(ctx, streamOpt, restarts)
+		if r.stopSignal != 0 {
+			return stoppedResult(err)
+		}
+		if !shouldRestart(r.launchSpec.RestartPolicy, err) {
+			return err
+		}
+		// A workload that ran for a while is restarted without the backoff
+		// of its earlier failures.
+		if time.Since(started) > restartBackoffReset {
+			restartBackoff.Reset()
+		}
+		wait := restartBackoff.NextBackOff()
+		r.logger.Printf("restarting workload task in %v (restart policy %s)\n", wait, r.launchSpec.RestartPolicy)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sig := <-r.stopC:
+			r.terminate(sig)
+			return nil
+		case <-time.After(wait):
+		}
+		restarts++
+	}
+}
+
+// stoppedResult returns the result of a run of the workload stopped with
+// Stop. The workload exiting non-zero or being killed once stopped is the
+// expected outcome of the stop, so it is not a WorkloadError.
+func stoppedResult(err error) error {
+	var workloadErr *WorkloadError
+	if errors.As(err, &workloadErr) {
+		return nil
+	}
+	return err
+}
+
+// shouldRestart returns whether the workload task is restarted after it
+// ended with the error, following the restart policy. Tasks failing to start
+// are not restarted, the VM is rebooted to retry them instead.
+func shouldRestart(restartPolicy spec.RestartPolicy, err error) bool {
+	var workloadErr *WorkloadError
+	switch restartPolicy {
+	case spec.Always:
+		return err == nil || errors.As(err, &workloadErr)
+	case spec.OnFailure:
+		return errors.As(err, &workloadErr)
+	}
+	return false
+}
+
+// runTask runs the workload task until it ends. The restarts are the number
+// of earlier runs of the task: the RestartCount of a restarted task is
+// measured before it starts, so it never runs unmeasured. It returns a
+// WorkloadError if the task returned non-zero.
+func (r *ContainerRunner) runTask(ctx context.Context, streamOpt cio.Opt, restarts int) error {
+	if err := r.joinSidecarsNetwork(ctx); err != nil {
+		return &RetryableError{err}
+	}
 	task, err := r.container.NewTask(ctx, cio.NewCreator(streamOpt))
 	if err != nil {
 		return &RetryableError{err}
@@ -495,12 +1070,29 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 	if err != nil {
 		r.logger.Println(err)
 	}
+	// The sidecars are started once, in the network namespace of the first
+	// task, before it starts.
+	if err := r.startSidecars(ctx, streamOpt, task.Pid()); err != nil {
+		return err
+	}
 	r.logger.Println("workload task started")
 
+	if restarts > 0 {
+		if err := r.measureRestart(restarts); err != nil {
+			return err
+		}
+	}
+	// A task failing to start after its restart was measured is left to a
+	// VM reboot, as the eventlog cannot record that it did not run.
 	if err := task.Start(ctx); err != nil {
 		return &RetryableError{err}
 	}
-	status := <-exitStatusC
+	var status containerd.ExitStatus
+	select {
+	case status = <-exitStatusC:
+	case sig := <-r.stopC:
+		status = r.stopTask(ctx, task, exitStatusC, sig)
+	}
 
 	code, _, err := status.Result()
 	if err != nil {
@@ -515,23 +1107,266 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 	return nil
 }
 
-func initImage(ctx context.Context, cdClient *containerd.Client, launchSpec spec.LaunchSpec, token oauth2.Token, logger *log.Logger) (containerd.Image, error) {
+// measureRestart measures the RestartCount of the workload into the COS
+// eventlog.
+func (r *ContainerRunner) measureRestart(restarts int) error {
+	count, err := cel.FormatRestartCount(restarts)
+	if err != nil {
+		return err
+	}
+	if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.RestartCountType, EventContent: count}); err != nil {
+		return fmt.Errorf("failed to measure workload restart: %v", err)
+	}
+	return nil
+}
+
+// Stop stops the workload, e.g. when the VM is preempted: the signal is
+// measured into the COS eventlog and forwarded to the workload task, which is
+// killed if it does not exit within the StopGracePeriod of the launch spec.
+// Run then returns without restarting it. Stop can be called from another
+// goroutine than Run.
+func (r *ContainerRunner) Stop(sig syscall.Signal) {
+	select {
+	case r.stopC <- sig:
+	default:
+		// The workload is already being stopped.
+	}
+}
+
+// terminate measures the termination of the workload with the signal, and
+// prevents its restart.
+func (r *ContainerRunner) terminate(sig syscall.Signal) {
+	r.stopSignal = sig
+	r.logger.Printf("stopping workload with %s\n", signalName(sig))
+	// The workload must be stopped even if the termination cannot be
+	// measured.
+	content, err := cel.FormatTerminationSignal(signalName(sig))
+	if err == nil {
+		err = r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.TerminationType, EventContent: content})
+	}
+	if err != nil {
+		r.logger.Printf("failed to measure workload termination: %v\n", err)
+	}
+}
+
+// stopTask terminates the workload with the signal, forwards it to the task,
+// and kills the task if it does not exit within the StopGracePeriod. It
+// returns the exit status of the task.
+func (r *ContainerRunner) stopTask(ctx context.Context, task containerd.Task, exitStatusC <-chan containerd.ExitStatus, sig syscall.Signal) containerd.ExitStatus {
+	r.terminate(sig)
+	if err := task.Kill(ctx, sig); err != nil {
+		r.logger.Printf("failed to forward %s to the workload task: %v\n", signalName(sig), err)
+	}
+	timer := time.NewTimer(r.launchSpec.StopGracePeriod)
+	defer timer.Stop()
+	select {
+	case status := <-exitStatusC:
+		return status
+	case <-timer.C:
+	}
+
+	r.logger.Printf("workload task did not exit within %v, killing it\n", r.launchSpec.StopGracePeriod)
+	if err := task.Kill(ctx, syscall.SIGKILL); err != nil {
+		r.logger.Printf("failed to kill the workload task: %v\n", err)
+	}
+	return <-exitStatusC
+}
+
+// signalName returns the name of the stop signal, e.g. "SIGTERM".
+func signalName(sig syscall.Signal) string {
+	switch sig {
+	case syscall.SIGTERM:
+		return "SIGTERM"
+	case syscall.SIGINT:
+		return "SIGINT"
+	case syscall.SIGKILL:
+		return "SIGKILL"
+	}
+	return fmt.Sprintf("SIG%d", int(sig))
+}
+
+// startSidecars creates and starts the tasks of the sidecars that were not
+// started yet. Without the host network, they join the network namespace of
+// the workload task with the given pid, so they reach each other over its
+// loopback interface.
+func (r *ContainerRunner) startSidecars(ctx context.Context, streamOpt cio.Opt, workloadPid uint32) error {
+	for _, s := range r.sidecars {
+		if s.task != nil {
+			continue
+		}
+		if !r.launchSpec.HostNetwork {
+			if err := setNetworkNamespace(ctx, s.container, networkNamespacePath(workloadPid)); err != nil {
+				return &RetryableError{fmt.Errorf("failed to set the network namespace of sidecar %s: %w", s.spec.Name, err)}
+			}
+		}
+		task, err := s.container.NewTask(ctx, cio.NewCreator(streamOpt))
+		if err != nil {
+			return &RetryableError{fmt.Errorf("failed to create sidecar %s task: %w", s.spec.Name, err)}
+		}
+		s.task = task
+		exitStatusC, err := task.Wait(ctx)
+		if err != nil {
+			return &RetryableError{fmt.Errorf("failed to wait for sidecar %s task: %w", s.spec.Name, err)}
+		}
+		if err := task.Start(ctx); err != nil {
+			return &RetryableError{fmt.Errorf("failed to start sidecar %s task: %w", s.spec.Name, err)}
+		}
+		r.logger.Printf("sidecar %s task started\n", s.spec.Name)
+		s.exited = make(chan struct{})
+		go func(name string, exited chan struct{}) {
+			status := <-exitStatusC
+			r.logger.Printf("sidecar %s task ended and returned %d\n", name, status.ExitCode())
+			close(exited)
+		}(s.spec.Name, s.exited)
+	}
+	return nil
+}
+
+// joinSidecarsNetwork makes the next workload task join the network
+// namespace of the running sidecars, which the earlier workload tasks shared
+// with them. The workload gets a new network namespace if none is running.
+func (r *ContainerRunner) joinSidecarsNetwork(ctx context.Context) error {
+	if r.launchSpec.HostNetwork {
+		return nil
+	}
+	started := false
+	for _, s := range r.sidecars {
+		if s.exited == nil {
+			continue
+		}
+		started = true
+		select {
+		case <-s.exited:
+			continue
+		default:
+		}
+		return setNetworkNamespace(ctx, r.container, networkNamespacePath(s.task.Pid()))
+	}
+	if !started {
+		return nil
+	}
+	return setNetworkNamespace(ctx, r.container, "")
+}
+
+// networkNamespacePath returns the path of the network namespace of the
+// process with the given pid.
+func networkNamespacePath(pid uint32) string {
+	return fmt.Sprintf("/proc/%d/ns/net", pid)
+}
+
+// setNetworkNamespace updates the spec of the container, so its next tasks
+// join the network namespace at the path, or get a new one if it is empty.
+func setNetworkNamespace(ctx context.Context, container containerd.Container, path string) error {
+	s, err := container.Spec(ctx)
+	if err != nil {
+		return err
+	}
+	return container.Update(ctx, containerd.UpdateContainerOpts(containerd.WithSpec(s, oci.WithLinuxNamespace(specs.LinuxNamespace{
+		Type: specs.NetworkNamespace,
+		Path: path,
+	}))))
+}
+
+// stopSidecars stops the started tasks of the sidecars like the workload:
+// the stop signal of the workload (SIGTERM if it exited by itself) is
+// forwarded to them, and they are killed if they do not exit within the
+// StopGracePeriod. Their tasks are then deleted.
+func (r *ContainerRunner) stopSidecars(ctx context.Context) {
+	sig := r.stopSignal
+	if sig == 0 {
+		sig = syscall.SIGTERM
+	}
+	for _, s := range r.sidecars {
+		if s.exited == nil {
+			continue
+		}
+		if err := s.task.Kill(ctx, sig); err != nil {
+			r.logger.Printf("failed to forward %s to the sidecar %s task: %v\n", signalName(sig), s.spec.Name, err)
+		}
+	}
+	graceCtx, cancel := context.WithTimeout(ctx, r.launchSpec.StopGracePeriod)
+	defer cancel()
+	for _, s := range r.sidecars {
+		if s.exited == nil {
+			continue
+		}
+		select {
+		case <-s.exited:
+		case <-graceCtx.Done():
+			r.logger.Printf("sidecar %s task did not exit within %v, killing it\n", s.spec.Name, r.launchSpec.StopGracePeriod)
+			if err := s.task.Kill(ctx, syscall.SIGKILL); err != nil {
+				r.logger.Printf("failed to kill the sidecar %s task: %v\n", s.spec.Name, err)
+			}
+		}
+	}
+
+	for _, s := range r.sidecars {
+		if s.task == nil {
+			continue
+		}
+		if _, err := s.task.Delete(ctx, containerd.WithProcessKill); err != nil {
+			r.logger.Printf("failed to delete sidecar %s task: %v\n", s.spec.Name, err)
+		}
+		s.task = nil
+		s.exited = nil
+	}
+}
+
+func initImage(ctx context.Context, cdClient *containerd.Client, imageRef string, token oauth2.Token, logger *log.Logger) (containerd.Image, error) {
 	if token.Valid() {
 		remoteOpt := containerd.WithResolver(Resolver(token.AccessToken))
 
-		image, err := cdClient.Pull(ctx, launchSpec.ImageRef, containerd.WithPullUnpack, remoteOpt)
+		image, err := cdClient.Pull(ctx, imageRef, containerd.WithPullUnpack, remoteOpt)
 		if err != nil {
 			return nil, fmt.Errorf("cannot pull the image: %w", err)
 		}
 		return image, nil
 	}
-	image, err := cdClient.Pull(ctx, launchSpec.ImageRef, containerd.WithPullUnpack)
+	image, err := cdClient.Pull(ctx, imageRef, containerd.WithPullUnpack)
 	if err != nil {
 		return nil, fmt.Errorf("cannot pull the image (no token, only works for a public image): %w", err)
 	}
 	return image, nil
 }
 
+// imageResolver returns the resolver for the image registries, authenticated
+// with the token if it is valid.
+func imageResolver(token oauth2.Token) remotes.Resolver {
+	if token.Valid() {
+		return Resolver(token.AccessToken)
+	}
+	return docker.NewResolver(docker.ResolverOptions{})
+}
+
+// verifyImageSignature verifies the image is signed by one of the image
+// signing keys of the launch spec, and returns the identity of the signer.
+// Without image signing keys, the signature is not verified, and the image is
+// refused in hardened mode.
+func verifyImageSignature(ctx context.Context, fetcher imagesig.Fetcher, imageRef string, imageDigest digest.Digest, launchSpec spec.LaunchSpec, logger *log.Logger) (string, error) {
+	if len(launchSpec.ImageSigningKeys) == 0 {
+		if launchSpec.Hardened {
+			return "", errors.New("refusing to run an unverified image in hardened mode, set tee-image-signing-keys to verify the image signature")
+		}
+		logger.Println("No image signing keys (tee-image-signing-keys), skipping image signature verification")
+		return "", nil
+	}
+
+	sigs, err := fetcher.Signatures(ctx, imageRef, imageDigest)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the image signatures: %v", err)
+	}
+	key, err := imagesig.Verify(sigs, imageDigest, launchSpec.ImageSigningKeys)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify the image signature: %v", err)
+	}
+	signer, err := cel.FormatImageSigner(key)
+	if err != nil {
+		return "", err
+	}
+	logger.Printf("Image Signer               : %v\n", signer)
+	return signer, nil
+}
+
 func getImageLabels(ctx context.Context, image containerd.Image) (map[string]string, error) {
 	// TODO(jiankun): Switch to containerd's WithImageConfigLabels()
 	ic, err := image.Config(ctx)
@@ -553,9 +1388,42 @@ func getImageLabels(ctx context.Context, image containerd.Image) (map[string]str
 	return nil, fmt.Errorf("unknown image config media type %s", ic.MediaType)
 }
 
+// unlockDisks unlocks the encrypted disks with their keys, and mounts them on
+// the host to be bind mounted into the container.
+func (r *ContainerRunner) unlockDisks(ctx context.Context) error {
+	for _, d := range r.disks {
+		if d.volume != nil {
+			continue
+		}
+		key, err := d.key.Key(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get the key of %s: %v", d.mount.Source, err)
+		}
+		volume, err := disk.Open(ctx, d.mount.Source, diskName(d.index), diskMountPoint(d.index), key, d.mount.ReadOnly)
+		if err != nil {
+			return err
+		}
+		d.volume = volume
+		r.logger.Printf("Unlocked encrypted disk %s for %s\n", d.mount.Source, d.mount.Destination)
+	}
+	return nil
+}
+
 // Close the container runner
 func (r *ContainerRunner) Close(ctx context.Context) {
 	// Exit gracefully:
 	// Delete container and close connection to attestation service.
 	r.container.Delete(ctx, containerd.WithSnapshotCleanup)
+	for _, s := range r.sidecars {
+		s.container.Delete(ctx, containerd.WithSnapshotCleanup)
+	}
+	for _, d := range r.disks {
+		if d.volume == nil {
+			continue
+		}
+		if err := d.volume.Close(ctx); err != nil {
+			r.logger.Printf("failed to lock encrypted disk %s: %v\n", d.mount.Source, err)
+		}
+		d.volume = nil
+	}
 }
@@ -3,8 +3,12 @@ package launcher
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,17 +17,36 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/cio"
+	"github.com/containerd/containerd/containers"
 	"github.com/containerd/containerd/defaults"
 	"github.com/containerd/containerd/namespaces"
+	"github.com/containerd/containerd/oci"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/imagesig"
 	"github.com/google/go-tpm-tools/launcher/spec"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/option"
 )
@@ -46,7 +69,7 @@ func (f *fakeAttestationAgent) MeasureEvent(event cel.Content) error {
 	return fmt.Errorf("unimplemented")
 }
 
-func (f *fakeAttestationAgent) Attest(ctx context.Context) ([]byte, error) {
+func (f *fakeAttestationAgent) Attest(ctx context.Context, _ agent.AttestAgentOpts) ([]byte, error) {
 	if f.attestFunc != nil {
 		return f.attestFunc(ctx)
 	}
@@ -54,6 +77,26 @@ func (f *fakeAttestationAgent) Attest(ctx context.Context) ([]byte, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
 
+func (f *fakeAttestationAgent) AttestationEvidence(nonce []byte) (*attestpb.Attestation, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (f *fakeAttestationAgent) MeasuredEvents() ([]cel.CosTlv, error) {
+	return nil, nil
+}
+
+func (f *fakeAttestationAgent) Unseal(*tpmpb.SealedBytes) ([]byte, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (f *fakeAttestationAgent) EKCert() ([]byte, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (f *fakeAttestationAgent) ImportSecret(*tpmpb.ImportBlob) ([]byte, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
 func createJWT(t *testing.T, ttl time.Duration) []byte {
 	return createJWTWithID(t, "test token", ttl)
 }
@@ -480,7 +523,7 @@ func TestInitImageDockerPublic(t *testing.T) {
 	ctx := namespaces.WithNamespace(context.Background(), "test")
 	// This is a "valid" token (formatwise)
 	validToken := oauth2.Token{AccessToken: "000000", Expiry: time.Now().Add(time.Hour)}
-	if _, err := initImage(ctx, containerdClient, spec.LaunchSpec{ImageRef: "docker.io/library/hello-world:latest"}, validToken, log.Default()); err != nil {
+	if _, err := initImage(ctx, containerdClient, "docker.io/library/hello-world:latest", validToken, log.Default()); err != nil {
 		t.Error(err)
 	} else {
 		if err := containerdClient.ImageService().Delete(ctx, "docker.io/library/hello-world:latest"); err != nil {
@@ -489,7 +532,7 @@ func TestInitImageDockerPublic(t *testing.T) {
 	}
 
 	invalidToken := oauth2.Token{}
-	if _, err := initImage(ctx, containerdClient, spec.LaunchSpec{ImageRef: "docker.io/library/hello-world:latest"}, invalidToken, log.Default()); err != nil {
+	if _, err := initImage(ctx, containerdClient, "docker.io/library/hello-world:latest", invalidToken, log.Default()); err != nil {
 		t.Error(err)
 	} else {
 		if err := containerdClient.ImageService().Delete(ctx, "docker.io/library/hello-world:latest"); err != nil {
@@ -497,3 +540,807 @@ func TestInitImageDockerPublic(t *testing.T) {
 		}
 	}
 }
+
+type fakeSignatureFetcher struct {
+	sigs []imagesig.Signature
+	err  error
+}
+
+func (f *fakeSignatureFetcher) Signatures(context.Context, string, digest.Digest) ([]imagesig.Signature, error) {
+	return f.sigs, f.err
+}
+
+func TestVerifyImageSignature(t *testing.T) {
+	imageDigest := digest.FromString("image manifest")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"docker.io/library/hello-world"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, imageDigest))
+	hashed := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := &fakeSignatureFetcher{sigs: []imagesig.Signature{{Payload: payload, Signature: signature}}}
+	wantSigner, err := cel.FormatImageSigner(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []crypto.PublicKey{key.Public()}
+
+	testCases := []struct {
+		name       string
+		fetcher    imagesig.Fetcher
+		launchSpec spec.LaunchSpec
+		wantSigner string
+		wantErr    bool
+	}{
+		{"Signed", signed, spec.LaunchSpec{ImageSigningKeys: keys}, wantSigner, false},
+		{"SignedHardened", signed, spec.LaunchSpec{ImageSigningKeys: keys, Hardened: true}, wantSigner, false},
+		{"Unsigned", &fakeSignatureFetcher{}, spec.LaunchSpec{ImageSigningKeys: keys}, "", true},
+		{"FetchFailed", &fakeSignatureFetcher{err: errors.New("registry unavailable")}, spec.LaunchSpec{ImageSigningKeys: keys}, "", true},
+		{"NoKeys", &fakeSignatureFetcher{}, spec.LaunchSpec{}, "", false},
+		{"NoKeysHardened", signed, spec.LaunchSpec{Hardened: true}, "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			signer, err := verifyImageSignature(context.Background(), tc.fetcher, "docker.io/library/hello-world:latest", imageDigest, tc.launchSpec, log.Default())
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("verifyImageSignature() got err %v, wantErr %v", err, tc.wantErr)
+			}
+			if signer != tc.wantSigner {
+				t.Errorf("verifyImageSignature() got signer %q, want %q", signer, tc.wantSigner)
+			}
+		})
+	}
+}
+
+func TestAppendOperatorMounts(t *testing.T) {
+	mounts := appendOperatorMounts(nil, []spec.Mount{
+		{Type: spec.BindMount, Source: "/mnt/disks/data", Destination: "/data", ReadOnly: true},
+		{Type: spec.BindMount, Source: "/var/log/app", Destination: "/logs"},
+		{Type: spec.TmpfsMount, Destination: "/scratch", SizeBytes: 64 << 20},
+		{Type: spec.LuksMount, Source: "/dev/sdb", Destination: "/secrets", ReadOnly: true, SealedKey: []byte("key")},
+	})
+	want := []specs.Mount{
+		{Destination: "/data", Type: "bind", Source: "/mnt/disks/data", Options: []string{"rbind", "ro"}},
+		{Destination: "/logs", Type: "bind", Source: "/var/log/app", Options: []string{"rbind", "rw"}},
+		{Destination: "/scratch", Type: "tmpfs", Source: "tmpfs", Options: []string{"nosuid", "nodev", "size=67108864"}},
+		{Destination: "/secrets", Type: "bind", Source: "/run/container_launcher/disks/tee-disk-3", Options: []string{"rbind", "ro"}},
+	}
+	if diff := cmp.Diff(want, mounts); diff != "" {
+		t.Errorf("appendOperatorMounts() got unexpected mounts (-want +got):\n%s", diff)
+	}
+}
+
+func TestResolveMountSources(t *testing.T) {
+	dir := t.TempDir()
+	target := path.Join(dir, "secrets")
+	if err := os.Mkdir(target, 0700); err != nil {
+		t.Fatal(err)
+	}
+	// The symlink is under an allowed-looking directory, but points outside.
+	link := path.Join(dir, "data")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	resolvedTarget, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mounts := []spec.Mount{
+		{Type: spec.BindMount, Source: link, Destination: "/data"},
+		{Type: spec.TmpfsMount, Destination: "/scratch", SizeBytes: 64 << 20},
+	}
+	got, err := resolveMountSources(mounts)
+	if err != nil {
+		t.Fatalf("resolveMountSources() failed: %v", err)
+	}
+	want := []spec.Mount{
+		{Type: spec.BindMount, Source: resolvedTarget, Destination: "/data"},
+		{Type: spec.TmpfsMount, Destination: "/scratch", SizeBytes: 64 << 20},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("resolveMountSources() got unexpected mounts (-want +got):\n%s", diff)
+	}
+	if mounts[0].Source != link {
+		t.Error("resolveMountSources() modified its argument")
+	}
+
+	if _, err := resolveMountSources([]spec.Mount{{Type: spec.BindMount, Source: path.Join(dir, "missing"), Destination: "/data"}}); err == nil {
+		t.Error("resolveMountSources() succeeded with a missing source")
+	}
+}
+
+func TestNewEncryptedDisks(t *testing.T) {
+	mounts := []spec.Mount{
+		{Type: spec.BindMount, Source: "/var/log/app", Destination: "/logs"},
+		{Type: spec.LuksMount, Source: "/dev/sdb", Destination: "/secrets", KeyReleaseEndpoint: "https://kr.example.com", WrappedKey: []byte("key")},
+		{Type: spec.LuksMount, Source: "/dev/sdc", Destination: "/keys", KeyReleaseEndpoint: "https://kr.example.com", KeyReleaseSecret: "disk-key"},
+	}
+	disks, err := newEncryptedDisks(mounts, &fakeAttestationAgent{})
+	if err != nil {
+		t.Fatalf("newEncryptedDisks() failed: %v", err)
+	}
+	if len(disks) != 2 || disks[0].index != 1 || disks[0].mount.Source != "/dev/sdb" || disks[1].index != 2 || disks[1].mount.Source != "/dev/sdc" {
+		t.Errorf("newEncryptedDisks() got %+v, want the luks mounts at indexes 1 and 2", disks)
+	}
+
+	mounts = append(mounts, spec.Mount{Type: spec.LuksMount, Source: "/dev/sdd", Destination: "/data", SealedKey: []byte("sealed")})
+	if _, err := newEncryptedDisks(mounts, &fakeAttestationAgent{}); err == nil {
+		t.Error("newEncryptedDisks() with a malformed sealed key succeeded")
+	}
+}
+
+// fakeContainer is a created container of the image, with the spec. Its
+// tasks exit with the exitCodes in order, and fail to be created after.
+type fakeContainer struct {
+	containerd.Container
+	image     containerd.Image
+	spec      *oci.Spec
+	exitCodes []uint32
+	tasks     int
+	// If exitOn is set, the tasks run until they are killed with it, and
+	// exit with 128 plus the signal.
+	exitOn syscall.Signal
+	// signals are the signals the tasks were killed with.
+	signals []syscall.Signal
+	// pid is added to the number of each task to get its pid.
+	pid uint32
+	// started is the number of tasks started. If failStart is set, the
+	// task with that number fails to start.
+	started   int
+	failStart int
+}
+
+func (c *fakeContainer) NewTask(context.Context, cio.Creator, ...containerd.NewTaskOpts) (containerd.Task, error) {
+	if c.tasks == len(c.exitCodes) {
+		return nil, errors.New("no more tasks")
+	}
+	c.tasks++
+	task := &fakeTask{container: c, pid: c.pid + uint32(c.tasks), exitCode: c.exitCodes[c.tasks-1], exitC: make(chan containerd.ExitStatus, 1)}
+	return task, nil
+}
+
+type fakeTask struct {
+	containerd.Task
+	container *fakeContainer
+	pid       uint32
+	exitCode  uint32
+	exitC     chan containerd.ExitStatus
+}
+
+func (t *fakeTask) exit() {
+	t.exitC <- *containerd.NewExitStatus(t.exitCode, time.Now(), nil)
+}
+
+func (t *fakeTask) Wait(context.Context) (<-chan containerd.ExitStatus, error) {
+	return t.exitC, nil
+}
+
+func (t *fakeTask) Kill(_ context.Context, sig syscall.Signal, _ ...containerd.KillOpts) error {
+	t.container.signals = append(t.container.signals, sig)
+	if sig == t.container.exitOn {
+		t.exitCode = 128 + uint32(sig)
+		t.exit()
+	}
+	return nil
+}
+
+func (t *fakeTask) Pid() uint32 {
+	return t.pid
+}
+
+func (t *fakeTask) Start(context.Context) error {
+	if t.pid-t.container.pid == uint32(t.container.failStart) {
+		return errors.New("failed to start")
+	}
+	t.container.started++
+	if t.container.exitOn == 0 {
+		t.exit()
+	}
+	return nil
+}
+
+func (t *fakeTask) Delete(context.Context, ...containerd.ProcessDeleteOpts) (*containerd.ExitStatus, error) {
+	return containerd.NewExitStatus(t.exitCode, time.Now(), nil), nil
+}
+
+func (c *fakeContainer) Image(context.Context) (containerd.Image, error) {
+	return c.image, nil
+}
+
+func (c *fakeContainer) Spec(context.Context) (*oci.Spec, error) {
+	return c.spec, nil
+}
+
+// Update applies the options, which update the spec returned by Spec in
+// place.
+func (c *fakeContainer) Update(ctx context.Context, opts ...containerd.UpdateContainerOpts) error {
+	for _, opt := range opts {
+		if err := opt(ctx, nil, &containers.Container{ID: "fake"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fakeImage struct {
+	containerd.Image
+	name   string
+	digest digest.Digest
+}
+
+func (i *fakeImage) Name() string {
+	return i.name
+}
+
+func (i *fakeImage) Target() v1.Descriptor {
+	return v1.Descriptor{Digest: i.digest}
+}
+
+func (i *fakeImage) Config(context.Context) (v1.Descriptor, error) {
+	return v1.Descriptor{}, errors.New("no image config")
+}
+
+func newFakeContainer(imageRef string, args ...string) *fakeContainer {
+	return &fakeContainer{
+		image: &fakeImage{name: imageRef, digest: digest.FromString(imageRef)},
+		spec:  &oci.Spec{Process: &specs.Process{Args: args}},
+	}
+}
+
+func TestMeasureContainerClaimsWithSidecars(t *testing.T) {
+	var events []cel.CosTlv
+	runner := ContainerRunner{
+		container: newFakeContainer("docker.io/library/workload:latest", "/workload"),
+		launchSpec: spec.LaunchSpec{
+			RestartPolicy:     spec.Always,
+			HostNetwork:       true,
+			MemoryLimitMB:     512,
+			AddedCapabilities: []string{"CAP_NET_ADMIN"},
+		},
+		sidecars: []*sidecar{
+			{spec: spec.Sidecar{Name: "envoy", Cmd: []string{"-c", "/etc/envoy.yaml"}}, container: newFakeContainer("docker.io/envoyproxy/envoy:v1.27.0", "envoy", "-c", "/etc/envoy.yaml")},
+			{spec: spec.Sidecar{Name: "log-agent"}, container: newFakeContainer("docker.io/fluent/fluent-bit:2.1", "fluent-bit")},
+		},
+		attestAgent: &fakeAttestationAgent{
+			measureEventFunc: func(content cel.Content) error {
+				events = append(events, content.(cel.CosTlv))
+				return nil
+			},
+		},
+		logger: log.Default(),
+	}
+	if err := runner.measureContainerClaims(context.Background()); err != nil {
+		t.Fatalf("measureContainerClaims() failed: %v", err)
+	}
+
+	want := []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/library/workload:latest")},
+		{EventType: cel.ImageDigestType, EventContent: []byte(digest.FromString("docker.io/library/workload:latest"))},
+		{EventType: cel.RestartPolicyType, EventContent: []byte("Always")},
+		{EventType: cel.ArgType, EventContent: []byte("/workload")},
+		{EventType: cel.CapabilityType, EventContent: []byte("CAP_NET_ADMIN")},
+		{EventType: cel.HostNetworkType, EventContent: []byte("true")},
+		{EventType: cel.MemoryLimitType, EventContent: []byte("512")},
+		{EventType: cel.ContainerIndexType, EventContent: []byte("1")},
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/envoyproxy/envoy:v1.27.0")},
+		{EventType: cel.ImageDigestType, EventContent: []byte(digest.FromString("docker.io/envoyproxy/envoy:v1.27.0"))},
+		{EventType: cel.RestartPolicyType, EventContent: []byte("Never")},
+		{EventType: cel.ArgType, EventContent: []byte("envoy")},
+		{EventType: cel.ArgType, EventContent: []byte("-c")},
+		{EventType: cel.ArgType, EventContent: []byte("/etc/envoy.yaml")},
+		{EventType: cel.OverrideArgType, EventContent: []byte("-c")},
+		{EventType: cel.OverrideArgType, EventContent: []byte("/etc/envoy.yaml")},
+		{EventType: cel.CapabilityType, EventContent: []byte("CAP_NET_ADMIN")},
+		{EventType: cel.HostNetworkType, EventContent: []byte("true")},
+		{EventType: cel.MemoryLimitType, EventContent: []byte("512")},
+		{EventType: cel.ContainerIndexType, EventContent: []byte("2")},
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/fluent/fluent-bit:2.1")},
+		{EventType: cel.ImageDigestType, EventContent: []byte(digest.FromString("docker.io/fluent/fluent-bit:2.1"))},
+		{EventType: cel.RestartPolicyType, EventContent: []byte("Never")},
+		{EventType: cel.ArgType, EventContent: []byte("fluent-bit")},
+		{EventType: cel.CapabilityType, EventContent: []byte("CAP_NET_ADMIN")},
+		{EventType: cel.HostNetworkType, EventContent: []byte("true")},
+		{EventType: cel.MemoryLimitType, EventContent: []byte("512")},
+		{EventType: cel.LaunchSeparatorType},
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("measureContainerClaims() measured unexpected events (-want +got):\n%s", diff)
+	}
+}
+
+func TestHasGceAKTemplate(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	hasTemplate, err := hasGceAKTemplate(tpm)
+	if err != nil {
+		t.Fatalf("hasGceAKTemplate() failed: %v", err)
+	}
+	if hasTemplate {
+		t.Error("hasGceAKTemplate() = true before the index was defined")
+	}
+
+	idx := tpmutil.Handle(client.GceAKTemplateNVIndexECC)
+	attrs := tpm2.AttrOwnerWrite | tpm2.AttrOwnerRead | tpm2.AttrNoDA
+	if err := tpm2.NVDefineSpace(tpm, tpm2.HandleOwner, idx, "", "", nil, attrs, 8); err != nil {
+		t.Fatal(err)
+	}
+	defer tpm2.NVUndefineSpace(tpm, "", tpm2.HandleOwner, idx)
+	hasTemplate, err = hasGceAKTemplate(tpm)
+	if err != nil {
+		t.Fatalf("hasGceAKTemplate() failed: %v", err)
+	}
+	if !hasTemplate {
+		t.Error("hasGceAKTemplate() = false after the index was defined")
+	}
+}
+
+func TestMeasureContainerClaimsAfterRestart(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	celPath := path.Join(t.TempDir(), "cel")
+	newRunner := func(imageRef string) *ContainerRunner {
+		t.Helper()
+		attestAgent, err := agent.CreatePersistentAttestationAgent(tpm, client.AttestationKeyECC, nil, nil, celPath)
+		if err != nil {
+			t.Fatalf("failed to create agent: %v", err)
+		}
+		return &ContainerRunner{
+			container:   newFakeContainer(imageRef, "/workload"),
+			launchSpec:  spec.LaunchSpec{RestartPolicy: spec.Never},
+			attestAgent: attestAgent,
+			logger:      log.Default(),
+		}
+	}
+
+	if err := newRunner("docker.io/library/workload:latest").measureContainerClaims(context.Background()); err != nil {
+		t.Fatalf("measureContainerClaims() failed: %v", err)
+	}
+	// The restarted launcher finds the claims in the persisted eventlog.
+	restarted := newRunner("docker.io/library/workload:latest")
+	if err := restarted.measureContainerClaims(context.Background()); err != nil {
+		t.Fatalf("measureContainerClaims() after a restart failed: %v", err)
+	}
+	if err := newRunner("docker.io/library/other:latest").measureContainerClaims(context.Background()); err == nil {
+		t.Error("measureContainerClaims() of another workload after a restart succeeded")
+	}
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	nonce := []byte("super secret nonce")
+	attestation, err := restarted.attestAgent.AttestationEvidence(nonce)
+	if err != nil {
+		t.Fatalf("AttestationEvidence() failed: %v", err)
+	}
+	state, err := server.VerifyAttestation(attestation, server.VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}})
+	if err != nil {
+		t.Fatalf("VerifyAttestation() of the eventlog measured across a restart failed: %v", err)
+	}
+	if got := state.GetCos().GetContainer().GetImageReference(); got != "docker.io/library/workload:latest" {
+		t.Errorf("got image reference %q, want %q", got, "docker.io/library/workload:latest")
+	}
+}
+
+func TestRunWorkloadAfterLauncherRestart(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	celPath := path.Join(t.TempDir(), "cel")
+	run := func(exitCodes ...uint32) *ContainerRunner {
+		t.Helper()
+		attestAgent, err := agent.CreatePersistentAttestationAgent(tpm, client.AttestationKeyECC, nil, nil, celPath)
+		if err != nil {
+			t.Fatalf("failed to create agent: %v", err)
+		}
+		container := newFakeContainer("docker.io/library/workload:latest", "/workload")
+		container.exitCodes = exitCodes
+		runner := &ContainerRunner{
+			container:   container,
+			launchSpec:  spec.LaunchSpec{RestartPolicy: spec.OnFailure, HostNetwork: true},
+			attestAgent: attestAgent,
+			logger:      log.Default(),
+		}
+		if err := runner.measureContainerClaims(context.Background()); err != nil {
+			t.Fatalf("measureContainerClaims() failed: %v", err)
+		}
+		restartBackoff := backoff.NewExponentialBackOff()
+		restartBackoff.InitialInterval = time.Millisecond
+		if err := runner.runWorkloadWithBackoff(context.Background(), cio.WithStreams(nil, nil, nil), restartBackoff); err != nil {
+			t.Fatalf("runWorkloadWithBackoff() failed: %v", err)
+		}
+		return runner
+	}
+
+	// The workload is restarted once, then relaunched by a restarted
+	// launcher, which measures the relaunch as its second restart.
+	run(1, 0)
+	restarted := run(0)
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	nonce := []byte("super secret nonce")
+	attestation, err := restarted.attestAgent.AttestationEvidence(nonce)
+	if err != nil {
+		t.Fatalf("AttestationEvidence() failed: %v", err)
+	}
+	state, err := server.VerifyAttestation(attestation, server.VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ak.PublicKey()}})
+	if err != nil {
+		t.Fatalf("VerifyAttestation() of the restarts measured across a launcher restart failed: %v", err)
+	}
+	if got := state.GetCos().GetContainer().GetRestartCount(); got != 2 {
+		t.Errorf("got restart count %d, want 2", got)
+	}
+}
+
+func TestRunWorkloadWithBackoff(t *testing.T) {
+	testCases := []struct {
+		name          string
+		restartPolicy spec.RestartPolicy
+		exitCodes     []uint32
+		wantTasks     int
+		wantRestarts  int
+		wantErr       interface{}
+	}{
+		{"NeverSucceeds", spec.Never, []uint32{0}, 1, 0, nil},
+		{"NeverFails", spec.Never, []uint32{1}, 1, 0, &WorkloadError{}},
+		{"OnFailureSucceeds", spec.OnFailure, []uint32{1, 2, 0}, 3, 2, nil},
+		// Tasks failing to start are left to a VM reboot, and their restart is
+		// not measured.
+		{"OnFailureFailsToStart", spec.OnFailure, []uint32{1}, 1, 0, &RetryableError{}},
+		{"Always", spec.Always, []uint32{0, 1, 0}, 3, 2, &RetryableError{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			container := newFakeContainer("docker.io/library/workload:latest")
+			container.exitCodes = tc.exitCodes
+			var restarts [][]byte
+			runner := ContainerRunner{
+				container:  container,
+				launchSpec: spec.LaunchSpec{RestartPolicy: tc.restartPolicy},
+				attestAgent: &fakeAttestationAgent{
+					measureEventFunc: func(content cel.Content) error {
+						event := content.(cel.CosTlv)
+						if event.EventType != cel.RestartCountType {
+							return fmt.Errorf("measured unexpected event %v", event.EventType)
+						}
+						restarts = append(restarts, event.EventContent)
+						return nil
+					},
+				},
+				logger: log.Default(),
+			}
+			restartBackoff := backoff.NewExponentialBackOff()
+			restartBackoff.InitialInterval = time.Millisecond
+
+			err := runner.runWorkloadWithBackoff(context.Background(), cio.WithStreams(nil, nil, nil), restartBackoff)
+			switch tc.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("runWorkloadWithBackoff() failed: %v", err)
+				}
+			case *WorkloadError:
+				var workloadErr *WorkloadError
+				if !errors.As(err, &workloadErr) {
+					t.Errorf("runWorkloadWithBackoff() got error %v, want a WorkloadError", err)
+				}
+			case *RetryableError:
+				var retryableErr *RetryableError
+				if !errors.As(err, &retryableErr) {
+					t.Errorf("runWorkloadWithBackoff() got error %v, want a RetryableError", err)
+				}
+			}
+			if container.tasks != tc.wantTasks {
+				t.Errorf("runWorkloadWithBackoff() ran %d tasks, want %d", container.tasks, tc.wantTasks)
+			}
+			var wantRestarts [][]byte
+			for i := 1; i <= tc.wantRestarts; i++ {
+				wantRestarts = append(wantRestarts, []byte(strconv.Itoa(i)))
+			}
+			if diff := cmp.Diff(wantRestarts, restarts); diff != "" {
+				t.Errorf("runWorkloadWithBackoff() measured unexpected restarts (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunWorkloadRestartMeasurementFailure(t *testing.T) {
+	container := newFakeContainer("docker.io/library/workload:latest")
+	container.exitCodes = []uint32{1, 0}
+	runner := ContainerRunner{
+		container:  container,
+		launchSpec: spec.LaunchSpec{RestartPolicy: spec.OnFailure},
+		attestAgent: &fakeAttestationAgent{
+			measureEventFunc: func(cel.Content) error {
+				return errors.New("failed to measure")
+			},
+		},
+		logger: log.Default(),
+	}
+	restartBackoff := backoff.NewExponentialBackOff()
+	restartBackoff.InitialInterval = time.Millisecond
+
+	err := runner.runWorkloadWithBackoff(context.Background(), cio.WithStreams(nil, nil, nil), restartBackoff)
+	var workloadErr *WorkloadError
+	if err == nil || errors.As(err, &workloadErr) {
+		t.Errorf("runWorkloadWithBackoff() got error %v, want a measurement error", err)
+	}
+	// The restarted task is not started, as its restart is not measured.
+	if container.tasks != 2 || container.started != 1 {
+		t.Errorf("runWorkloadWithBackoff() created %d tasks and started %d, want 2 and 1", container.tasks, container.started)
+	}
+}
+
+func TestRunWorkloadRestartFailsToStart(t *testing.T) {
+	container := newFakeContainer("docker.io/library/workload:latest")
+	container.exitCodes = []uint32{1, 0}
+	container.failStart = 2
+	var events []cel.CosTlv
+	runner := ContainerRunner{
+		container:  container,
+		launchSpec: spec.LaunchSpec{RestartPolicy: spec.OnFailure},
+		attestAgent: &fakeAttestationAgent{
+			measureEventFunc: func(content cel.Content) error {
+				events = append(events, content.(cel.CosTlv))
+				return nil
+			},
+		},
+		logger: log.Default(),
+	}
+	restartBackoff := backoff.NewExponentialBackOff()
+	restartBackoff.InitialInterval = time.Millisecond
+
+	// The restart is measured before the task fails to start, so the VM is
+	// rebooted rather than restarting it again.
+	err := runner.runWorkloadWithBackoff(context.Background(), cio.WithStreams(nil, nil, nil), restartBackoff)
+	var retryableErr *RetryableError
+	if !errors.As(err, &retryableErr) {
+		t.Errorf("runWorkloadWithBackoff() got error %v, want a RetryableError", err)
+	}
+	wantEvents := []cel.CosTlv{{EventType: cel.RestartCountType, EventContent: []byte("1")}}
+	if diff := cmp.Diff(wantEvents, events); diff != "" {
+		t.Errorf("runWorkloadWithBackoff() measured unexpected events (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunWorkloadCanceled(t *testing.T) {
+	container := newFakeContainer("docker.io/library/workload:latest")
+	container.exitCodes = []uint32{1}
+	runner := ContainerRunner{
+		container:   container,
+		launchSpec:  spec.LaunchSpec{RestartPolicy: spec.OnFailure},
+		attestAgent: &fakeAttestationAgent{},
+		logger:      log.Default(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// The restart is canceled while waiting for the backoff.
+	if err := runner.runWorkloadWithBackoff(ctx, cio.WithStreams(nil, nil, nil), defaultRestartPolicy()); !errors.Is(err, context.Canceled) {
+		t.Errorf("runWorkloadWithBackoff() got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestStopWorkload(t *testing.T) {
+	testCases := []struct {
+		name        string
+		exitOn      syscall.Signal
+		wantSignals []syscall.Signal
+	}{
+		{"ExitsOnSignal", syscall.SIGTERM, []syscall.Signal{syscall.SIGTERM}},
+		{"KilledAfterGracePeriod", syscall.SIGKILL, []syscall.Signal{syscall.SIGTERM, syscall.SIGKILL}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			container := newFakeContainer("docker.io/library/workload:latest")
+			container.exitCodes = []uint32{0, 0}
+			container.exitOn = tc.exitOn
+			var events []cel.CosTlv
+			runner := ContainerRunner{
+				container:  container,
+				launchSpec: spec.LaunchSpec{RestartPolicy: spec.Always, StopGracePeriod: 10 * time.Millisecond},
+				attestAgent: &fakeAttestationAgent{
+					measureEventFunc: func(content cel.Content) error {
+						events = append(events, content.(cel.CosTlv))
+						return nil
+					},
+				},
+				stopC:  make(chan syscall.Signal, 1),
+				logger: log.Default(),
+			}
+			runner.Stop(syscall.SIGTERM)
+
+			// The stopped workload is not restarted, despite the restart policy,
+			// and its exit on the signal is not a failure.
+			if err := runner.runWorkloadWithBackoff(context.Background(), cio.WithStreams(nil, nil, nil), defaultRestartPolicy()); err != nil {
+				t.Errorf("runWorkloadWithBackoff() failed: %v", err)
+			}
+			if container.tasks != 1 {
+				t.Errorf("runWorkloadWithBackoff() ran %d tasks, want 1", container.tasks)
+			}
+			if diff := cmp.Diff(tc.wantSignals, container.signals); diff != "" {
+				t.Errorf("runWorkloadWithBackoff() sent unexpected signals (-want +got):\n%s", diff)
+			}
+			wantEvents := []cel.CosTlv{{EventType: cel.TerminationType, EventContent: []byte("SIGTERM")}}
+			if diff := cmp.Diff(wantEvents, events); diff != "" {
+				t.Errorf("runWorkloadWithBackoff() measured unexpected events (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStopSidecars(t *testing.T) {
+	exits := newFakeContainer("docker.io/envoyproxy/envoy:v1.27.0")
+	exits.exitCodes = []uint32{0}
+	exits.exitOn = syscall.SIGINT
+	hangs := newFakeContainer("docker.io/fluent/fluent-bit:2.1")
+	hangs.exitCodes = []uint32{0}
+	hangs.exitOn = syscall.SIGKILL
+	runner := ContainerRunner{
+		launchSpec: spec.LaunchSpec{StopGracePeriod: 10 * time.Millisecond},
+		sidecars: []*sidecar{
+			{spec: spec.Sidecar{Name: "envoy"}, container: exits},
+			{spec: spec.Sidecar{Name: "log-agent"}, container: hangs},
+		},
+		attestAgent: &fakeAttestationAgent{measureEventFunc: func(cel.Content) error { return nil }},
+		logger:      log.Default(),
+	}
+	ctx := context.Background()
+	if err := runner.startSidecars(ctx, cio.WithStreams(nil, nil, nil), 1); err != nil {
+		t.Fatalf("startSidecars() failed: %v", err)
+	}
+	runner.terminate(syscall.SIGINT)
+	runner.stopSidecars(ctx)
+
+	// The stop signal of the workload is forwarded to the sidecars, which are
+	// killed after the grace period.
+	if diff := cmp.Diff([]syscall.Signal{syscall.SIGINT}, exits.signals); diff != "" {
+		t.Errorf("stopSidecars() sent unexpected signals to the exiting sidecar (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]syscall.Signal{syscall.SIGINT, syscall.SIGKILL}, hangs.signals); diff != "" {
+		t.Errorf("stopSidecars() sent unexpected signals to the hanging sidecar (-want +got):\n%s", diff)
+	}
+	for _, s := range runner.sidecars {
+		if s.task != nil {
+			t.Errorf("stopSidecars() did not delete the sidecar %s task", s.spec.Name)
+		}
+	}
+}
+
+func TestSidecarsShareWorkloadNetwork(t *testing.T) {
+	workload := newFakeContainer("docker.io/library/workload:latest")
+	workload.exitCodes = []uint32{1, 0}
+	workload.pid = 100
+	envoy := newFakeContainer("docker.io/envoyproxy/envoy:v1.27.0")
+	envoy.exitCodes = []uint32{0}
+	envoy.exitOn = syscall.SIGTERM
+	envoy.pid = 200
+	runner := ContainerRunner{
+		container:   workload,
+		launchSpec:  spec.LaunchSpec{RestartPolicy: spec.OnFailure, StopGracePeriod: time.Second},
+		sidecars:    []*sidecar{{spec: spec.Sidecar{Name: "envoy"}, container: envoy}},
+		attestAgent: &fakeAttestationAgent{measureEventFunc: func(cel.Content) error { return nil }},
+		logger:      log.Default(),
+	}
+	restartBackoff := backoff.NewExponentialBackOff()
+	restartBackoff.InitialInterval = time.Millisecond
+	ctx := context.Background()
+	defer runner.stopSidecars(ctx)
+	if err := runner.runWorkloadWithBackoff(ctx, cio.WithStreams(nil, nil, nil), restartBackoff); err != nil {
+		t.Fatalf("runWorkloadWithBackoff() failed: %v", err)
+	}
+
+	networkNamespace := func(s *oci.Spec) string {
+		for _, ns := range s.Linux.Namespaces {
+			if ns.Type == specs.NetworkNamespace {
+				return ns.Path
+			}
+		}
+		return "none"
+	}
+	// The sidecar joins the network namespace of the first workload task,
+	// and the restarted workload task joins the network namespace of the
+	// sidecar.
+	if got, want := networkNamespace(envoy.spec), "/proc/101/ns/net"; got != want {
+		t.Errorf("sidecar got network namespace %q, want %q", got, want)
+	}
+	if got, want := networkNamespace(workload.spec), "/proc/201/ns/net"; got != want {
+		t.Errorf("restarted workload got network namespace %q, want %q", got, want)
+	}
+	if envoy.tasks != 1 {
+		t.Errorf("runWorkloadWithBackoff() ran %d sidecar tasks, want 1", envoy.tasks)
+	}
+}
+
+func TestResourceSpecOpts(t *testing.T) {
+	ctx := namespaces.WithNamespace(context.Background(), "test")
+	generate := func(launchSpec spec.LaunchSpec) *oci.Spec {
+		t.Helper()
+		s, err := oci.GenerateSpec(ctx, nil, &containers.Container{ID: "test"}, resourceSpecOpts(launchSpec)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	hasNetworkNamespace := func(s *oci.Spec) bool {
+		for _, ns := range s.Linux.Namespaces {
+			if ns.Type == specs.NetworkNamespace {
+				return true
+			}
+		}
+		return false
+	}
+
+	defaults := generate(spec.LaunchSpec{})
+	if !hasNetworkNamespace(defaults) {
+		t.Error("container without the host network has no network namespace")
+	}
+	if defaults.Linux.Resources.Memory != nil {
+		t.Errorf("container without a memory limit got memory resources %+v", defaults.Linux.Resources.Memory)
+	}
+
+	s := generate(spec.LaunchSpec{
+		HostNetwork:       true,
+		MemoryLimitMB:     512,
+		AddedCapabilities: []string{"CAP_NET_ADMIN"},
+	})
+	if hasNetworkNamespace(s) {
+		t.Error("container with the host network has a network namespace")
+	}
+	if s.Linux.Resources.Memory == nil || s.Linux.Resources.Memory.Limit == nil || *s.Linux.Resources.Memory.Limit != 512*1024*1024 {
+		t.Errorf("got memory resources %+v, want a limit of 512 MiB", s.Linux.Resources.Memory)
+	}
+	if !contains(s.Process.Capabilities.Effective, "CAP_NET_ADMIN") || contains(defaults.Process.Capabilities.Effective, "CAP_NET_ADMIN") {
+		t.Errorf("got effective capabilities %v, want CAP_NET_ADMIN added", s.Process.Capabilities.Effective)
+	}
+}
+
+func TestSidecarSpecOpts(t *testing.T) {
+	ctx := namespaces.WithNamespace(context.Background(), "test")
+	launchSpec := spec.LaunchSpec{
+		HostNetwork:       true,
+		MemoryLimitMB:     512,
+		AddedCapabilities: []string{"CAP_NET_ADMIN"},
+	}
+	s, err := oci.GenerateSpec(ctx, nil, &containers.Container{ID: "test"}, sidecarSpecOpts(launchSpec, []string{"FOO=bar"}, "test-host")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range s.Mounts {
+		if isUnderPath(hostTokenPath, m.Source) || m.Destination == containerTokenMountPath {
+			t.Errorf("sidecar got the token directory mount %+v", m)
+		}
+	}
+	if !contains(s.Process.Env, "FOO=bar") || !contains(s.Process.Env, "HOSTNAME=test-host") {
+		t.Errorf("got env %v, want FOO=bar and HOSTNAME=test-host", s.Process.Env)
+	}
+	if s.Linux.Resources.Memory == nil || s.Linux.Resources.Memory.Limit == nil || *s.Linux.Resources.Memory.Limit != 512*1024*1024 {
+		t.Errorf("got memory resources %+v, want a limit of 512 MiB", s.Linux.Resources.Memory)
+	}
+	if !contains(s.Process.Capabilities.Effective, "CAP_NET_ADMIN") {
+		t.Errorf("got effective capabilities %v, want CAP_NET_ADMIN added", s.Process.Capabilities.Effective)
+	}
+}
+
+// isUnderPath returns whether the path is in the directory.
+func isUnderPath(dir string, p string) bool {
+	return strings.HasPrefix(path.Clean(p)+"/", path.Clean(dir)+"/")
+}
+
+func contains(strs []string, target string) bool {
+	for _, s := range strs {
+		if s == target {
+			return true
+		}
+	}
+	return false
+}
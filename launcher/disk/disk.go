@@ -0,0 +1,77 @@
+// Package disk unlocks LUKS encrypted data disks for the workload, with a key
+// released to the attested workload by a key release service, or unsealed
+// from the TPM.
+package disk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+)
+
+// KeySource returns the key of an encrypted disk.
+type KeySource interface {
+	Key(ctx context.Context) ([]byte, error)
+}
+
+// runCommand runs the command, with the input on its stdin. Tests replace it
+// to run without cryptsetup.
+var runCommand = func(ctx context.Context, input []byte, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %v: %s", name, err, out)
+	}
+	return nil
+}
+
+// Volume is an unlocked encrypted disk, mounted on the host.
+type Volume struct {
+	name       string
+	mountPoint string
+}
+
+// Open unlocks the LUKS device with the key as /dev/mapper/<name>, and mounts
+// its filesystem at the mount point. The key is passed to cryptsetup on its
+// stdin, so it never appears in a command line.
+func Open(ctx context.Context, device string, name string, mountPoint string, key []byte, readOnly bool) (*Volume, error) {
+	openArgs := []string{"open", "--type", "luks", "--key-file", "-"}
+	mountArgs := []string{}
+	if readOnly {
+		openArgs = append(openArgs, "--readonly")
+		mountArgs = append(mountArgs, "-o", "ro")
+	}
+	if err := runCommand(ctx, key, "cryptsetup", append(openArgs, device, name)...); err != nil {
+		return nil, fmt.Errorf("failed to unlock %s: %v", device, err)
+	}
+	v := &Volume{name: name}
+
+	if err := os.MkdirAll(mountPoint, 0700); err != nil {
+		v.Close(ctx)
+		return nil, err
+	}
+	if err := runCommand(ctx, nil, "mount", append(mountArgs, v.device(), mountPoint)...); err != nil {
+		v.Close(ctx)
+		return nil, fmt.Errorf("failed to mount %s: %v", device, err)
+	}
+	v.mountPoint = mountPoint
+	return v, nil
+}
+
+func (v *Volume) device() string {
+	return path.Join("/dev/mapper", v.name)
+}
+
+// Close unmounts the filesystem, and locks the device again.
+func (v *Volume) Close(ctx context.Context) error {
+	if v.mountPoint != "" {
+		if err := runCommand(ctx, nil, "umount", v.mountPoint); err != nil {
+			return err
+		}
+		v.mountPoint = ""
+	}
+	return runCommand(ctx, nil, "cryptsetup", "close", v.name)
+}
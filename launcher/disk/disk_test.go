@@ -0,0 +1,73 @@
+package disk
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type command struct {
+	input string
+	args  string
+}
+
+// fakeCommands replaces runCommand to record the commands, and fail the
+// command named failing.
+func fakeCommands(t *testing.T, failing string) *[]command {
+	t.Helper()
+	var commands []command
+	orig := runCommand
+	runCommand = func(_ context.Context, input []byte, name string, args ...string) error {
+		commands = append(commands, command{string(input), strings.Join(append([]string{name}, args...), " ")})
+		if name == failing {
+			return errors.New("failed")
+		}
+		return nil
+	}
+	t.Cleanup(func() { runCommand = orig })
+	return &commands
+}
+
+func TestOpenClose(t *testing.T) {
+	commands := fakeCommands(t, "")
+	mountPoint := path.Join(t.TempDir(), "disk")
+
+	v, err := Open(context.Background(), "/dev/sdb", "tee-disk-0", mountPoint, []byte("key"), true)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if err := v.Close(context.Background()); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	want := []command{
+		{"key", "cryptsetup open --type luks --key-file - --readonly /dev/sdb tee-disk-0"},
+		{"", "mount -o ro /dev/mapper/tee-disk-0 " + mountPoint},
+		{"", "umount " + mountPoint},
+		{"", "cryptsetup close tee-disk-0"},
+	}
+	if diff := cmp.Diff(want, *commands, cmp.AllowUnexported(command{})); diff != "" {
+		t.Errorf("unexpected commands (-want +got):\n%s", diff)
+	}
+}
+
+func TestOpenMountFailure(t *testing.T) {
+	commands := fakeCommands(t, "mount")
+	mountPoint := path.Join(t.TempDir(), "disk")
+
+	if _, err := Open(context.Background(), "/dev/sdb", "tee-disk-0", mountPoint, []byte("key"), false); err == nil {
+		t.Fatal("Open() succeeded")
+	}
+	// The device must be locked again.
+	want := []command{
+		{"key", "cryptsetup open --type luks --key-file - /dev/sdb tee-disk-0"},
+		{"", "mount /dev/mapper/tee-disk-0 " + mountPoint},
+		{"", "cryptsetup close tee-disk-0"},
+	}
+	if diff := cmp.Diff(want, *commands, cmp.AllowUnexported(command{})); diff != "" {
+		t.Errorf("unexpected commands (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,109 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/go-tpm-tools/server/keyrelease"
+)
+
+// maxResponseSize limits the size of the key release service responses.
+const maxResponseSize = 1 << 20
+
+// TokenFetcher returns an attestation token with the audience.
+type TokenFetcher func(ctx context.Context, audience string) ([]byte, error)
+
+// ReleaseRequest is the JSON request to a key release service, sent with the
+// attestation token of the workload as a bearer token.
+type ReleaseRequest struct {
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+// ReleaseResponse is the JSON response of a key release service.
+type ReleaseResponse struct {
+	Key []byte `json:"key"`
+}
+
+type keyReleaseSource struct {
+	endpoint   string
+	wrappedKey []byte
+	fetchToken TokenFetcher
+	client     *http.Client
+}
+
+// NewKeyReleaseSource returns a KeySource unwrapping the wrapped key with the
+// key release service at the endpoint, which releases it only to attested
+// workloads. The service is authenticated to with an attestation token whose
+// audience is the endpoint. If client is nil, http.DefaultClient is used.
+func NewKeyReleaseSource(endpoint string, wrappedKey []byte, fetchToken TokenFetcher, client *http.Client) KeySource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &keyReleaseSource{endpoint, wrappedKey, fetchToken, client}
+}
+
+func (s *keyReleaseSource) Key(ctx context.Context) ([]byte, error) {
+	token, err := s.fetchToken(ctx, s.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get an attestation token for the key release service: %v", err)
+	}
+	body, err := json.Marshal(ReleaseRequest{WrappedKey: s.wrappedKey})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+string(token))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call the key release service: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the key release service response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("key release service refused to release the key: %s: %s", resp.Status, data)
+	}
+	var release ReleaseResponse
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("malformed key release service response: %v", err)
+	}
+	if len(release.Key) == 0 {
+		return nil, fmt.Errorf("key release service returned an empty key")
+	}
+	return release.Key, nil
+}
+
+type attestedReleaseSource struct {
+	client   *keyrelease.Client
+	secret   string
+	attester keyrelease.Attester
+}
+
+// NewAttestedReleaseSource returns a KeySource releasing the named secret
+// from a keyrelease.Server, which wraps it to the EK of the TPM attested by
+// the attester.
+func NewAttestedReleaseSource(client *keyrelease.Client, secret string, attester keyrelease.Attester) KeySource {
+	return &attestedReleaseSource{client, secret, attester}
+}
+
+func (s *attestedReleaseSource) Key(ctx context.Context) ([]byte, error) {
+	key, err := s.client.ReleaseSecret(ctx, s.secret, s.attester)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("key release service released an empty key")
+	}
+	return key, nil
+}
@@ -0,0 +1,205 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm-tools/server/keyrelease"
+)
+
+const fakeToken = "attestation-token"
+
+// fakeKMS is a key release service unwrapping keys wrapped with AES-GCM, for
+// requests with the fakeToken.
+type fakeKMS struct {
+	aead cipher.AEAD
+}
+
+func newFakeKMS(t *testing.T) *fakeKMS {
+	t.Helper()
+	kek := make([]byte, 32)
+	if _, err := rand.Read(kek); err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fakeKMS{aead}
+}
+
+func (k *fakeKMS) wrap(t *testing.T, key []byte) []byte {
+	t.Helper()
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		t.Fatal(err)
+	}
+	return k.aead.Seal(nonce, nonce, key, nil)
+}
+
+func (k *fakeKMS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+fakeToken {
+		http.Error(w, "workload is not attested", http.StatusForbidden)
+		return
+	}
+	var req ReleaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	nonceSize := k.aead.NonceSize()
+	if len(req.WrappedKey) < nonceSize {
+		http.Error(w, "malformed wrapped key", http.StatusBadRequest)
+		return
+	}
+	key, err := k.aead.Open(nil, req.WrappedKey[:nonceSize], req.WrappedKey[nonceSize:], nil)
+	if err != nil {
+		http.Error(w, "failed to unwrap key", http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(ReleaseResponse{Key: key})
+}
+
+func TestKeyReleaseSource(t *testing.T) {
+	kms := newFakeKMS(t)
+	server := httptest.NewServer(kms)
+	defer server.Close()
+
+	diskKey := []byte("disk encryption key")
+	wrappedKey := kms.wrap(t, diskKey)
+	var gotAudience string
+	fetchToken := func(_ context.Context, audience string) ([]byte, error) {
+		gotAudience = audience
+		return []byte(fakeToken), nil
+	}
+
+	key, err := NewKeyReleaseSource(server.URL, wrappedKey, fetchToken, nil).Key(context.Background())
+	if err != nil {
+		t.Fatalf("Key() failed: %v", err)
+	}
+	if !bytes.Equal(key, diskKey) {
+		t.Errorf("Key() got %q, want %q", key, diskKey)
+	}
+	if gotAudience != server.URL {
+		t.Errorf("got token audience %q, want the endpoint %q", gotAudience, server.URL)
+	}
+}
+
+func TestKeyReleaseSourceRefused(t *testing.T) {
+	kms := newFakeKMS(t)
+	server := httptest.NewServer(kms)
+	defer server.Close()
+	wrappedKey := kms.wrap(t, []byte("disk encryption key"))
+	tamperedKey := kms.wrap(t, []byte("disk encryption key"))
+	tamperedKey[len(tamperedKey)-1] ^= 1
+
+	tokenFetcher := func(token string, err error) TokenFetcher {
+		return func(context.Context, string) ([]byte, error) {
+			return []byte(token), err
+		}
+	}
+	testCases := []struct {
+		name       string
+		endpoint   string
+		wrappedKey []byte
+		fetchToken TokenFetcher
+		wantErr    string
+	}{
+		{"NotAttested", server.URL, wrappedKey, tokenFetcher("", errors.New("no token")), "attestation token"},
+		{"WrongToken", server.URL, wrappedKey, tokenFetcher("other-token", nil), "not attested"},
+		{"TamperedKey", server.URL, tamperedKey, tokenFetcher(fakeToken, nil), "failed to unwrap"},
+		{"Unreachable", "http://127.0.0.1:0", wrappedKey, tokenFetcher(fakeToken, nil), "failed to call"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewKeyReleaseSource(tc.endpoint, tc.wrappedKey, tc.fetchToken, nil).Key(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Key() got error %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyReleaseSourceMalformedResponse(t *testing.T) {
+	for name, body := range map[string]string{
+		"NotJSON":  "key",
+		"EmptyKey": "{}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+			fetchToken := func(context.Context, string) ([]byte, error) {
+				return []byte(fakeToken), nil
+			}
+			if _, err := NewKeyReleaseSource(server.URL, []byte("wrapped"), fetchToken, nil).Key(context.Background()); err == nil {
+				t.Error("Key() succeeded")
+			}
+		})
+	}
+}
+
+func TestAttestedReleaseSource(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	ek, err := client.EndorsementKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+
+	ca := test.NewCA(t)
+	if err := ak.SetCert(ca.IssueGCECert(t, ak.PublicKey(), 1, x509.KeyUsageDigitalSignature)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ek.SetCert(ca.IssueGCECert(t, ek.PublicKey(), 1, x509.KeyUsageKeyAgreement)); err != nil {
+		t.Fatal(err)
+	}
+
+	diskKey := []byte("disk encryption key")
+	s, err := keyrelease.NewServer(keyrelease.Config{
+		Secrets:    map[string]keyrelease.Secret{"disk-key": {Value: diskKey}},
+		VerifyOpts: server.VerifyOpts{TrustedRootCerts: []*x509.Certificate{ca.Cert}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := keyrelease.NewClient(ts.URL, ts.Client())
+	attester := keyrelease.NewKeyAttester(ak, ek, client.AttestOpts{})
+
+	key, err := NewAttestedReleaseSource(c, "disk-key", attester).Key(context.Background())
+	if err != nil {
+		t.Fatalf("Key() failed: %v", err)
+	}
+	if !bytes.Equal(key, diskKey) {
+		t.Errorf("Key() got %q, want %q", key, diskKey)
+	}
+	if _, err := NewAttestedReleaseSource(c, "other-key", attester).Key(context.Background()); err == nil {
+		t.Error("Key() of an unknown secret succeeded")
+	}
+}
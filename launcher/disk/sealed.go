@@ -0,0 +1,47 @@
+package disk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-tpm-tools/cel"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"google.golang.org/protobuf/proto"
+)
+
+// Unsealer unseals data sealed to the TPM by client.Key.Seal. The launcher's
+// attestation agent is the Unsealer, so unsealing is serialized with the
+// other uses of the TPM.
+type Unsealer interface {
+	Unseal(*pb.SealedBytes) ([]byte, error)
+}
+
+type sealedSource struct {
+	unsealer Unsealer
+	sealed   *pb.SealedBytes
+}
+
+// NewSealedSource returns a KeySource unsealing the sealed key (a serialized
+// tpm.SealedBytes) with the unsealer. The key must be sealed to
+// cel.CosEventPCR, so it is only unsealed once the launcher measured the
+// expected workload.
+func NewSealedSource(unsealer Unsealer, sealedKey []byte) (KeySource, error) {
+	sealed := &pb.SealedBytes{}
+	if err := proto.Unmarshal(sealedKey, sealed); err != nil {
+		return nil, fmt.Errorf("malformed sealed key: %v", err)
+	}
+	for _, pcr := range sealed.GetPcrs() {
+		if pcr == cel.CosEventPCR {
+			return &sealedSource{unsealer, sealed}, nil
+		}
+	}
+	return nil, fmt.Errorf("sealed key is not sealed to PCR %d (got PCRs %v)", cel.CosEventPCR, sealed.GetPcrs())
+}
+
+func (s *sealedSource) Key(context.Context) ([]byte, error) {
+	key, err := s.unsealer.Unseal(s.sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unseal the key: %v", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,76 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"testing"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"google.golang.org/protobuf/proto"
+)
+
+func sealKey(t *testing.T, tpm io.ReadWriter, key []byte, pcrs ...int) []byte {
+	t.Helper()
+	srk, err := client.StorageRootKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srk.Close()
+	sealed, err := srk.Seal(key, client.SealOpts{Current: tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: pcrs}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := proto.Marshal(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSealedSource(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	diskKey := []byte("disk encryption key")
+	unsealer := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, nil, nil)
+	source, err := NewSealedSource(unsealer, sealKey(t, tpm, diskKey, cel.CosEventPCR))
+	if err != nil {
+		t.Fatalf("NewSealedSource() failed: %v", err)
+	}
+	key, err := source.Key(context.Background())
+	if err != nil {
+		t.Fatalf("Key() failed: %v", err)
+	}
+	if !bytes.Equal(key, diskKey) {
+		t.Errorf("Key() got %q, want %q", key, diskKey)
+	}
+
+	// Measuring another workload changes PCR 13, so the key is not unsealed.
+	digest := sha256.Sum256([]byte("other workload"))
+	if err := tpm2.PCRExtend(tpm, tpmutil.Handle(cel.CosEventPCR), tpm2.AlgSHA256, digest[:], ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.Key(context.Background()); err == nil {
+		t.Error("Key() succeeded after PCR 13 changed")
+	}
+}
+
+func TestNewSealedSourceRejected(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	unsealer := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, nil, nil)
+	if _, err := NewSealedSource(unsealer, sealKey(t, tpm, []byte("key"), test.DebugPCR)); err == nil {
+		t.Error("NewSealedSource() with a key not sealed to PCR 13 succeeded")
+	}
+	if _, err := NewSealedSource(unsealer, []byte("sealed key")); err == nil {
+		t.Error("NewSealedSource() with a malformed key succeeded")
+	}
+}
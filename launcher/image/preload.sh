@@ -7,6 +7,13 @@ copy_launcher() {
   cp launcher "${CS_PATH}/cs_container_launcher"
 }
 
+copy_launch_spec_signing_keys() {
+  # Pin the keys the launch spec must be signed by, if any are provided.
+  if [[ -f launch_spec_signing_keys.pem ]]; then
+    cp launch_spec_signing_keys.pem "${CS_PATH}/launch_spec_signing_keys.pem"
+  fi
+}
+
 setup_launcher_systemd_unit() {
   cp container-runner.service "${CS_PATH}/container-runner.service"
   cp exit_script.sh "${CS_PATH}/exit_script.sh"
@@ -82,6 +89,7 @@ main() {
   configure_entrypoint "entrypoint.sh"
   # Install container launcher.
   copy_launcher
+  copy_launch_spec_signing_keys
   setup_launcher_systemd_unit
   append_cmdline "cos.protected_stateful_partition=e"
   # Increase wait timeout of the protected stateful partition.
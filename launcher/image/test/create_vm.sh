@@ -7,6 +7,7 @@ print_usage() {
     echo "  -p <imageProject>: which image project to use for the VM"
     echo "  -m <metadata>: metadata variables on VM creation; passed directly into gcloud"
     echo "  -f <metadataFromFile>: read a metadata value from a file; specified in format key=filePath"
+    echo "  -k <imageSigningKeysFile>: optional PEM file set as the tee-image-signing-keys metadata"
     echo "  -n <instanceName>: instance name"
     echo "  -z <instanceZone>: instance zone"
     exit 1
@@ -23,6 +24,10 @@ create_vm() {
     APPEND_METADATA="--metadata ${METADATA}"
   fi
 
+  if ! [ -z "$IMAGE_SIGNING_KEYS_FILE" ]; then
+    METADATA_FILE="${METADATA_FILE:+${METADATA_FILE},}tee-image-signing-keys=${IMAGE_SIGNING_KEYS_FILE}"
+  fi
+
   APPEND_METADATA_FILE=''
   if ! [ -z "$METADATA_FILE" ]; then
     APPEND_METADATA_FILE="--metadata-from-file ${METADATA_FILE}"
@@ -39,6 +44,7 @@ create_vm() {
 }
 
 IMAGE_NAME=''
+IMAGE_SIGNING_KEYS_FILE=''
 METADATA_FILE=''
 METADATA=''
 PROJECT_NAME=''
@@ -47,10 +53,11 @@ ZONE=''
 
 # In getopts, a ':' following a letter means that that flag takes an argument.
 # For example, i: means -i takes an additional argument.
-while getopts 'i:f:m:p:n:z:' flag; do
+while getopts 'i:f:k:m:p:n:z:' flag; do
   case "${flag}" in
     i) IMAGE_NAME=${OPTARG} ;;
     f) METADATA_FILE=${OPTARG} ;;
+    k) IMAGE_SIGNING_KEYS_FILE=${OPTARG} ;;
     m) METADATA=${OPTARG} ;;
     p) PROJECT_NAME=${OPTARG} ;;
     n) VM_NAME=${OPTARG} ;;
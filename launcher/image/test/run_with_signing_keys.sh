@@ -0,0 +1,12 @@
+#!/bin/bash
+# run_with_signing_keys.sh <IMAGE_SIGNING_KEYS_FILE> <SCRIPT> [ARGS...]
+# Runs the script of a signed workload test step, or skips it when no image
+# signing keys file is set.
+set -euo pipefail
+
+if [ -z "$1" ]; then
+  echo "No _IMAGE_SIGNING_KEYS_FILE set, skipping $2."
+  exit 0
+fi
+shift
+exec bash "$@"
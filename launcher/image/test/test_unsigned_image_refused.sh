@@ -0,0 +1,30 @@
+#!/bin/bash
+set -euo pipefail
+source util/read_serial.sh
+
+# This test requires the hardened launcher to refuse the workload image, as
+# the VM has no tee-image-signing-keys to verify its signature with.
+SERIAL_OUTPUT=$(read_serial $1 $2)
+print_serial=false
+
+if echo $SERIAL_OUTPUT | grep -q 'refusing to run an unverified image in hardened mode'
+then
+    echo "- unsigned image refused"
+else
+    echo "FAILED: unsigned image not refused"
+    echo 'TEST FAILED.' > /workspace/status.txt
+    print_serial=true
+fi
+
+if echo $SERIAL_OUTPUT | grep -q 'workload running'
+then
+    echo "FAILED: unsigned workload running"
+    echo 'TEST FAILED.' > /workspace/status.txt
+    print_serial=true
+else
+    echo "- unsigned workload not running"
+fi
+
+if $print_serial; then
+    echo $SERIAL_OUTPUT
+fi
@@ -0,0 +1,123 @@
+// Package imagesig verifies cosign-style container image signatures: simple
+// signing payloads naming the image manifest digest, signed by a key and
+// stored in the registry next to the image.
+package imagesig
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// signatureType is the critical type of cosign simple signing payloads.
+const signatureType = "cosign container image signature"
+
+// Signature is a signature on an image.
+type Signature struct {
+	// Payload is the signed simple signing payload.
+	Payload []byte
+	// Signature is the raw signature on the Payload.
+	Signature []byte
+}
+
+// Fetcher fetches the signatures of an image.
+type Fetcher interface {
+	// Signatures returns the signatures of the image with the reference and
+	// manifest digest, or no signatures if the image is not signed.
+	Signatures(ctx context.Context, imageRef string, imageDigest digest.Digest) ([]Signature, error)
+}
+
+// payload is a simple signing payload, see
+// https://github.com/containers/image/blob/main/docs/containers-signature.5.md.
+type payload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// Verify verifies that one of the signatures signs the image manifest digest,
+// and was made by one of the keys. It returns the key that made the verified
+// signature.
+func Verify(sigs []Signature, imageDigest digest.Digest, keys []crypto.PublicKey) (crypto.PublicKey, error) {
+	if len(sigs) == 0 {
+		return nil, errors.New("image is not signed")
+	}
+	var errs []error
+	for _, sig := range sigs {
+		key, err := verifySignature(sig, keys)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := checkPayload(sig.Payload, imageDigest); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("none of the %d image signatures could be verified: %v", len(sigs), errs)
+}
+
+// verifySignature returns the key that made the signature on the payload.
+func verifySignature(sig Signature, keys []crypto.PublicKey) (crypto.PublicKey, error) {
+	hashed := sha256.Sum256(sig.Payload)
+	for _, key := range keys {
+		switch pub := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(pub, hashed[:], sig.Signature) {
+				return key, nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(pub, crypto.SHA256, hashed[:], sig.Signature) == nil {
+				return key, nil
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(pub, sig.Payload, sig.Signature) {
+				return key, nil
+			}
+		}
+	}
+	return nil, errors.New("signature not made by any of the image signing keys")
+}
+
+// CheckKeys returns an error if any of the keys has a type that cannot verify
+// image signatures. Verify ignores such keys.
+func CheckKeys(keys []crypto.PublicKey) error {
+	for _, key := range keys {
+		switch key.(type) {
+		case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		default:
+			return fmt.Errorf("unsupported image signing key type %T", key)
+		}
+	}
+	return nil
+}
+
+// checkPayload checks the payload is a signature on the image digest.
+func checkPayload(data []byte, imageDigest digest.Digest) error {
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("malformed signature payload: %v", err)
+	}
+	if p.Critical.Type != signatureType {
+		return fmt.Errorf("signature payload has type %q, want %q", p.Critical.Type, signatureType)
+	}
+	if got := p.Critical.Image.DockerManifestDigest; got != imageDigest.String() {
+		return fmt.Errorf("signature is for image digest %s, want %s", got, imageDigest)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package imagesig
+
+import (
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+const testImageDigest = digest.Digest("sha256:781d8dfdd92118436bd914442c8339e653b83f6bf3c1a7a98efcfb7c4fed7483")
+
+// testSigningKey returns the bundled test signing key.
+func testSigningKey(t *testing.T) crypto.Signer {
+	t.Helper()
+	data, err := os.ReadFile("testdata/signing_key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("no PEM block in testdata/signing_key.pem")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key.(crypto.Signer)
+}
+
+// testPayload returns a simple signing payload for the image digest.
+func testPayload(imageDigest digest.Digest) []byte {
+	return []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"docker.io/library/hello-world"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, imageDigest))
+}
+
+// sign returns a signature on the payload, as made by cosign.
+func sign(t *testing.T, key crypto.Signer, payload []byte) Signature {
+	t.Helper()
+	var sig []byte
+	var err error
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		sig, err = key.Sign(rand.Reader, payload, crypto.Hash(0))
+	} else {
+		hashed := sha256.Sum256(payload)
+		sig, err = key.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Signature{Payload: payload, Signature: sig}
+}
+
+func TestVerify(t *testing.T) {
+	bundled := testSigningKey(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keys of unsupported types are skipped.
+	keys := []crypto.PublicKey{&dsa.PublicKey{}, rsaKey.Public(), edKey.Public(), bundled.Public()}
+
+	for name, key := range map[string]crypto.Signer{"Bundled": bundled, "RSA": rsaKey, "Ed25519": edKey} {
+		t.Run(name, func(t *testing.T) {
+			otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sigs := []Signature{
+				sign(t, otherKey, testPayload(testImageDigest)),
+				sign(t, key, testPayload(testImageDigest)),
+			}
+			signer, err := Verify(sigs, testImageDigest, keys)
+			if err != nil {
+				t.Fatalf("Verify() failed: %v", err)
+			}
+			if !key.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(signer) {
+				t.Errorf("Verify() returned signer %v, want %v", signer, key.Public())
+			}
+		})
+	}
+}
+
+func TestCheckKeys(t *testing.T) {
+	key := testSigningKey(t)
+	if err := CheckKeys([]crypto.PublicKey{key.Public()}); err != nil {
+		t.Errorf("CheckKeys() failed: %v", err)
+	}
+	if err := CheckKeys([]crypto.PublicKey{key.Public(), &dsa.PublicKey{}}); err == nil {
+		t.Error("CheckKeys() succeeded with a DSA key")
+	}
+}
+
+func TestVerifyRejected(t *testing.T) {
+	key := testSigningKey(t)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherDigest := digest.FromString("other image")
+	tampered := sign(t, key, testPayload(testImageDigest))
+	tampered.Payload = testPayload(otherDigest)
+
+	tests := []struct {
+		name string
+		sigs []Signature
+	}{
+		{"Unsigned", nil},
+		{"WrongKey", []Signature{sign(t, otherKey, testPayload(testImageDigest))}},
+		{"OtherImage", []Signature{sign(t, key, testPayload(otherDigest))}},
+		{"TamperedPayload", []Signature{tampered}},
+		{"WrongType", []Signature{sign(t, key, []byte(fmt.Sprintf(`{"critical":{"image":{"docker-manifest-digest":%q},"type":"attestation"}}`, testImageDigest)))}},
+		{"MalformedPayload", []Signature{sign(t, key, []byte(testImageDigest))}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Verify(tc.sigs, testImageDigest, []crypto.PublicKey{key.Public()}); err == nil {
+				t.Error("Verify() succeeded")
+			}
+		})
+	}
+}
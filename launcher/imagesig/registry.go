@@ -0,0 +1,114 @@
+package imagesig
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/reference"
+	"github.com/containerd/containerd/remotes"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// signatureAnnotation is the annotation of the signature layers holding
+	// the base64 signature on the layer.
+	signatureAnnotation = "dev.cosignproject.cosign/signature"
+	// maxBlobSize limits the size of the signature manifest and payloads.
+	maxBlobSize = 1 << 20
+)
+
+type registryFetcher struct {
+	resolver remotes.Resolver
+}
+
+// NewRegistryFetcher returns a Fetcher reading the signatures cosign stores
+// in the image repository: the layers of the image tagged
+// "<algorithm>-<hex digest>.sig", with the signature in an annotation.
+func NewRegistryFetcher(resolver remotes.Resolver) Fetcher {
+	return &registryFetcher{resolver}
+}
+
+// signatureRef returns the reference of the signatures of the image.
+func signatureRef(imageRef string, imageDigest digest.Digest) (string, error) {
+	spec, err := reference.Parse(imageRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image reference %q: %v", imageRef, err)
+	}
+	return fmt.Sprintf("%s:%s-%s.sig", spec.Locator, imageDigest.Algorithm(), imageDigest.Encoded()), nil
+}
+
+func (f *registryFetcher) Signatures(ctx context.Context, imageRef string, imageDigest digest.Digest) ([]Signature, error) {
+	ref, err := signatureRef(imageRef, imageDigest)
+	if err != nil {
+		return nil, err
+	}
+	name, desc, err := f.resolver.Resolve(ctx, ref)
+	if errdefs.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve image signatures %s: %v", ref, err)
+	}
+	fetcher, err := f.resolver.Fetcher(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := fetchBlob(ctx, fetcher, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image signatures %s: %v", ref, err)
+	}
+	var manifest v1.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("malformed image signatures manifest %s: %v", ref, err)
+	}
+
+	var sigs []Signature
+	for _, layer := range manifest.Layers {
+		encoded, ok := layer.Annotations[signatureAnnotation]
+		if !ok {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("malformed image signature in layer %s: %v", layer.Digest, err)
+		}
+		payload, err := fetchBlob(ctx, fetcher, layer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch image signature payload %s: %v", layer.Digest, err)
+		}
+		sigs = append(sigs, Signature{Payload: payload, Signature: signature})
+	}
+	return sigs, nil
+}
+
+// fetchBlob fetches the blob of the descriptor, and checks its digest.
+func fetchBlob(ctx context.Context, fetcher remotes.Fetcher, desc v1.Descriptor) ([]byte, error) {
+	if desc.Size > maxBlobSize {
+		return nil, fmt.Errorf("blob is too large (%d bytes)", desc.Size)
+	}
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxBlobSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != desc.Size {
+		return nil, fmt.Errorf("got %d bytes, want %d", len(data), desc.Size)
+	}
+	if err := desc.Digest.Validate(); err != nil {
+		return nil, err
+	}
+	if got := desc.Digest.Algorithm().FromBytes(data); got != desc.Digest {
+		return nil, fmt.Errorf("got digest %s, want %s", got, desc.Digest)
+	}
+	return data, nil
+}
@@ -0,0 +1,145 @@
+package imagesig
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/google/go-cmp/cmp"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const simpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+// fakeRegistry serves the manifests and blobs of the test/image repository.
+type fakeRegistry struct {
+	manifests map[string][]byte
+	blobs     map[digest.Digest][]byte
+}
+
+func (r *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	const prefix = "/v2/test/image/"
+	var data []byte
+	var ok bool
+	var mediaType string
+	switch {
+	case req.URL.Path == "/v2/":
+		return
+	case strings.HasPrefix(req.URL.Path, prefix+"manifests/"):
+		data, ok = r.manifests[strings.TrimPrefix(req.URL.Path, prefix+"manifests/")]
+		mediaType = v1.MediaTypeImageManifest
+	case strings.HasPrefix(req.URL.Path, prefix+"blobs/"):
+		data, ok = r.blobs[digest.Digest(strings.TrimPrefix(req.URL.Path, prefix+"blobs/"))]
+		mediaType = "application/octet-stream"
+	}
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Docker-Content-Digest", digest.FromBytes(data).String())
+	if req.Method != http.MethodHead {
+		w.Write(data)
+	}
+}
+
+// addSignatures stores the signatures of the image digest, as cosign does.
+func (r *fakeRegistry) addSignatures(t *testing.T, imageDigest digest.Digest, sigs []Signature) {
+	t.Helper()
+	manifest := v1.Manifest{MediaType: v1.MediaTypeImageManifest}
+	manifest.SchemaVersion = 2
+	for _, sig := range sigs {
+		layer := v1.Descriptor{
+			MediaType:   simpleSigningMediaType,
+			Digest:      digest.FromBytes(sig.Payload),
+			Size:        int64(len(sig.Payload)),
+			Annotations: map[string]string{signatureAnnotation: base64.StdEncoding.EncodeToString(sig.Signature)},
+		}
+		manifest.Layers = append(manifest.Layers, layer)
+		r.blobs[layer.Digest] = sig.Payload
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The resolver resolves the tag, then fetches the manifest by digest.
+	r.manifests[imageDigest.Algorithm().String()+"-"+imageDigest.Encoded()+".sig"] = data
+	r.manifests[digest.FromBytes(data).String()] = data
+}
+
+func startFakeRegistry(t *testing.T) (*fakeRegistry, string, Fetcher) {
+	t.Helper()
+	registry := &fakeRegistry{manifests: map[string][]byte{}, blobs: map[digest.Digest][]byte{}}
+	ts := httptest.NewServer(registry)
+	t.Cleanup(ts.Close)
+	resolver := docker.NewResolver(docker.ResolverOptions{
+		Hosts: docker.ConfigureDefaultRegistries(docker.WithPlainHTTP(docker.MatchAllHosts)),
+	})
+	imageRef := strings.TrimPrefix(ts.URL, "http://") + "/test/image:latest"
+	return registry, imageRef, NewRegistryFetcher(resolver)
+}
+
+func TestRegistryFetcher(t *testing.T) {
+	registry, imageRef, fetcher := startFakeRegistry(t)
+	key := testSigningKey(t)
+	sigs := []Signature{
+		sign(t, key, testPayload(testImageDigest)),
+		sign(t, key, testPayload(digest.FromString("other image"))),
+	}
+	registry.addSignatures(t, testImageDigest, sigs)
+
+	got, err := fetcher.Signatures(context.Background(), imageRef, testImageDigest)
+	if err != nil {
+		t.Fatalf("Signatures() failed: %v", err)
+	}
+	if diff := cmp.Diff(sigs, got); diff != "" {
+		t.Errorf("unexpected signatures (-want +got):\n%s", diff)
+	}
+	if _, err := Verify(got, testImageDigest, []crypto.PublicKey{key.Public()}); err != nil {
+		t.Errorf("Verify() failed: %v", err)
+	}
+}
+
+func TestRegistryFetcherUnsigned(t *testing.T) {
+	_, imageRef, fetcher := startFakeRegistry(t)
+	sigs, err := fetcher.Signatures(context.Background(), imageRef, testImageDigest)
+	if err != nil {
+		t.Fatalf("Signatures() failed: %v", err)
+	}
+	if len(sigs) != 0 {
+		t.Errorf("got %d signatures for an unsigned image", len(sigs))
+	}
+}
+
+func TestRegistryFetcherTamperedPayload(t *testing.T) {
+	registry, imageRef, fetcher := startFakeRegistry(t)
+	sig := sign(t, testSigningKey(t), testPayload(testImageDigest))
+	registry.addSignatures(t, testImageDigest, []Signature{sig})
+	registry.blobs[digest.FromBytes(sig.Payload)] = testPayload(digest.FromString("other image"))
+
+	if _, err := fetcher.Signatures(context.Background(), imageRef, testImageDigest); err == nil {
+		t.Error("Signatures() succeeded with a payload not matching its digest")
+	}
+}
+
+func TestSignatureRef(t *testing.T) {
+	got, err := signatureRef("docker.io/library/hello-world:latest", testImageDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "docker.io/library/hello-world:sha256-" + testImageDigest.Encoded() + ".sig"; got != want {
+		t.Errorf("got signature ref %q, want %q", got, want)
+	}
+	if _, err := signatureRef("Invalid Ref", testImageDigest); err == nil {
+		t.Error("signatureRef() succeeded with an invalid reference")
+	}
+}
@@ -4,9 +4,13 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"cloud.google.com/go/compute/metadata"
 	"cloud.google.com/go/logging"
@@ -38,8 +42,15 @@ var rcMessage = map[int]string{
 	holdRC:    "VM remains running",
 }
 
+var (
+	specSource  = flag.String("launch-spec-source", "mds", `where to read the launch spec from: "mds" (GCE custom metadata), "file" or "cmdline" (kernel command line)`)
+	specFile    = flag.String("launch-spec-file", "", `JSON file of the launch spec attributes, for --launch-spec-source=file`)
+	identitySrc = flag.String("identity", "mds", `identity to pull images and attest with: "mds" (the GCE default service account) or "none"`)
+	signingKeys = flag.String("launch-spec-signing-keys", "/usr/share/oem/confidential_space/launch_spec_signing_keys.pem", `PEM file of the public keys the launch spec must be signed by; if the file does not exist, the launch spec is not required to be signed`)
+)
+
 var logger *log.Logger
-var mdsClient *metadata.Client
+var tokenProvider launcher.TokenProvider
 var launchSpec spec.LaunchSpec
 
 func main() {
@@ -61,17 +72,23 @@ func main() {
 		os.Exit(exitCode)
 	}()
 
-	mdsClient = metadata.NewClient(nil)
-	projectID, err := mdsClient.ProjectID()
+	flag.Parse()
+	source, err := getLaunchSpecSource()
 	if err != nil {
-		logger.Printf("cannot get projectID, not in GCE? %v", err)
-		// cannot get projectID from MDS, exit directly
+		logger.Println(err)
+		exitCode = failRC
+		return
+	}
+	if tokenProvider, err = getTokenProvider(); err != nil {
+		logger.Println(err)
 		exitCode = failRC
 		return
 	}
 
-	logClient, err := logging.NewClient(context.Background(), projectID)
-	if err != nil {
+	projectID, err := source.ProjectID()
+	if err != nil || projectID == "" {
+		logger.Printf("cannot get projectID, not in GCE? %v, using the default stdout logger", err)
+	} else if logClient, err := logging.NewClient(context.Background(), projectID); err != nil {
 		logger.Printf("cannot setup Cloud Logging, using the default stdout logger %v", err)
 	} else {
 		defer logClient.Close()
@@ -81,8 +98,14 @@ func main() {
 		logger.SetOutput(loggerAndStdout)
 	}
 
+	if source, err = signedLaunchSpecSource(source); err != nil {
+		logger.Println(err)
+		exitCode = failRC
+		return
+	}
+
 	// get restart policy and ishardened from spec
-	launchSpec, err = spec.GetLaunchSpec(mdsClient)
+	launchSpec, err = spec.GetLaunchSpec(source)
 	if err != nil {
 		logger.Println(err)
 		// if cannot get launchSpec, exit directly
@@ -110,6 +133,59 @@ func main() {
 	exitCode = getExitCode(launchSpec.Hardened, launchSpec.RestartPolicy, err)
 }
 
+// getLaunchSpecSource returns the launch spec source selected by the flags.
+func getLaunchSpecSource() (spec.Source, error) {
+	switch *specSource {
+	case "mds":
+		return spec.NewMDSSource(metadata.NewClient(nil)), nil
+	case "file":
+		if *specFile == "" {
+			return nil, errors.New("--launch-spec-file is required for --launch-spec-source=file")
+		}
+		return spec.NewFileSource(*specFile)
+	case "cmdline":
+		kernelCmd, err := os.ReadFile("/proc/cmdline")
+		if err != nil {
+			return nil, err
+		}
+		return spec.NewCmdlineSource(string(kernelCmd)), nil
+	}
+	return nil, fmt.Errorf("invalid --launch-spec-source %q", *specSource)
+}
+
+// signedLaunchSpecSource returns a source requiring the launch spec to be
+// signed, if signing keys are pinned in the image, or the source otherwise.
+func signedLaunchSpecSource(source spec.Source) (spec.Source, error) {
+	pemData, err := os.ReadFile(*signingKeys)
+	if errors.Is(err, os.ErrNotExist) {
+		return source, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read launch spec signing keys: %v", err)
+	}
+	keys, err := spec.ParsePublicKeys(pemData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse launch spec signing keys: %v", err)
+	}
+	logger.Printf("launch spec must be signed by one of the %d keys in %s\n", len(keys), *signingKeys)
+	return spec.NewSignedSource(source, keys)
+}
+
+// getTokenProvider returns the identity token provider selected by the flags.
+func getTokenProvider() (launcher.TokenProvider, error) {
+	switch *identitySrc {
+	case "mds":
+		return launcher.NewMDSTokenProvider(metadata.NewClient(nil)), nil
+	case "none":
+		return launcher.NoTokenProvider(), nil
+	}
+	return nil, fmt.Errorf("invalid --identity %q", *identitySrc)
+}
+
+// getExitCode returns the exit code of the launcher for the error of the
+// runner. The runner restarts the workload itself following the restart
+// policy, so the VM is only rebooted to retry a workload that failed to
+// start.
 func getExitCode(isHardened bool, restartPolicy spec.RestartPolicy, err error) int {
 	exitCode := 0
 
@@ -121,9 +197,9 @@ func getExitCode(isHardened bool, restartPolicy spec.RestartPolicy, err error) i
 	if err != nil {
 		switch err.(type) {
 		default:
-			// non-retryable error
+			// non-retryable error, or a workload error not restarted
 			exitCode = failRC
-		case *launcher.RetryableError, *launcher.WorkloadError:
+		case *launcher.RetryableError:
 			if restartPolicy == spec.Always || restartPolicy == spec.OnFailure {
 				exitCode = rebootRC
 			} else {
@@ -132,11 +208,7 @@ func getExitCode(isHardened bool, restartPolicy spec.RestartPolicy, err error) i
 		}
 	} else {
 		// if no error
-		if restartPolicy == spec.Always {
-			exitCode = rebootRC
-		} else {
-			exitCode = successRC
-		}
+		exitCode = successRC
 	}
 
 	return exitCode
@@ -156,27 +228,43 @@ func startLauncher() error {
 	}
 	defer tpm.Close()
 
-	// check AK (EK signing) cert
-	gceAk, err := client.GceAttestationKeyECC(tpm)
-	if err != nil {
-		return err
-	}
-	if gceAk.Cert() == nil {
-		return errors.New("failed to find AKCert on this VM: try creating a new VM or contacting support")
+	// check AK (EK signing) cert, which GCE VMs must have
+	if *identitySrc == "mds" {
+		gceAk, err := client.GceAttestationKeyECC(tpm)
+		if err != nil {
+			return err
+		}
+		if gceAk.Cert() == nil {
+			return errors.New("failed to find AKCert on this VM: try creating a new VM or contacting support")
+		}
+		gceAk.Close()
 	}
-	gceAk.Close()
 
-	token, err := launcher.RetrieveAuthToken(mdsClient)
+	token, err := tokenProvider.AccessToken()
 	if err != nil {
 		logger.Printf("failed to retrieve auth token: %v, using empty auth for image pulling\n", err)
 	}
 
+	// Forward stop signals, e.g. on VM preemption or shutdown, to the workload.
+	// They are caught before the runner is set up, so a signal received
+	// meanwhile is forwarded once the workload starts, rather than killing
+	// the launcher.
+	sigC := make(chan os.Signal, 1)
+	signal.Notify(sigC, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sigC)
+
 	ctx := namespaces.WithNamespace(context.Background(), namespaces.Default)
-	r, err := launcher.NewRunner(ctx, containerdClient, token, launchSpec, mdsClient, tpm, logger)
+	r, err := launcher.NewRunner(ctx, containerdClient, token, launchSpec, tokenProvider, tpm, logger)
 	if err != nil {
 		return err
 	}
 	defer r.Close(ctx)
 
+	go func() {
+		for sig := range sigC {
+			r.Stop(sig.(syscall.Signal))
+		}
+	}()
+
 	return r.Run(ctx)
 }
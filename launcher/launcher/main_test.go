@@ -32,7 +32,7 @@ func TestGetExitCode(t *testing.T) {
 		// no error, hardened image
 		{
 			"hardened, always restart, nil error",
-			true, spec.Always, nil, rebootRC,
+			true, spec.Always, nil, successRC,
 		},
 		{
 			"hardened, never restart, nil error",
@@ -81,10 +81,11 @@ func TestGetExitCode(t *testing.T) {
 			"hardened, onfailure restart, retryable error",
 			true, spec.OnFailure, &launcher.RetryableError{}, rebootRC,
 		},
-		// workload error, hardened image (same as retryable error)
+		// workload error, hardened image (restarted by the runner, so only
+		// returned once it is not restarted)
 		{
 			"hardened, always restart, workload error",
-			true, spec.Always, &launcher.WorkloadError{}, rebootRC,
+			true, spec.Always, &launcher.WorkloadError{}, failRC,
 		},
 		{
 			"hardened, never restart, workload error",
@@ -92,7 +93,7 @@ func TestGetExitCode(t *testing.T) {
 		},
 		{
 			"hardened, onfailure restart, workload error",
-			true, spec.OnFailure, &launcher.WorkloadError{}, rebootRC,
+			true, spec.OnFailure, &launcher.WorkloadError{}, failRC,
 		},
 		// non-retryable error, debug image
 		{
@@ -12,6 +12,22 @@ type LaunchPolicy struct {
 	AllowedEnvOverride []string
 	AllowedCmdOverride bool
 	AllowedLogRedirect logRedirectPolicy
+	// AllowedHostMounts are the host directories which can be bind mounted,
+	// with everything under them. Encrypted (luks) devices must be under them
+	// too, e.g. /dev/disk/by-id.
+	AllowedHostMounts  []string
+	AllowedHostNetwork bool
+	// AllowedCapabilities can be added to the default capabilities.
+	AllowedCapabilities []string
+	// MaxTmpfsSizeMB bounds the total size of the tmpfs mounts, which are
+	// not allowed if it is 0.
+	MaxTmpfsSizeMB uint64
+	// MinMemoryLimitMB and MaxMemoryLimitMB bound the memory limit, if not 0.
+	MinMemoryLimitMB uint64
+	MaxMemoryLimitMB uint64
+	// AllowedSidecars is whether sidecars can be launched next to the
+	// container of this image.
+	AllowedSidecars bool
 }
 
 type logRedirectPolicy int
@@ -38,9 +54,16 @@ func toLogRedirectPolicy(s string) (logRedirectPolicy, error) {
 }
 
 const (
-	envOverride = "tee.launch_policy.allow_env_override"
-	cmdOverride = "tee.launch_policy.allow_cmd_override"
-	logRedirect = "tee.launch_policy.log_redirect"
+	envOverride  = "tee.launch_policy.allow_env_override"
+	cmdOverride  = "tee.launch_policy.allow_cmd_override"
+	logRedirect  = "tee.launch_policy.log_redirect"
+	hostMounts   = "tee.launch_policy.allowed_host_mounts"
+	hostNetwork  = "tee.launch_policy.allow_host_network"
+	capabilities = "tee.launch_policy.allowed_capabilities"
+	minMemory    = "tee.launch_policy.min_memory_limit_mb"
+	maxMemory    = "tee.launch_policy.max_memory_limit_mb"
+	maxTmpfsSize = "tee.launch_policy.max_tmpfs_size_mb"
+	sidecars     = "tee.launch_policy.allow_sidecars"
 )
 
 // GetLaunchPolicy takes in a map[string] string which should come from image labels,
@@ -72,6 +95,59 @@ func GetLaunchPolicy(imageLabels map[string]string) (LaunchPolicy, error) {
 		}
 	}
 
+	if v, ok := imageLabels[hostMounts]; ok {
+		for _, dir := range strings.Split(v, ",") {
+			if dir == "" {
+				continue
+			}
+			if err := checkPath(dir); err != nil {
+				return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (%v); contact the image author", hostMounts, err)
+			}
+			launchPolicy.AllowedHostMounts = append(launchPolicy.AllowedHostMounts, dir)
+		}
+	}
+
+	// default is to allow the host network
+	launchPolicy.AllowedHostNetwork = true
+	if v, ok := imageLabels[hostNetwork]; ok {
+		if launchPolicy.AllowedHostNetwork, err = strconv.ParseBool(v); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not a boolean); contact the image author", hostNetwork)
+		}
+	}
+
+	if v, ok := imageLabels[capabilities]; ok {
+		if launchPolicy.AllowedCapabilities, err = parseCapabilities(v); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (%v); contact the image author", capabilities, err)
+		}
+	}
+
+	if v, ok := imageLabels[minMemory]; ok {
+		if launchPolicy.MinMemoryLimitMB, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not an integer); contact the image author", minMemory)
+		}
+	}
+	if v, ok := imageLabels[maxMemory]; ok {
+		if launchPolicy.MaxMemoryLimitMB, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not an integer); contact the image author", maxMemory)
+		}
+	}
+	if v, ok := imageLabels[maxTmpfsSize]; ok {
+		if launchPolicy.MaxTmpfsSizeMB, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not an integer); contact the image author", maxTmpfsSize)
+		}
+		if launchPolicy.MaxTmpfsSizeMB > maxSizeMB {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (greater than %d); contact the image author", maxTmpfsSize, uint64(maxSizeMB))
+		}
+	}
+	if v, ok := imageLabels[sidecars]; ok {
+		if launchPolicy.AllowedSidecars, err = strconv.ParseBool(v); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not a boolean); contact the image author", sidecars)
+		}
+	}
+	if launchPolicy.MaxMemoryLimitMB != 0 && launchPolicy.MinMemoryLimitMB > launchPolicy.MaxMemoryLimitMB {
+		return LaunchPolicy{}, fmt.Errorf("invalid image LABELs '%s' and '%s' (min is greater than max); contact the image author", minMemory, maxMemory)
+	}
+
 	return launchPolicy, nil
 }
 
@@ -95,9 +171,88 @@ func (p LaunchPolicy) Verify(ls LaunchSpec) error {
 		return fmt.Errorf("logging redirection only allowed on debug environment by image")
 	}
 
+	var tmpfsSize uint64
+	for _, m := range ls.Mounts {
+		switch m.Type {
+		case BindMount, LuksMount:
+			if !p.allowsHostMount(m.Source) {
+				return fmt.Errorf("host path %s is not allowed to be mounted on this image; allowed host mounts: %v", m.Source, p.AllowedHostMounts)
+			}
+		case TmpfsMount:
+			if p.MaxTmpfsSizeMB == 0 {
+				return fmt.Errorf("tmpfs mounts are not allowed on this image")
+			}
+			if p.MaxTmpfsSizeMB > maxSizeMB {
+				return fmt.Errorf("invalid maximum tmpfs size of %d MB on this image", p.MaxTmpfsSizeMB)
+			}
+			if m.SizeBytes > p.MaxTmpfsSizeMB*1024*1024-tmpfsSize {
+				return fmt.Errorf("total tmpfs mount size exceeds %d MB allowed on this image", p.MaxTmpfsSizeMB)
+			}
+			tmpfsSize += m.SizeBytes
+		}
+	}
+
+	if err := p.verifyResources(ls); err != nil {
+		return err
+	}
+
+	if !p.AllowedSidecars && len(ls.Sidecars) > 0 {
+		return fmt.Errorf("sidecars are not allowed to be launched with this image")
+	}
+
+	return nil
+}
+
+// verifyResources verifies the network, capabilities and memory limit that
+// the LaunchSpec gives its containers.
+func (p LaunchPolicy) verifyResources(ls LaunchSpec) error {
+	if !p.AllowedHostNetwork && ls.HostNetwork {
+		return fmt.Errorf("host network is not allowed by image, set %s=false", hostNetworkKey)
+	}
+
+	for _, c := range ls.AddedCapabilities {
+		if !contains(p.AllowedCapabilities, c) {
+			return fmt.Errorf("capability %s is not allowed to be added on this image; allowed capabilities: %v", c, p.AllowedCapabilities)
+		}
+	}
+
+	if p.MaxMemoryLimitMB != 0 && (ls.MemoryLimitMB == 0 || ls.MemoryLimitMB > p.MaxMemoryLimitMB) {
+		return fmt.Errorf("memory limit must be set to at most %d MB on this image", p.MaxMemoryLimitMB)
+	}
+	if ls.MemoryLimitMB != 0 && ls.MemoryLimitMB < p.MinMemoryLimitMB {
+		return fmt.Errorf("memory limit must be at least %d MB on this image", p.MinMemoryLimitMB)
+	}
 	return nil
 }
 
+// VerifySidecar will use the LaunchPolicy of the sidecar image to verify the
+// overrides of the given Sidecar, and the resources it gets from the
+// LaunchSpec: sidecars have the network, capabilities and memory limit of the
+// workload. If the verification passed, will return nil.
+func (p LaunchPolicy) VerifySidecar(s Sidecar, ls LaunchSpec) error {
+	for _, e := range s.Envs {
+		if !contains(p.AllowedEnvOverride, e.Name) {
+			return fmt.Errorf("env var %s is not allowed to be overridden on sidecar %s image; allowed envs to be overridden: %v", e.Name, s.Name, p.AllowedEnvOverride)
+		}
+	}
+	if !p.AllowedCmdOverride && len(s.Cmd) > 0 {
+		return fmt.Errorf("CMD is not allowed to be overridden on sidecar %s image", s.Name)
+	}
+	if err := p.verifyResources(ls); err != nil {
+		return fmt.Errorf("sidecar %s: %v", s.Name, err)
+	}
+	return nil
+}
+
+func (p LaunchPolicy) allowsHostMount(source string) bool {
+	for _, dir := range p.AllowedHostMounts {
+		if isUnder(source, dir) {
+			return true
+		}
+	}
+	return false
+}
+
 func contains(strs []string, target string) bool {
 	for _, s := range strs {
 		if s == target {
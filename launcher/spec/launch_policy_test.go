@@ -1,6 +1,7 @@
 package spec
 
 import (
+	"math"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -21,6 +22,7 @@ func TestLaunchPolicy(t *testing.T) {
 			LaunchPolicy{
 				AllowedEnvOverride: []string{"foo"},
 				AllowedCmdOverride: true,
+				AllowedHostNetwork: true,
 			},
 		},
 		{
@@ -31,6 +33,7 @@ func TestLaunchPolicy(t *testing.T) {
 			LaunchPolicy{
 				AllowedEnvOverride: []string{"foo", "bar"},
 				AllowedCmdOverride: false,
+				AllowedHostNetwork: true,
 			},
 		},
 		{
@@ -39,6 +42,7 @@ func TestLaunchPolicy(t *testing.T) {
 			LaunchPolicy{
 				AllowedEnvOverride: nil,
 				AllowedCmdOverride: false,
+				AllowedHostNetwork: true,
 			},
 		},
 		{
@@ -50,6 +54,28 @@ func TestLaunchPolicy(t *testing.T) {
 			LaunchPolicy{
 				AllowedEnvOverride: []string{"foo"},
 				AllowedCmdOverride: false,
+				AllowedHostNetwork: true,
+			},
+		},
+		{
+			"resource constraints",
+			map[string]string{
+				hostMounts:   "/mnt/disks/data,,/var/log",
+				hostNetwork:  "false",
+				capabilities: "NET_ADMIN,cap_sys_ptrace",
+				minMemory:    "256",
+				maxMemory:    "1024",
+				maxTmpfsSize: "64",
+				sidecars:     "true",
+			},
+			LaunchPolicy{
+				AllowedHostMounts:   []string{"/mnt/disks/data", "/var/log"},
+				AllowedHostNetwork:  false,
+				AllowedCapabilities: []string{"CAP_NET_ADMIN", "CAP_SYS_PTRACE"},
+				MinMemoryLimitMB:    256,
+				MaxMemoryLimitMB:    1024,
+				MaxTmpfsSizeMB:      64,
+				AllowedSidecars:     true,
 			},
 		},
 	}
@@ -68,6 +94,27 @@ func TestLaunchPolicy(t *testing.T) {
 	}
 }
 
+func TestLaunchPolicyBadLabels(t *testing.T) {
+	for _, labels := range []map[string]string{
+		{cmdOverride: "yes please"},
+		{logRedirect: "sometimes"},
+		{hostMounts: "mnt/disks/data"},
+		{hostMounts: "/mnt/disks/../data"},
+		{hostNetwork: "maybe"},
+		{capabilities: "NET-ADMIN"},
+		{minMemory: "lots"},
+		{maxMemory: "-1"},
+		{minMemory: "1024", maxMemory: "256"},
+		{maxTmpfsSize: "big"},
+		{maxTmpfsSize: "8796093022208"},
+		{sidecars: "some"},
+	} {
+		if _, err := GetLaunchPolicy(labels); err == nil {
+			t.Errorf("GetLaunchPolicy(%v) succeeded", labels)
+		}
+	}
+}
+
 func TestVerify(t *testing.T) {
 	testCases := []struct {
 		testName  string
@@ -115,6 +162,206 @@ func TestVerify(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"allowed host mounts",
+			LaunchPolicy{
+				AllowedHostMounts: []string{"/mnt/disks/data", "/var/log"},
+			},
+			LaunchSpec{
+				Mounts: []Mount{
+					{Type: BindMount, Source: "/mnt/disks/data", Destination: "/data"},
+					{Type: BindMount, Source: "/var/log/app", Destination: "/logs"},
+				},
+			},
+			false,
+		},
+		{
+			"host mount violation",
+			LaunchPolicy{
+				AllowedHostMounts: []string{"/var/log"},
+			},
+			LaunchSpec{
+				Mounts: []Mount{{Type: BindMount, Source: "/var/logs", Destination: "/logs"}},
+			},
+			true,
+		},
+		{
+			"allowed luks mounts",
+			LaunchPolicy{
+				AllowedHostMounts: []string{"/dev/disk/by-id"},
+			},
+			LaunchSpec{
+				Mounts: []Mount{{Type: LuksMount, Source: "/dev/disk/by-id/google-secrets", Destination: "/secrets", SealedKey: []byte("key")}},
+			},
+			false,
+		},
+		{
+			"luks mount violation",
+			LaunchPolicy{
+				AllowedHostMounts: []string{"/mnt/disks"},
+			},
+			LaunchSpec{
+				Mounts: []Mount{{Type: LuksMount, Source: "/dev/sda", Destination: "/host", SealedKey: []byte("key")}},
+			},
+			true,
+		},
+		{
+			"host mount violation without allowed host mounts",
+			LaunchPolicy{},
+			LaunchSpec{
+				Mounts: []Mount{{Type: BindMount, Source: "/", Destination: "/host"}},
+			},
+			true,
+		},
+		{
+			"allowed tmpfs mounts",
+			LaunchPolicy{
+				MaxTmpfsSizeMB: 64,
+			},
+			LaunchSpec{
+				Mounts: []Mount{
+					{Type: TmpfsMount, Destination: "/scratch", SizeBytes: 32 << 20},
+					{Type: TmpfsMount, Destination: "/cache", SizeBytes: 32 << 20},
+				},
+			},
+			false,
+		},
+		{
+			"tmpfs mount violation",
+			LaunchPolicy{},
+			LaunchSpec{
+				Mounts: []Mount{{Type: TmpfsMount, Destination: "/scratch", SizeBytes: 1}},
+			},
+			true,
+		},
+		{
+			"tmpfs size violation",
+			LaunchPolicy{
+				MaxTmpfsSizeMB: 64,
+			},
+			LaunchSpec{
+				Mounts: []Mount{
+					{Type: TmpfsMount, Destination: "/scratch", SizeBytes: 32 << 20},
+					{Type: TmpfsMount, Destination: "/cache", SizeBytes: 32<<20 + 1},
+				},
+			},
+			true,
+		},
+		{
+			"tmpfs size overflow",
+			LaunchPolicy{
+				MaxTmpfsSizeMB: math.MaxUint64,
+			},
+			LaunchSpec{
+				Mounts: []Mount{{Type: TmpfsMount, Destination: "/scratch", SizeBytes: 1 << 30}},
+			},
+			true,
+		},
+		{
+			"host network violation",
+			LaunchPolicy{
+				AllowedHostNetwork: false,
+			},
+			LaunchSpec{
+				HostNetwork: true,
+			},
+			true,
+		},
+		{
+			"host network allowed",
+			LaunchPolicy{
+				AllowedHostNetwork: true,
+			},
+			LaunchSpec{
+				HostNetwork: true,
+			},
+			false,
+		},
+		{
+			"sidecars violation",
+			LaunchPolicy{},
+			LaunchSpec{
+				Sidecars: []Sidecar{{Name: "envoy", ImageRef: "docker.io/envoyproxy/envoy:v1.27.0"}},
+			},
+			true,
+		},
+		{
+			"sidecars allowed",
+			LaunchPolicy{
+				AllowedSidecars: true,
+			},
+			LaunchSpec{
+				Sidecars: []Sidecar{{Name: "envoy", ImageRef: "docker.io/envoyproxy/envoy:v1.27.0"}},
+			},
+			false,
+		},
+		{
+			"allowed capabilities",
+			LaunchPolicy{
+				AllowedCapabilities: []string{"CAP_NET_ADMIN", "CAP_SYS_PTRACE"},
+			},
+			LaunchSpec{
+				AddedCapabilities: []string{"CAP_NET_ADMIN"},
+			},
+			false,
+		},
+		{
+			"capability violation",
+			LaunchPolicy{
+				AllowedCapabilities: []string{"CAP_NET_ADMIN"},
+			},
+			LaunchSpec{
+				AddedCapabilities: []string{"CAP_SYS_ADMIN"},
+			},
+			true,
+		},
+		{
+			"memory limit within bounds",
+			LaunchPolicy{
+				MinMemoryLimitMB: 256,
+				MaxMemoryLimitMB: 1024,
+			},
+			LaunchSpec{
+				MemoryLimitMB: 512,
+			},
+			false,
+		},
+		{
+			"memory limit above max",
+			LaunchPolicy{
+				MaxMemoryLimitMB: 1024,
+			},
+			LaunchSpec{
+				MemoryLimitMB: 2048,
+			},
+			true,
+		},
+		{
+			"memory limit unset with max",
+			LaunchPolicy{
+				MaxMemoryLimitMB: 1024,
+			},
+			LaunchSpec{},
+			true,
+		},
+		{
+			"memory limit below min",
+			LaunchPolicy{
+				MinMemoryLimitMB: 256,
+			},
+			LaunchSpec{
+				MemoryLimitMB: 128,
+			},
+			true,
+		},
+		{
+			"memory limit unset with min",
+			LaunchPolicy{
+				MinMemoryLimitMB: 256,
+			},
+			LaunchSpec{},
+			false,
+		},
 		{
 			"log redirect (never) test 1",
 			LaunchPolicy{
@@ -264,6 +511,54 @@ func TestVerify(t *testing.T) {
 	}
 }
 
+func TestVerifySidecar(t *testing.T) {
+	sidecar := Sidecar{
+		Name:     "envoy",
+		ImageRef: "docker.io/envoyproxy/envoy:v1.27.0",
+		Cmd:      []string{"-c", "/etc/envoy.yaml"},
+		Envs:     []EnvVar{{Name: "ENVOY_UID", Value: "0"}},
+	}
+	launchSpec := LaunchSpec{
+		HostNetwork:       true,
+		AddedCapabilities: []string{"CAP_NET_ADMIN"},
+		MemoryLimitMB:     512,
+		Sidecars:          []Sidecar{sidecar},
+	}
+	allowed := LaunchPolicy{
+		AllowedEnvOverride:  []string{"ENVOY_UID"},
+		AllowedCmdOverride:  true,
+		AllowedHostNetwork:  true,
+		AllowedCapabilities: []string{"CAP_NET_ADMIN"},
+	}
+	with := func(modify func(*LaunchPolicy)) LaunchPolicy {
+		p := allowed
+		modify(&p)
+		return p
+	}
+	testCases := []struct {
+		testName  string
+		policy    LaunchPolicy
+		expectErr bool
+	}{
+		{"allows overrides", allowed, false},
+		{"env override violation", with(func(p *LaunchPolicy) { p.AllowedEnvOverride = nil }), true},
+		{"cmd override violation", with(func(p *LaunchPolicy) { p.AllowedCmdOverride = false }), true},
+		{"host network violation", with(func(p *LaunchPolicy) { p.AllowedHostNetwork = false }), true},
+		{"capability violation", with(func(p *LaunchPolicy) { p.AllowedCapabilities = []string{"CAP_SYS_ADMIN"} }), true},
+		{"memory limit within bounds", with(func(p *LaunchPolicy) { p.MinMemoryLimitMB, p.MaxMemoryLimitMB = 256, 1024 }), false},
+		{"memory limit above max", with(func(p *LaunchPolicy) { p.MaxMemoryLimitMB = 256 }), true},
+		{"memory limit below min", with(func(p *LaunchPolicy) { p.MinMemoryLimitMB = 1024 }), true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			err := testCase.policy.VerifySidecar(sidecar, launchSpec)
+			if (err != nil) != testCase.expectErr {
+				t.Errorf("VerifySidecar() got error %v, expectErr %v", err, testCase.expectErr)
+			}
+		})
+	}
+}
+
 func TestIsHardened(t *testing.T) {
 	testCases := []struct {
 		testName       string
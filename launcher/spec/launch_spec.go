@@ -3,13 +3,18 @@
 package spec
 
 import (
+	"crypto"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/compute/metadata"
+	"github.com/google/go-tpm-tools/launcher/imagesig"
 )
 
 // RestartPolicy is the enum for the container restart policy.
@@ -30,6 +35,30 @@ const (
 	Never     RestartPolicy = "Never"
 )
 
+// VerifierType is the enum for the type of attestation verifier.
+type VerifierType string
+
+func (t VerifierType) isValid() error {
+	switch t {
+	case GoogleVerifier, SelfHostedVerifier, GRPCVerifier:
+		return nil
+	}
+	return fmt.Errorf("invalid attestation service type: %s", t)
+}
+
+// Verifier type enum values.
+const (
+	// GoogleVerifier is the Google attestation service REST API.
+	GoogleVerifier VerifierType = "google"
+	// SelfHostedVerifier is a verifier from launcher/verifier/selfhosted,
+	// which requires the AttestationServiceAddr to be set.
+	SelfHostedVerifier VerifierType = "self-hosted"
+	// GRPCVerifier is a verifier serving the AttestationVerifier gRPC
+	// service over TLS, which requires the AttestationServiceAddr to be set
+	// (e.g. "verifier.example.com:443").
+	GRPCVerifier VerifierType = "grpc"
+)
+
 // Metadata variable names.
 const (
 	imageRefKey                = "tee-image-reference"
@@ -38,11 +67,25 @@ const (
 	envKeyPrefix               = "tee-env-"
 	impersonateServiceAccounts = "tee-impersonate-service-accounts"
 	attestationServiceAddrKey  = "tee-attestation-service-endpoint"
+	attestationServiceTypeKey  = "tee-attestation-service-type"
 	logRedirectKey             = "tee-container-log-redirect"
+	imageSigningKeysKey        = "tee-image-signing-keys"
+	memoryLimitKey             = "tee-memory-limit-mb"
+	mountsKey                  = "tee-mounts"
+	hostNetworkKey             = "tee-host-network"
+	addedCapabilitiesKey       = "tee-added-capabilities"
+	sidecarsKey                = "tee-sidecars"
+	stopGracePeriodKey         = "tee-stop-grace-period"
 )
 
 const (
 	instanceAttributesQuery = "instance/attributes/?recursive=true"
+	// defaultStopGracePeriod is the default StopGracePeriod, like the
+	// default timeout of docker stop.
+	defaultStopGracePeriod = 10 * time.Second
+	// maxSizeMB is the largest size in MiB whose size in bytes fits the int64
+	// memory limits of the OCI runtime spec.
+	maxSizeMB = math.MaxInt64 >> 20
 )
 
 var errImageRefNotSpecified = fmt.Errorf("%s is not specified in the custom metadata", imageRefKey)
@@ -62,11 +105,34 @@ type LaunchSpec struct {
 	Cmd                        []string
 	Envs                       []EnvVar
 	AttestationServiceAddr     string
+	AttestationServiceType     VerifierType
 	ImpersonateServiceAccounts []string
 	ProjectID                  string
 	Region                     string
 	Hardened                   bool
 	LogRedirect                bool
+	// MemoryLimitMB is the memory limit of the container in MiB, or 0 if it
+	// is unlimited.
+	MemoryLimitMB uint64
+	Mounts        []Mount
+	// HostNetwork is whether the container uses the host network, which is
+	// the default. Otherwise the container only has a loopback interface.
+	HostNetwork bool
+	// AddedCapabilities are the capabilities (e.g. "CAP_NET_ADMIN") added to
+	// the default capabilities of the container.
+	AddedCapabilities []string
+	// Sidecars are the containers launched next to the main container. They
+	// share its network, the host network or its loopback interface, and are
+	// stopped when it exits.
+	Sidecars []Sidecar
+	// StopGracePeriod is how long the workload, and then the sidecars, have to
+	// exit after the stop signal is forwarded to them, before they are killed.
+	StopGracePeriod time.Duration
+	// ImageSigningKeys are the keys one of which must have signed the image.
+	ImageSigningKeys []crypto.PublicKey
+	// LaunchSpecSigner is the identity of the key that signed the launch
+	// spec (see cel.FormatLaunchSpecSigner), or empty if it was not signed.
+	LaunchSpecSigner string
 }
 
 // UnmarshalJSON unmarshals an instance attributes list in JSON format from the metadata
@@ -119,8 +185,98 @@ func (s *LaunchSpec) UnmarshalJSON(b []byte) error {
 		s.LogRedirect = logRedirect
 	}
 
+	if val, ok := unmarshaledMap[memoryLimitKey]; ok && val != "" {
+		memoryLimit, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", memoryLimitKey, err)
+		}
+		if memoryLimit > maxSizeMB {
+			return fmt.Errorf("invalid %s: %d is greater than %d", memoryLimitKey, memoryLimit, uint64(maxSizeMB))
+		}
+		s.MemoryLimitMB = memoryLimit
+	}
+
+	if val, ok := unmarshaledMap[mountsKey]; ok && val != "" {
+		mounts, err := parseMounts(val)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", mountsKey, err)
+		}
+		s.Mounts = mounts
+	}
+
+	// by default the container uses the host network
+	s.HostNetwork = true
+	if val, ok := unmarshaledMap[hostNetworkKey]; ok && val != "" {
+		hostNetwork, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", hostNetworkKey, err)
+		}
+		s.HostNetwork = hostNetwork
+	}
+
+	if val, ok := unmarshaledMap[addedCapabilitiesKey]; ok && val != "" {
+		caps, err := parseCapabilities(val)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", addedCapabilitiesKey, err)
+		}
+		s.AddedCapabilities = caps
+	}
+
+	if val, ok := unmarshaledMap[sidecarsKey]; ok && val != "" {
+		sidecars, err := parseSidecars(val)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", sidecarsKey, err)
+		}
+		s.Sidecars = sidecars
+	}
+
+	s.StopGracePeriod = defaultStopGracePeriod
+	if val, ok := unmarshaledMap[stopGracePeriodKey]; ok && val != "" {
+		gracePeriod, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", stopGracePeriodKey, err)
+		}
+		if gracePeriod < 0 {
+			return fmt.Errorf("invalid %s: %v is negative", stopGracePeriodKey, gracePeriod)
+		}
+		s.StopGracePeriod = gracePeriod
+	}
+
+	// image signing keys are PEM-encoded public keys
+	if val, ok := unmarshaledMap[imageSigningKeysKey]; ok && val != "" {
+		keys, err := ParsePublicKeys([]byte(val))
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", imageSigningKeysKey, err)
+		}
+		if err := imagesig.CheckKeys(keys); err != nil {
+			return fmt.Errorf("invalid %s: %v", imageSigningKeysKey, err)
+		}
+		s.ImageSigningKeys = keys
+	}
+
 	s.AttestationServiceAddr = unmarshaledMap[attestationServiceAddrKey]
 
+	s.AttestationServiceType = VerifierType(unmarshaledMap[attestationServiceTypeKey])
+	if s.AttestationServiceType == "" {
+		s.AttestationServiceType = GoogleVerifier
+	}
+	if err := s.AttestationServiceType.isValid(); err != nil {
+		return err
+	}
+	if s.AttestationServiceType != GoogleVerifier && s.AttestationServiceAddr == "" {
+		return fmt.Errorf("%s is required for a %s attestation service", attestationServiceAddrKey, s.AttestationServiceType)
+	}
+	// The key release service unwrapping a wrapped key is authenticated to
+	// with a token whose audience is its endpoint, which the Google
+	// attestation service cannot set.
+	if s.AttestationServiceType == GoogleVerifier {
+		for _, m := range s.Mounts {
+			if len(m.WrappedKey) > 0 {
+				return fmt.Errorf("invalid %s: the wrapped key of the mount at %s requires a %s or %s attestation service", mountsKey, m.Destination, SelfHostedVerifier, GRPCVerifier)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -136,30 +292,34 @@ func getRegion(client *metadata.Client) (string, error) {
 	return zone[:lastDash], nil
 }
 
-// GetLaunchSpec takes in a metadata server client, reads and parse operator's
-// input to the GCE instance custom metadata and return a LaunchSpec.
-// ImageRef (tee-image-reference) is required, will return an error if
-// ImageRef is not presented in the metadata.
-func GetLaunchSpec(client *metadata.Client) (LaunchSpec, error) {
-	data, err := client.Get(instanceAttributesQuery)
+// GetLaunchSpec reads and parses the operator's launch configuration from the
+// source, and returns a LaunchSpec. ImageRef (tee-image-reference) is
+// required, will return an error if ImageRef is not presented in the
+// instance attributes.
+func GetLaunchSpec(source Source) (LaunchSpec, error) {
+	data, err := source.InstanceAttributes()
 	if err != nil {
 		return LaunchSpec{}, err
 	}
 
 	spec := &LaunchSpec{}
-	if err := spec.UnmarshalJSON([]byte(data)); err != nil {
+	if err := spec.UnmarshalJSON(data); err != nil {
 		return LaunchSpec{}, err
 	}
+	spec.LaunchSpecSigner = source.LaunchSpecSigner()
 
-	spec.ProjectID, err = client.ProjectID()
+	spec.ProjectID, err = source.ProjectID()
 	if err != nil {
-		return LaunchSpec{}, fmt.Errorf("failed to retrieve projectID from MDS: %v", err)
+		return LaunchSpec{}, err
 	}
 
-	spec.Region, err = getRegion(client)
+	spec.Region, err = source.Region()
 	if err != nil {
 		return LaunchSpec{}, err
 	}
+	if spec.AttestationServiceType == GoogleVerifier && (spec.ProjectID == "" || spec.Region == "") {
+		return LaunchSpec{}, fmt.Errorf("the project ID and region are required for a %s attestation service", GoogleVerifier)
+	}
 
 	kernelCmd, err := readCmdline()
 	if err != nil {
@@ -186,3 +346,25 @@ func readCmdline() (string, error) {
 	}
 	return string(kernelCmd), nil
 }
+
+// parseCapabilities parses comma-separated capabilities, with or without the
+// "CAP_" prefix (e.g. "net_admin"), and returns them in the canonical form
+// (e.g. "CAP_NET_ADMIN").
+func parseCapabilities(val string) ([]string, error) {
+	var capabilityRegexp = regexp.MustCompile("^CAP_[A-Z_]+$")
+	var caps []string
+	for _, c := range strings.Split(val, ",") {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if c == "" {
+			continue
+		}
+		if !strings.HasPrefix(c, "CAP_") {
+			c = "CAP_" + c
+		}
+		if !capabilityRegexp.MatchString(c) {
+			return nil, fmt.Errorf("malformed capability %q", c)
+		}
+		caps = append(caps, c)
+	}
+	return caps, nil
+}
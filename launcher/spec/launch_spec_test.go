@@ -1,7 +1,14 @@
 package spec
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -44,6 +51,9 @@ func TestLaunchSpecUnmarshalJSONHappyCases(t *testing.T) {
 		Envs:                       []EnvVar{{"foo", "bar"}},
 		ImpersonateServiceAccounts: []string{"sv1@developer.gserviceaccount.com", "sv2@developer.gserviceaccount.com"},
 		LogRedirect:                true,
+		AttestationServiceType:     GoogleVerifier,
+		HostNetwork:                true,
+		StopGracePeriod:            defaultStopGracePeriod,
 	}
 
 	for _, testcase := range testCases {
@@ -91,6 +101,97 @@ func TestLaunchSpecUnmarshalJSONBadInput(t *testing.T) {
 				"tee-restart-policy":"noway",
 			}`,
 		},
+		{
+			"WrongAttestationServiceType",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-attestation-service-type":"noway"
+			}`,
+		},
+		{
+			"KeyReleaseMountWithGoogleAttestationService",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-mounts":"type=luks,source=/dev/sdb,destination=/secrets,key-release-endpoint=https://kr.example.com/v1/release,wrapped-key=a2V5"
+			}`,
+		},
+		{
+			"SelfHostedAttestationServiceWithoutEndpoint",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-attestation-service-type":"self-hosted"
+			}`,
+		},
+		{
+			"InvalidImageSigningKeys",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-image-signing-keys":"not a key"
+			}`,
+		},
+		{
+			"InvalidMemoryLimit",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-memory-limit-mb":"-1"
+			}`,
+		},
+		{
+			"MemoryLimitOverflow",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-memory-limit-mb":"8796093022208"
+			}`,
+		},
+		{
+			"InvalidMounts",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-mounts":"source=data,destination=/data"
+			}`,
+		},
+		{
+			"InvalidHostNetwork",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-host-network":"maybe"
+			}`,
+		},
+		{
+			"InvalidCapabilities",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-added-capabilities":"NET-ADMIN"
+			}`,
+		},
+		{
+			"InvalidSidecars",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-sidecars":"[{\"name\":\"envoy\"}]"
+			}`,
+		},
+		{
+			"InvalidStopGracePeriod",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-stop-grace-period":"soon"
+			}`,
+		},
+		{
+			"NegativeStopGracePeriod",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-stop-grace-period":"-1s"
+			}`,
+		},
+		{
+			"GRPCAttestationServiceWithoutEndpoint",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-attestation-service-type":"grpc"
+			}`,
+		},
 	}
 
 	for _, testcase := range testCases {
@@ -115,8 +216,37 @@ func TestLaunchSpecUnmarshalJSONWithDefaultValue(t *testing.T) {
 	}
 
 	want := &LaunchSpec{
-		ImageRef:      "docker.io/library/hello-world:latest",
-		RestartPolicy: Never,
+		ImageRef:               "docker.io/library/hello-world:latest",
+		RestartPolicy:          Never,
+		AttestationServiceType: GoogleVerifier,
+		HostNetwork:            true,
+		StopGracePeriod:        defaultStopGracePeriod,
+	}
+
+	if !cmp.Equal(spec, want) {
+		t.Errorf("LaunchSpec UnmarshalJSON got %+v, want %+v", spec, want)
+	}
+}
+
+func TestLaunchSpecUnmarshalJSONSelfHostedVerifier(t *testing.T) {
+	mdsJSON := `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-attestation-service-endpoint":"https://verifier.example.com",
+		"tee-attestation-service-type":"self-hosted"
+		}`
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON([]byte(mdsJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &LaunchSpec{
+		ImageRef:               "docker.io/library/hello-world:latest",
+		RestartPolicy:          Never,
+		AttestationServiceAddr: "https://verifier.example.com",
+		AttestationServiceType: SelfHostedVerifier,
+		HostNetwork:            true,
+		StopGracePeriod:        defaultStopGracePeriod,
 	}
 
 	if !cmp.Equal(spec, want) {
@@ -124,6 +254,121 @@ func TestLaunchSpecUnmarshalJSONWithDefaultValue(t *testing.T) {
 	}
 }
 
+func TestLaunchSpecUnmarshalJSONResources(t *testing.T) {
+	mdsJSON := `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-memory-limit-mb":"512",
+		"tee-mounts":"type=bind,source=/mnt/disks/data,destination=/data,readonly;src=/var/log/app,dst=/logs",
+		"tee-host-network":"false",
+		"tee-added-capabilities":"net_admin, CAP_SYS_PTRACE",
+		"tee-stop-grace-period":"25s"
+		}`
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON([]byte(mdsJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &LaunchSpec{
+		ImageRef:               "docker.io/library/hello-world:latest",
+		RestartPolicy:          Never,
+		AttestationServiceType: GoogleVerifier,
+		MemoryLimitMB:          512,
+		Mounts: []Mount{
+			{Type: BindMount, Source: "/mnt/disks/data", Destination: "/data", ReadOnly: true},
+			{Type: BindMount, Source: "/var/log/app", Destination: "/logs"},
+		},
+		HostNetwork:       false,
+		AddedCapabilities: []string{"CAP_NET_ADMIN", "CAP_SYS_PTRACE"},
+		StopGracePeriod:   25 * time.Second,
+	}
+
+	if diff := cmp.Diff(want, spec); diff != "" {
+		t.Errorf("LaunchSpec UnmarshalJSON got unexpected spec (-want +got):\n%s", diff)
+	}
+}
+
+func TestLaunchSpecUnmarshalJSONKeyReleaseMount(t *testing.T) {
+	mdsJSON := `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-attestation-service-type":"self-hosted",
+		"tee-attestation-service-endpoint":"https://verifier.example.com",
+		"tee-mounts":"type=luks,source=/dev/sdb,destination=/secrets,key-release-endpoint=https://kr.example.com/v1/release,wrapped-key=a2V5"
+		}`
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON([]byte(mdsJSON)); err != nil {
+		t.Fatal(err)
+	}
+	want := []Mount{{Type: LuksMount, Source: "/dev/sdb", Destination: "/secrets", KeyReleaseEndpoint: "https://kr.example.com/v1/release", WrappedKey: []byte("key")}}
+	if diff := cmp.Diff(want, spec.Mounts); diff != "" {
+		t.Errorf("LaunchSpec UnmarshalJSON got unexpected mounts (-want +got):\n%s", diff)
+	}
+
+	// A key release secret is released to the attested TPM, without a token
+	// of the attestation service.
+	mdsJSON = `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-mounts":"type=luks,source=/dev/sdb,destination=/secrets,key-release-endpoint=https://kr.example.com,key-release-secret=disk-key"
+		}`
+	spec = &LaunchSpec{}
+	if err := spec.UnmarshalJSON([]byte(mdsJSON)); err != nil {
+		t.Fatal(err)
+	}
+	want = []Mount{{Type: LuksMount, Source: "/dev/sdb", Destination: "/secrets", KeyReleaseEndpoint: "https://kr.example.com", KeyReleaseSecret: "disk-key"}}
+	if diff := cmp.Diff(want, spec.Mounts); diff != "" {
+		t.Errorf("LaunchSpec UnmarshalJSON got unexpected mounts (-want +got):\n%s", diff)
+	}
+}
+
+func TestLaunchSpecUnmarshalJSONSidecars(t *testing.T) {
+	mdsJSON := `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-host-network":"false",
+		"tee-sidecars":"[{\"name\":\"envoy\",\"image\":\"docker.io/envoyproxy/envoy:v1.27.0\",\"cmd\":[\"-c\",\"/etc/envoy.yaml\"]}]"
+		}`
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON([]byte(mdsJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Sidecar{{Name: "envoy", ImageRef: "docker.io/envoyproxy/envoy:v1.27.0", Cmd: []string{"-c", "/etc/envoy.yaml"}}}
+	if diff := cmp.Diff(want, spec.Sidecars); diff != "" {
+		t.Errorf("LaunchSpec UnmarshalJSON got unexpected sidecars (-want +got):\n%s", diff)
+	}
+	// The sidecars share the loopback interface of the workload.
+	if spec.HostNetwork {
+		t.Error("LaunchSpec UnmarshalJSON with sidecars got the host network, want it unset")
+	}
+}
+
+func TestLaunchSpecUnmarshalJSONImageSigningKeys(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdsJSON, err := json.Marshal(map[string]string{
+		"tee-image-reference":    "docker.io/library/hello-world:latest",
+		"tee-image-signing-keys": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON(mdsJSON); err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.ImageSigningKeys) != 1 || !key.PublicKey.Equal(spec.ImageSigningKeys[0]) {
+		t.Errorf("LaunchSpec UnmarshalJSON got image signing keys %v, want [%v]", spec.ImageSigningKeys, key.Public())
+	}
+}
+
 func TestLaunchSpecUnmarshalJSONWithoutImageReference(t *testing.T) {
 	mdsJSON := `{
 		"tee-cmd":"[\"--foo\",\"--bar\",\"--baz\"]",
@@ -0,0 +1,221 @@
+package spec
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// MountType is the type of a Mount.
+type MountType string
+
+// Mount types.
+const (
+	// BindMount mounts a host directory or file into the container.
+	BindMount MountType = "bind"
+	// TmpfsMount mounts an in-memory scratch volume of a fixed size.
+	TmpfsMount MountType = "tmpfs"
+	// LuksMount unlocks a LUKS encrypted host device, and mounts its
+	// filesystem into the container.
+	LuksMount MountType = "luks"
+)
+
+// Mount is a mount of the container requested by the operator.
+type Mount struct {
+	Type MountType
+	// Source is the host path of a BindMount, or the device of a LuksMount.
+	Source      string
+	Destination string
+	ReadOnly    bool
+	// SizeBytes is the size of a TmpfsMount.
+	SizeBytes uint64
+	// KeyReleaseEndpoint is the URL of the key release service releasing the
+	// key of a LuksMount to the attested workload. The service either unwraps
+	// the WrappedKey for the attestation token of the workload, or is a
+	// keyrelease.Server releasing the KeyReleaseSecret to the attested TPM.
+	KeyReleaseEndpoint string
+	WrappedKey         []byte
+	KeyReleaseSecret   string
+	// SealedKey is the key of a LuksMount sealed to PCR 13 of the TPM, as a
+	// serialized tpm.SealedBytes, used instead of a WrappedKey.
+	SealedKey []byte
+}
+
+// parseMounts parses mounts separated by ';', each formatted like a docker
+// --mount flag, as comma-separated key=value fields:
+//
+//	type=bind,source=/mnt/disks/data,destination=/data,readonly
+//	type=tmpfs,destination=/scratch,size=1g
+//	type=luks,source=/dev/disk/by-id/google-secrets,destination=/secrets,key-release-endpoint=https://kr.example.com/v1/release,wrapped-key=<base64>
+//	type=luks,source=/dev/disk/by-id/google-secrets,destination=/secrets,key-release-endpoint=https://kr.example.com,key-release-secret=disk-key
+//	type=luks,source=/dev/disk/by-id/google-secrets,destination=/secrets,sealed-key=<base64>
+//
+// The type defaults to bind. Tmpfs mounts require a size, in bytes or with a
+// k, m or g (binary) suffix. Luks mounts require either a key release
+// endpoint and a wrapped key or secret name, or a sealed key.
+func parseMounts(val string) ([]Mount, error) {
+	var mounts []Mount
+	for _, m := range strings.Split(val, ";") {
+		if strings.TrimSpace(m) == "" {
+			continue
+		}
+		mount, err := parseMount(m)
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts, nil
+}
+
+func parseMount(val string) (Mount, error) {
+	mount := Mount{Type: BindMount}
+	for _, field := range strings.Split(val, ",") {
+		key, value, hasValue := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "type":
+			mount.Type = MountType(value)
+		case "source", "src":
+			mount.Source = value
+		case "destination", "dst", "target":
+			mount.Destination = value
+		case "readonly", "ro":
+			if hasValue && value != "true" {
+				return Mount{}, fmt.Errorf("invalid mount option %q", field)
+			}
+			mount.ReadOnly = true
+		case "size", "tmpfs-size":
+			size, err := parseSize(value)
+			if err != nil {
+				return Mount{}, fmt.Errorf("invalid mount size %q: %v", value, err)
+			}
+			mount.SizeBytes = size
+		case "key-release-endpoint":
+			mount.KeyReleaseEndpoint = value
+		case "wrapped-key":
+			key, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return Mount{}, fmt.Errorf("invalid mount wrapped key: %v", err)
+			}
+			mount.WrappedKey = key
+		case "key-release-secret":
+			mount.KeyReleaseSecret = value
+		case "sealed-key":
+			key, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return Mount{}, fmt.Errorf("invalid mount sealed key: %v", err)
+			}
+			mount.SealedKey = key
+		default:
+			return Mount{}, fmt.Errorf("unknown mount option %q", field)
+		}
+	}
+
+	hasKey := mount.KeyReleaseEndpoint != "" || len(mount.WrappedKey) > 0 || mount.KeyReleaseSecret != "" || len(mount.SealedKey) > 0
+	switch mount.Type {
+	case BindMount:
+		if err := checkPath(mount.Source); err != nil {
+			return Mount{}, fmt.Errorf("invalid mount source: %v", err)
+		}
+		if mount.SizeBytes != 0 {
+			return Mount{}, fmt.Errorf("%s mount cannot have a size", mount.Type)
+		}
+		if hasKey {
+			return Mount{}, fmt.Errorf("%s mount cannot have a key", mount.Type)
+		}
+	case TmpfsMount:
+		if mount.Source != "" {
+			return Mount{}, fmt.Errorf("%s mount cannot have a source", mount.Type)
+		}
+		if mount.SizeBytes == 0 {
+			return Mount{}, fmt.Errorf("%s mount requires a size", mount.Type)
+		}
+		if mount.ReadOnly {
+			return Mount{}, fmt.Errorf("%s mount cannot be read-only", mount.Type)
+		}
+		if hasKey {
+			return Mount{}, fmt.Errorf("%s mount cannot have a key", mount.Type)
+		}
+	case LuksMount:
+		if err := checkPath(mount.Source); err != nil {
+			return Mount{}, fmt.Errorf("invalid mount source: %v", err)
+		}
+		if mount.SizeBytes != 0 {
+			return Mount{}, fmt.Errorf("%s mount cannot have a size", mount.Type)
+		}
+		if err := checkMountKey(mount); err != nil {
+			return Mount{}, err
+		}
+	default:
+		return Mount{}, fmt.Errorf("unsupported mount type %q (must be one of [%s, %s, %s])", mount.Type, BindMount, TmpfsMount, LuksMount)
+	}
+	if err := checkPath(mount.Destination); err != nil {
+		return Mount{}, fmt.Errorf("invalid mount destination: %v", err)
+	}
+	return mount, nil
+}
+
+// checkMountKey checks a LuksMount has either a key release endpoint and a
+// wrapped key or secret name, or a sealed key.
+func checkMountKey(mount Mount) error {
+	if len(mount.SealedKey) > 0 {
+		if mount.KeyReleaseEndpoint != "" || len(mount.WrappedKey) > 0 || mount.KeyReleaseSecret != "" {
+			return fmt.Errorf("%s mount cannot have both a sealed key and a released key", mount.Type)
+		}
+		return nil
+	}
+	if len(mount.WrappedKey) > 0 && mount.KeyReleaseSecret != "" {
+		return fmt.Errorf("%s mount cannot have both a wrapped-key and a key-release-secret", mount.Type)
+	}
+	if mount.KeyReleaseEndpoint == "" || (len(mount.WrappedKey) == 0 && mount.KeyReleaseSecret == "") {
+		return fmt.Errorf("%s mount requires a key-release-endpoint and a wrapped-key or key-release-secret, or a sealed-key", mount.Type)
+	}
+	endpoint, err := url.Parse(mount.KeyReleaseEndpoint)
+	if err != nil {
+		return fmt.Errorf("invalid mount key release endpoint: %v", err)
+	}
+	if endpoint.Scheme != "https" && endpoint.Scheme != "http" {
+		return fmt.Errorf("invalid mount key release endpoint %q, must be an http(s) URL", mount.KeyReleaseEndpoint)
+	}
+	return nil
+}
+
+// parseSize parses a size in bytes, with an optional k, m or g suffix.
+func parseSize(val string) (uint64, error) {
+	multipliers := map[string]uint64{"k": 1 << 10, "m": 1 << 20, "g": 1 << 30}
+	multiplier := uint64(1)
+	for suffix, m := range multipliers {
+		if strings.HasSuffix(strings.ToLower(val), suffix) {
+			multiplier = m
+			val = val[:len(val)-len(suffix)]
+		}
+	}
+	size, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if size > math.MaxUint64/multiplier {
+		return 0, fmt.Errorf("size overflows")
+	}
+	return size * multiplier, nil
+}
+
+// checkPath checks the path is absolute and clean.
+func checkPath(p string) error {
+	if !path.IsAbs(p) {
+		return fmt.Errorf("path %q is not absolute", p)
+	}
+	if path.Clean(p) != p {
+		return fmt.Errorf("path %q is not clean, use %q", p, path.Clean(p))
+	}
+	return nil
+}
+
+// isUnder returns whether the path is the directory or under it.
+func isUnder(p string, dir string) bool {
+	return p == dir || dir == "/" || strings.HasPrefix(p, dir+"/")
+}
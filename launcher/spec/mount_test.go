@@ -0,0 +1,154 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseMounts(t *testing.T) {
+	testCases := []struct {
+		testName string
+		val      string
+		want     []Mount
+	}{
+		{
+			"bind mount",
+			"type=bind,source=/mnt/disks/data,destination=/data",
+			[]Mount{{Type: BindMount, Source: "/mnt/disks/data", Destination: "/data"}},
+		},
+		{
+			"default type and short options",
+			"src=/mnt/disks/data,dst=/data,ro",
+			[]Mount{{Type: BindMount, Source: "/mnt/disks/data", Destination: "/data", ReadOnly: true}},
+		},
+		{
+			"tmpfs mount",
+			"type=tmpfs,destination=/scratch,size=1G",
+			[]Mount{{Type: TmpfsMount, Destination: "/scratch", SizeBytes: 1 << 30}},
+		},
+		{
+			"luks mount with a wrapped key",
+			"type=luks,source=/dev/disk/by-id/google-secrets,destination=/secrets,ro,key-release-endpoint=https://kr.example.com/v1/release,wrapped-key=a2V5Lw==",
+			[]Mount{{
+				Type:               LuksMount,
+				Source:             "/dev/disk/by-id/google-secrets",
+				Destination:        "/secrets",
+				ReadOnly:           true,
+				KeyReleaseEndpoint: "https://kr.example.com/v1/release",
+				WrappedKey:         []byte("key/"),
+			}},
+		},
+		{
+			"luks mount with a key release secret",
+			"type=luks,source=/dev/sdb,destination=/secrets,key-release-endpoint=https://kr.example.com,key-release-secret=disk-key",
+			[]Mount{{Type: LuksMount, Source: "/dev/sdb", Destination: "/secrets", KeyReleaseEndpoint: "https://kr.example.com", KeyReleaseSecret: "disk-key"}},
+		},
+		{
+			"luks mount with a sealed key",
+			"type=luks,source=/dev/sdb,destination=/secrets,sealed-key=c2VhbGVk",
+			[]Mount{{Type: LuksMount, Source: "/dev/sdb", Destination: "/secrets", SealedKey: []byte("sealed")}},
+		},
+		{
+			"multiple mounts",
+			"source=/a,target=/b,readonly=true; ;source=/c,destination=/d;type=tmpfs,dst=/e,tmpfs-size=4096",
+			[]Mount{
+				{Type: BindMount, Source: "/a", Destination: "/b", ReadOnly: true},
+				{Type: BindMount, Source: "/c", Destination: "/d"},
+				{Type: TmpfsMount, Destination: "/e", SizeBytes: 4096},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			got, err := parseMounts(testCase.val)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(testCase.want, got); diff != "" {
+				t.Errorf("parseMounts got unexpected mounts (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseMountsBadInput(t *testing.T) {
+	for _, val := range []string{
+		"type=volume,source=/a,destination=/b",
+		"source=/a",
+		"destination=/b",
+		"source=a,destination=/b",
+		"source=/a/../b,destination=/b",
+		"source=/a,destination=/b/",
+		"source=/a,destination=/b,readonly=false",
+		"source=/a,destination=/b,propagation=shared",
+		"source=/a,destination=/b,size=1m",
+		"type=tmpfs,destination=/b",
+		"type=tmpfs,source=/a,destination=/b,size=1m",
+		"type=tmpfs,destination=/b,size=1m,ro",
+		"type=tmpfs,destination=/b,size=lots",
+		"type=tmpfs,destination=/b,size=17179869184g",
+		"source=/a,destination=/b,sealed-key=c2VhbGVk",
+		"type=tmpfs,destination=/b,size=1m,sealed-key=c2VhbGVk",
+		"type=luks,source=/dev/sdb,destination=/b",
+		"type=luks,destination=/b,sealed-key=c2VhbGVk",
+		"type=luks,source=/dev/sdb,destination=/b,size=1m,sealed-key=c2VhbGVk",
+		"type=luks,source=/dev/sdb,destination=/b,sealed-key=!",
+		"type=luks,source=/dev/sdb,destination=/b,wrapped-key=a2V5",
+		"type=luks,source=/dev/sdb,destination=/b,key-release-endpoint=https://kr.example.com",
+		"type=luks,source=/dev/sdb,destination=/b,key-release-endpoint=file:///key,wrapped-key=a2V5",
+		"type=luks,source=/dev/sdb,destination=/b,key-release-endpoint=https://kr.example.com,wrapped-key=a2V5,sealed-key=c2VhbGVk",
+		"type=luks,source=/dev/sdb,destination=/b,key-release-secret=disk-key",
+		"type=luks,source=/dev/sdb,destination=/b,key-release-endpoint=https://kr.example.com,wrapped-key=a2V5,key-release-secret=disk-key",
+		"type=luks,source=/dev/sdb,destination=/b,key-release-secret=disk-key,sealed-key=c2VhbGVk",
+		"source=/a,destination=/b,key-release-secret=disk-key",
+	} {
+		if _, err := parseMounts(val); err == nil {
+			t.Errorf("parseMounts(%q) succeeded", val)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	testCases := []struct {
+		val  string
+		want uint64
+	}{
+		{"4096", 4096},
+		{"4k", 4 << 10},
+		{"64M", 64 << 20},
+		{"2g", 2 << 30},
+	}
+	for _, testCase := range testCases {
+		got, err := parseSize(testCase.val)
+		if err != nil {
+			t.Errorf("parseSize(%q) failed: %v", testCase.val, err)
+		} else if got != testCase.want {
+			t.Errorf("parseSize(%q) got %d, want %d", testCase.val, got, testCase.want)
+		}
+	}
+	for _, val := range []string{"", "g", "1t", "-1", "1.5g"} {
+		if _, err := parseSize(val); err == nil {
+			t.Errorf("parseSize(%q) succeeded", val)
+		}
+	}
+}
+
+func TestIsUnder(t *testing.T) {
+	testCases := []struct {
+		path string
+		dir  string
+		want bool
+	}{
+		{"/var/log", "/var/log", true},
+		{"/var/log/app", "/var/log", true},
+		{"/var/logs", "/var/log", false},
+		{"/var", "/var/log", false},
+		{"/etc", "/", true},
+	}
+	for _, testCase := range testCases {
+		if got := isUnder(testCase.path, testCase.dir); got != testCase.want {
+			t.Errorf("isUnder(%q, %q) got %v, want %v", testCase.path, testCase.dir, got, testCase.want)
+		}
+	}
+}
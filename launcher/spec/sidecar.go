@@ -0,0 +1,64 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Sidecar is a container launched next to the main workload container, such
+// as a logging agent or a proxy.
+type Sidecar struct {
+	// Name identifies the sidecar in the container IDs and logs.
+	Name     string
+	ImageRef string
+	// Cmd and Envs override the command and environment of the image, like
+	// the Cmd and Envs of the LaunchSpec.
+	Cmd  []string
+	Envs []EnvVar
+}
+
+// sidecarJSON is the JSON format of a Sidecar in the launch spec.
+type sidecarJSON struct {
+	Name  string            `json:"name"`
+	Image string            `json:"image"`
+	Cmd   []string          `json:"cmd"`
+	Env   map[string]string `json:"env"`
+}
+
+// parseSidecars parses a JSON list of sidecars, e.g.:
+//
+//	[{"name": "envoy", "image": "docker.io/envoyproxy/envoy:v1.27.0", "cmd": ["-c", "/etc/envoy/envoy.yaml"], "env": {"ENVOY_UID": "0"}}]
+//
+// The name and image are required, and the names must be unique.
+func parseSidecars(val string) ([]Sidecar, error) {
+	var sidecarNameRegexp = regexp.MustCompile("^[a-z0-9][a-z0-9-]{0,31}$")
+	var list []sidecarJSON
+	if err := json.Unmarshal([]byte(val), &list); err != nil {
+		return nil, err
+	}
+	var sidecars []Sidecar
+	names := make(map[string]bool)
+	for _, s := range list {
+		if !sidecarNameRegexp.MatchString(s.Name) {
+			return nil, fmt.Errorf("malformed sidecar name %q, must match %s", s.Name, sidecarNameRegexp)
+		}
+		if names[s.Name] {
+			return nil, fmt.Errorf("duplicate sidecar name %q", s.Name)
+		}
+		names[s.Name] = true
+		if s.Image == "" {
+			return nil, fmt.Errorf("sidecar %q has no image", s.Name)
+		}
+
+		sidecar := Sidecar{Name: s.Name, ImageRef: s.Image, Cmd: s.Cmd}
+		for name, value := range s.Env {
+			sidecar.Envs = append(sidecar.Envs, EnvVar{name, value})
+		}
+		// Sort the env vars, so they are measured in a deterministic order.
+		sort.Slice(sidecar.Envs, func(i, j int) bool { return sidecar.Envs[i].Name < sidecar.Envs[j].Name })
+		sidecars = append(sidecars, sidecar)
+	}
+	return sidecars, nil
+}
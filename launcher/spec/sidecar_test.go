@@ -0,0 +1,50 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSidecars(t *testing.T) {
+	val := `[
+		{"name": "envoy", "image": "docker.io/envoyproxy/envoy:v1.27.0", "cmd": ["-c", "/etc/envoy.yaml"], "env": {"ENVOY_UID": "0", "ENVOY_GID": "0"}},
+		{"name": "log-agent", "image": "docker.io/fluent/fluent-bit:2.1"}
+	]`
+	want := []Sidecar{
+		{
+			Name:     "envoy",
+			ImageRef: "docker.io/envoyproxy/envoy:v1.27.0",
+			Cmd:      []string{"-c", "/etc/envoy.yaml"},
+			Envs:     []EnvVar{{"ENVOY_GID", "0"}, {"ENVOY_UID", "0"}},
+		},
+		{Name: "log-agent", ImageRef: "docker.io/fluent/fluent-bit:2.1"},
+	}
+	got, err := parseSidecars(val)
+	if err != nil {
+		t.Fatalf("parseSidecars() failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseSidecars() got unexpected sidecars (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseSidecarsBadInput(t *testing.T) {
+	testCases := []struct {
+		testName string
+		val      string
+	}{
+		{"not a list", `{"name": "envoy", "image": "envoy"}`},
+		{"no name", `[{"image": "envoy"}]`},
+		{"malformed name", `[{"name": "Envoy_Proxy", "image": "envoy"}]`},
+		{"no image", `[{"name": "envoy"}]`},
+		{"duplicate name", `[{"name": "envoy", "image": "envoy"}, {"name": "envoy", "image": "envoy"}]`},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			if _, err := parseSidecars(testCase.val); err == nil {
+				t.Errorf("parseSidecars(%q) succeeded", testCase.val)
+			}
+		})
+	}
+}
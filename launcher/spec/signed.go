@@ -0,0 +1,158 @@
+package spec
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/cel"
+)
+
+// signedLaunchSpecKey is the instance attribute holding a signed launch spec
+// bundle.
+const signedLaunchSpecKey = "tee-signed-launch-spec"
+
+// signedLaunchSpecAlgs are the JWS algorithms accepted for signed launch spec
+// bundles.
+var signedLaunchSpecAlgs = map[string]bool{
+	"RS256": true, "RS384": true, "RS512": true,
+	"PS256": true, "PS384": true, "PS512": true,
+	"ES256": true, "ES384": true, "ES512": true,
+}
+
+// signedSource is a Source whose instance attributes were read from a signed
+// launch spec bundle. The project and region are read from the unsigned
+// source.
+type signedSource struct {
+	Source
+	attributes []byte
+	// signer is the identity of the key that signed the bundle, see
+	// cel.FormatLaunchSpecSigner.
+	signer string
+}
+
+// NewSignedSource returns a Source reading the instance attributes from a
+// signed launch spec bundle, found in the tee-signed-launch-spec attribute of
+// the unsigned source. It returns an error if the bundle is missing, or is
+// not signed by one of the keys.
+//
+// The bundle is a JWS in compact serialization, whose payload is a JSON
+// object of the instance attributes:
+//
+//	{"tee-image-reference": "docker.io/library/hello-world:latest"}
+//
+// All other tee-* attributes of the unsigned source are ignored, except for
+// the project and region.
+func NewSignedSource(source Source, keys []crypto.PublicKey) (Source, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no launch spec signing keys")
+	}
+	data, err := source.InstanceAttributes()
+	if err != nil {
+		return nil, err
+	}
+	var unsigned map[string]string
+	if err := json.Unmarshal(data, &unsigned); err != nil {
+		return nil, err
+	}
+	bundle, ok := unsigned[signedLaunchSpecKey]
+	if !ok {
+		return nil, fmt.Errorf("%s is not specified, but the launch spec must be signed", signedLaunchSpecKey)
+	}
+
+	payload, key, err := verifyBundle(bundle, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify %s: %v", signedLaunchSpecKey, err)
+	}
+	var attributes map[string]string
+	if err := json.Unmarshal(payload, &attributes); err != nil {
+		return nil, fmt.Errorf("failed to parse %s payload: %v", signedLaunchSpecKey, err)
+	}
+	if _, ok := attributes[signedLaunchSpecKey]; ok {
+		return nil, fmt.Errorf("%s payload cannot contain %s", signedLaunchSpecKey, signedLaunchSpecKey)
+	}
+	signer, err := cel.FormatLaunchSpecSigner(key)
+	if err != nil {
+		return nil, err
+	}
+	return &signedSource{Source: source, attributes: payload, signer: signer}, nil
+}
+
+func (s *signedSource) InstanceAttributes() ([]byte, error) {
+	return s.attributes, nil
+}
+
+func (s *signedSource) LaunchSpecSigner() string {
+	return s.signer
+}
+
+// verifyBundle verifies the JWS bundle was signed by one of the keys, and
+// returns its payload and the key that signed it.
+func verifyBundle(bundle string, keys []crypto.PublicKey) ([]byte, crypto.PublicKey, error) {
+	parts := strings.Split(bundle, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("malformed JWS, must have 3 parts")
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("malformed JWS header: %v", err)
+	}
+	var header struct {
+		Alg  string   `json:"alg"`
+		Crit []string `json:"crit"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, nil, fmt.Errorf("malformed JWS header: %v", err)
+	}
+	if len(header.Crit) != 0 {
+		return nil, nil, fmt.Errorf("unsupported critical JWS header parameters %v", header.Crit)
+	}
+	if !signedLaunchSpecAlgs[header.Alg] {
+		return nil, nil, fmt.Errorf("unsupported JWS algorithm %q", header.Alg)
+	}
+	method := jwt.GetSigningMethod(header.Alg)
+
+	signingString := parts[0] + "." + parts[1]
+	for _, key := range keys {
+		if err := method.Verify(signingString, parts[2], key); err != nil {
+			continue
+		}
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("malformed JWS payload: %v", err)
+		}
+		return payload, key, nil
+	}
+	return nil, nil, errors.New("not signed by any of the launch spec signing keys")
+}
+
+// ParsePublicKeys parses the PEM-encoded PKIX public keys ("PUBLIC KEY"
+// blocks) pinned to verify signed launch specs.
+func ParsePublicKeys(pemData []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("unexpected PEM block type %q, want PUBLIC KEY", block.Type)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM public keys found")
+	}
+	return keys, nil
+}
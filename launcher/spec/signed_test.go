@@ -0,0 +1,200 @@
+package spec
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+)
+
+// signBundle returns a signed launch spec bundle with the payload.
+func signBundle(t *testing.T, method jwt.SigningMethod, key crypto.Signer, payload string) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": method.Alg()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingString := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	signature, err := method.Sign(signingString, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingString + "." + signature
+}
+
+// bundleSource returns an unsigned source with the bundle, and extra
+// attributes.
+func bundleSource(bundle string, extra map[string]string) Source {
+	attributes := attributesSource{signedLaunchSpecKey: bundle}
+	for k, v := range extra {
+		attributes[k] = v
+	}
+	return attributes
+}
+
+const signedPayload = `{
+	"tee-image-reference":"docker.io/library/hello-world:latest",
+	"tee-restart-policy":"Always",
+	"tee-attestation-service-type":"self-hosted",
+	"tee-attestation-service-endpoint":"https://verifier.example.com"
+}`
+
+func TestSignedSource(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []crypto.PublicKey{ecKey.Public(), rsaKey.Public()}
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		key    crypto.Signer
+	}{
+		{"ES256", jwt.SigningMethodES256, ecKey},
+		{"RS256", jwt.SigningMethodRS256, rsaKey},
+		{"PS256", jwt.SigningMethodPS256, rsaKey},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Unsigned attributes are ignored, except for the project and
+			// region.
+			source := bundleSource(signBundle(t, tc.method, tc.key, signedPayload), map[string]string{
+				imageRefKey:  "docker.io/library/unsigned:latest",
+				cmdKey:       `["--unsigned"]`,
+				projectIDKey: "test-project",
+				regionKey:    "us-central1",
+			})
+			signed, err := NewSignedSource(source, keys)
+			if err != nil {
+				t.Fatalf("NewSignedSource() failed: %v", err)
+			}
+			spec, err := GetLaunchSpec(signed)
+			if err != nil {
+				t.Fatalf("GetLaunchSpec() failed: %v", err)
+			}
+
+			signer, err := cel.FormatLaunchSpecSigner(tc.key.Public())
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := LaunchSpec{
+				ImageRef:               "docker.io/library/hello-world:latest",
+				RestartPolicy:          Always,
+				AttestationServiceAddr: "https://verifier.example.com",
+				AttestationServiceType: SelfHostedVerifier,
+				HostNetwork:            true,
+				StopGracePeriod:        defaultStopGracePeriod,
+				ProjectID:              "test-project",
+				Region:                 "us-central1",
+				Hardened:               spec.Hardened,
+				LaunchSpecSigner:       signer,
+			}
+			if diff := cmp.Diff(want, spec); diff != "" {
+				t.Errorf("unexpected LaunchSpec (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSignedSourceRejected(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := signBundle(t, jwt.SigningMethodES256, key, signedPayload)
+	parts := strings.Split(bundle, ".")
+	tamperedPayload := base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(signedPayload, "hello-world", "evil", 1)))
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	critHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256","crit":["exp"]}`))
+	critSigningString := critHeader + "." + parts[1]
+	critSignature, err := jwt.SigningMethodES256.Sign(critSigningString, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		source Source
+	}{
+		{"Unsigned", attributesSource{imageRefKey: "docker.io/library/hello-world:latest"}},
+		{"WrongKey", bundleSource(signBundle(t, jwt.SigningMethodES256, otherKey, signedPayload), nil)},
+		{"TamperedPayload", bundleSource(parts[0]+"."+tamperedPayload+"."+parts[2], nil)},
+		{"AlgNone", bundleSource(noneHeader+"."+parts[1]+".", nil)},
+		{"CriticalHeader", bundleSource(critSigningString+"."+critSignature, nil)},
+		{"Malformed", bundleSource("not-a-jws", nil)},
+		{"NotAnObject", bundleSource(signBundle(t, jwt.SigningMethodES256, key, `["tee-image-reference"]`), nil)},
+		{"NestedBundle", bundleSource(signBundle(t, jwt.SigningMethodES256, key, `{"tee-signed-launch-spec":"a.b.c"}`), nil)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewSignedSource(tc.source, []crypto.PublicKey{key.Public()}); err == nil {
+				t.Error("NewSignedSource() succeeded")
+			}
+		})
+	}
+
+	if _, err := NewSignedSource(bundleSource(bundle, nil), nil); err == nil {
+		t.Error("NewSignedSource() without keys succeeded")
+	}
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pemData []byte
+	for _, pub := range []crypto.PublicKey{ecKey.Public(), rsaKey.Public()} {
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pemData = append(pemData, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})...)
+	}
+
+	keys, err := ParsePublicKeys(pemData)
+	if err != nil {
+		t.Fatalf("ParsePublicKeys() failed: %v", err)
+	}
+	if len(keys) != 2 || !ecKey.PublicKey.Equal(keys[0]) || !rsaKey.PublicKey.Equal(keys[1]) {
+		t.Errorf("ParsePublicKeys() got %v, want the EC and RSA keys", keys)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{
+		"Empty":      nil,
+		"PrivateKey": pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		"Malformed":  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("key")}),
+	} {
+		if _, err := ParsePublicKeys(data); err == nil {
+			t.Errorf("ParsePublicKeys() with %s data succeeded", name)
+		}
+	}
+}
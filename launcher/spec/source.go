@@ -0,0 +1,132 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/compute/metadata"
+)
+
+// Source provides the operator's launch configuration: the instance
+// attributes (the tee-* keys), and the project and region the instance runs
+// in.
+type Source interface {
+	// InstanceAttributes returns the instance attributes as a JSON object of
+	// strings.
+	InstanceAttributes() ([]byte, error)
+	// ProjectID and Region return the project and region of the instance,
+	// or an empty string if the source does not know them.
+	ProjectID() (string, error)
+	Region() (string, error)
+	// LaunchSpecSigner returns the identity of the key that signed the
+	// instance attributes (see cel.FormatLaunchSpecSigner), or an empty
+	// string if they are not signed.
+	LaunchSpecSigner() string
+}
+
+// Keys of the project and region, for sources without a metadata server.
+const (
+	projectIDKey = "tee-project-id"
+	regionKey    = "tee-region"
+)
+
+// cmdlinePrefix is the prefix of the kernel command line arguments read by
+// the CmdlineSource.
+const cmdlinePrefix = "confidential-space."
+
+type mdsSource struct {
+	client *metadata.Client
+}
+
+// NewMDSSource returns a Source reading the GCE instance custom metadata
+// from the metadata server. The client can be pointed at a local stand-in
+// for the metadata server with the GCE_METADATA_HOST environment variable.
+func NewMDSSource(client *metadata.Client) Source {
+	return &mdsSource{client}
+}
+
+func (s *mdsSource) InstanceAttributes() ([]byte, error) {
+	data, err := s.client.Get(instanceAttributesQuery)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+func (s *mdsSource) ProjectID() (string, error) {
+	projectID, err := s.client.ProjectID()
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve projectID from MDS: %v", err)
+	}
+	return projectID, nil
+}
+
+func (s *mdsSource) Region() (string, error) {
+	return getRegion(s.client)
+}
+
+func (s *mdsSource) LaunchSpecSigner() string {
+	return ""
+}
+
+// attributesSource is a Source with fixed attributes.
+type attributesSource map[string]string
+
+func (s attributesSource) InstanceAttributes() ([]byte, error) {
+	return json.Marshal(map[string]string(s))
+}
+
+func (s attributesSource) ProjectID() (string, error) {
+	return s[projectIDKey], nil
+}
+
+func (s attributesSource) Region() (string, error) {
+	return s[regionKey], nil
+}
+
+func (s attributesSource) LaunchSpecSigner() string {
+	return ""
+}
+
+// NewFileSource returns a Source reading the instance attributes from a local
+// JSON file, formatted like the custom metadata:
+//
+//	{"tee-image-reference": "docker.io/library/hello-world:latest"}
+//
+// The project and region can be set with the tee-project-id and tee-region
+// keys.
+func NewFileSource(path string) (Source, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var attributes map[string]string
+	if err := json.Unmarshal(data, &attributes); err != nil {
+		return nil, fmt.Errorf("failed to parse launch spec file %s: %v", path, err)
+	}
+	return attributesSource(attributes), nil
+}
+
+// NewCmdlineSource returns a Source reading the instance attributes from the
+// kernel command line, with each attribute set as an argument prefixed with
+// "confidential-space.":
+//
+//	confidential-space.tee-image-reference=docker.io/library/hello-world:latest
+//
+// As arguments are separated by spaces, values cannot contain spaces.
+func NewCmdlineSource(kernelCmd string) Source {
+	attributes := make(map[string]string)
+	for _, arg := range strings.Fields(kernelCmd) {
+		if !strings.HasPrefix(arg, cmdlinePrefix) {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(arg, cmdlinePrefix), "=")
+		if !ok || !strings.HasPrefix(key, "tee-") {
+			continue
+		}
+		attributes[key] = value
+	}
+	return attributesSource(attributes)
+}
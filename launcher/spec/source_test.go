@@ -0,0 +1,145 @@
+package spec
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/compute/metadata"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testAttributes = `{
+	"tee-image-reference":"docker.io/library/hello-world:latest",
+	"tee-restart-policy":"Never"
+}`
+
+// startFakeMDS serves the paths of the metadata server in responses, and
+// points the metadata clients at it.
+func startFakeMDS(t *testing.T, responses map[string]string) {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing Metadata-Flavor header", http.StatusForbidden)
+			return
+		}
+		resp, ok := responses[strings.TrimPrefix(r.URL.RequestURI(), "/computeMetadata/v1/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(resp))
+	}))
+	t.Cleanup(ts.Close)
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(ts.URL, "http://"))
+}
+
+func TestMDSSource(t *testing.T) {
+	startFakeMDS(t, map[string]string{
+		instanceAttributesQuery: testAttributes,
+		"project/project-id":    "test-project",
+		"instance/zone":         "projects/123/zones/us-central1-a",
+	})
+
+	spec, err := GetLaunchSpec(NewMDSSource(metadata.NewClient(nil)))
+	if err != nil {
+		t.Fatalf("GetLaunchSpec() failed: %v", err)
+	}
+	if spec.ImageRef != "docker.io/library/hello-world:latest" || spec.RestartPolicy != Never {
+		t.Errorf("got image %q and restart policy %q", spec.ImageRef, spec.RestartPolicy)
+	}
+	if spec.ProjectID != "test-project" || spec.Region != "us-central1" {
+		t.Errorf("got project %q and region %q, want test-project and us-central1", spec.ProjectID, spec.Region)
+	}
+}
+
+func TestMDSSourceUnavailable(t *testing.T) {
+	startFakeMDS(t, map[string]string{})
+	if _, err := GetLaunchSpec(NewMDSSource(metadata.NewClient(nil))); err == nil {
+		t.Error("GetLaunchSpec() succeeded without instance attributes")
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "launch_spec.json")
+	data := `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-project-id":"test-project",
+		"tee-region":"us-central1"
+	}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := NewFileSource(path)
+	if err != nil {
+		t.Fatalf("NewFileSource() failed: %v", err)
+	}
+	spec, err := GetLaunchSpec(source)
+	if err != nil {
+		t.Fatalf("GetLaunchSpec() failed: %v", err)
+	}
+	want := LaunchSpec{
+		ImageRef:               "docker.io/library/hello-world:latest",
+		RestartPolicy:          Never,
+		AttestationServiceType: GoogleVerifier,
+		HostNetwork:            true,
+		StopGracePeriod:        defaultStopGracePeriod,
+		ProjectID:              "test-project",
+		Region:                 "us-central1",
+		Hardened:               spec.Hardened,
+	}
+	if diff := cmp.Diff(want, spec); diff != "" {
+		t.Errorf("unexpected LaunchSpec (-want +got):\n%s", diff)
+	}
+}
+
+func TestFileSourceInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewFileSource(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("NewFileSource() succeeded with a missing file")
+	}
+	path := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(path, []byte(`["tee-image-reference"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileSource(path); err == nil {
+		t.Error("NewFileSource() succeeded with an invalid file")
+	}
+}
+
+func TestCmdlineSource(t *testing.T) {
+	kernelCmd := "BOOT_IMAGE=/vmlinuz root=/dev/sda1 confidential-space.hardened=true " +
+		"confidential-space.tee-image-reference=docker.io/library/hello-world:latest " +
+		"confidential-space.tee-attestation-service-type=self-hosted " +
+		"confidential-space.tee-attestation-service-endpoint=https://verifier.example.com " +
+		"confidential-space.tee-env-foo=bar=baz confidential-space.other=value"
+
+	spec, err := GetLaunchSpec(NewCmdlineSource(kernelCmd))
+	if err != nil {
+		t.Fatalf("GetLaunchSpec() failed: %v", err)
+	}
+	want := LaunchSpec{
+		ImageRef:               "docker.io/library/hello-world:latest",
+		RestartPolicy:          Never,
+		Envs:                   []EnvVar{{"foo", "bar=baz"}},
+		AttestationServiceType: SelfHostedVerifier,
+		HostNetwork:            true,
+		StopGracePeriod:        defaultStopGracePeriod,
+		AttestationServiceAddr: "https://verifier.example.com",
+		Hardened:               spec.Hardened,
+	}
+	if diff := cmp.Diff(want, spec); diff != "" {
+		t.Errorf("unexpected LaunchSpec (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetLaunchSpecGoogleVerifierRequiresProjectAndRegion(t *testing.T) {
+	source := NewCmdlineSource("confidential-space.tee-image-reference=docker.io/library/hello-world:latest")
+	if _, err := GetLaunchSpec(source); err == nil {
+		t.Error("GetLaunchSpec() succeeded without a project and region for the Google verifier")
+	}
+}
@@ -0,0 +1,212 @@
+// Package teeserver implements a server exposing the launcher's attestation
+// agent to the workload container over a Unix domain socket.
+package teeserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	"google.golang.org/protobuf/proto"
+)
+
+// HTTP paths served to the workload.
+const (
+	// MeasurePath measures an event, see MeasureRequest.
+	MeasurePath = "/v1/measure"
+	// TokenPath returns a fresh attestation token, see TokenRequest.
+	TokenPath = "/v1/token"
+	// EvidencePath returns raw attestation evidence, see EvidenceRequest.
+	EvidencePath = "/v1/evidence"
+)
+
+// Limits on the nonces of a TokenRequest.
+const (
+	maxNonces      = 6
+	minNonceLength = 8
+	maxNonceLength = 88
+	// The evidence nonce is quoted by the TPM as qualifying data, which is
+	// limited to the size of a digest.
+	maxEvidenceNonceLength = 64
+)
+
+// maxRequestSize limits the size of a request body.
+const maxRequestSize = 1 << 20
+
+// MeasureRequest is the JSON request body to MeasurePath. The measurement is
+// appended to the COS eventlog as a cel.WorkloadMeasurementType event.
+type MeasureRequest struct {
+	// Type of the measurement, see cel.FormatWorkloadMeasurement.
+	Type string `json:"type"`
+	// Content of the measurement, base64 encoded in JSON.
+	Content []byte `json:"content"`
+}
+
+// TokenRequest is the JSON request body to TokenPath. The response body is
+// the claims token returned by the verifier for a fresh attestation. Verifiers
+// which cannot customize the token, like the default Google Cloud Attestation
+// REST API, answer requests with an audience or nonces with 501 Not
+// Implemented.
+type TokenRequest struct {
+	// Audience of the token. If empty, the verifier's default audience is
+	// used.
+	Audience string `json:"audience"`
+	// Nonces bound into the token, so a relying party can check its
+	// freshness. At most 6 nonces of 8 to 88 bytes each are allowed.
+	Nonces []string `json:"nonces"`
+}
+
+// EvidenceRequest is the JSON request body to EvidencePath. The response body
+// is the binary serialized attest.Attestation over the nonce, containing the
+// TCG and COS eventlogs and the AK certificate chain, to be verified with
+// server.VerifyAttestation.
+type EvidenceRequest struct {
+	// Nonce quoted by the TPM, base64 encoded in JSON. It must be between 8
+	// and 64 bytes.
+	Nonce []byte `json:"nonce"`
+}
+
+// TeeServer serves the workload API on a Unix domain socket.
+type TeeServer struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// New creates a TeeServer listening on the Unix domain socket at unixSock,
+// replacing any stale socket left by a previous launcher run. Events are
+// measured using the given agent, so the server must only be started after
+// the launcher measured the LaunchSeparator.
+func New(unixSock string, a agent.AttestationAgent) (*TeeServer, error) {
+	if err := os.Remove(unixSock); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", unixSock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %v: %v", unixSock, err)
+	}
+	// The workload may not run as root, and can only reach the socket
+	// through the container mount.
+	if err := os.Chmod(unixSock, 0666); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(MeasurePath, measureHandler(a))
+	mux.HandleFunc(TokenPath, tokenHandler(a))
+	mux.HandleFunc(EvidencePath, evidenceHandler(a))
+	return &TeeServer{listener: listener, server: &http.Server{Handler: mux}}, nil
+}
+
+// Serve serves requests until Shutdown is called. It always returns a non-nil
+// error, which is http.ErrServerClosed after Shutdown.
+func (s *TeeServer) Serve() error {
+	return s.server.Serve(s.listener)
+}
+
+// Shutdown stops the server, waiting for in-flight requests to complete.
+func (s *TeeServer) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// decodePost decodes the JSON body of a POST request into req. On failure, it
+// writes the error response and returns false.
+func decodePost(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode request: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func measureHandler(a agent.AttestationAgent) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req MeasureRequest
+		if !decodePost(w, r, &req) {
+			return
+		}
+		content, err := cel.FormatWorkloadMeasurement(req.Type, req.Content)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := a.MeasureEvent(cel.CosTlv{EventType: cel.WorkloadMeasurementType, EventContent: content}); err != nil {
+			http.Error(w, fmt.Sprintf("failed to measure event: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func tokenHandler(a agent.AttestationAgent) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req TokenRequest
+		if !decodePost(w, r, &req) {
+			return
+		}
+		if err := validateNonces(req.Nonces); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		token, err := a.Attest(r.Context(), agent.AttestAgentOpts{Aud: req.Audience, Nonces: req.Nonces})
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, verifier.ErrTokenOptionsUnsupported) {
+				status = http.StatusNotImplemented
+			}
+			http.Error(w, fmt.Sprintf("failed to get attestation token: %v", err), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/jwt")
+		w.Write(token)
+	}
+}
+
+func evidenceHandler(a agent.AttestationAgent) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req EvidenceRequest
+		if !decodePost(w, r, &req) {
+			return
+		}
+		if len(req.Nonce) < minNonceLength || len(req.Nonce) > maxEvidenceNonceLength {
+			http.Error(w, fmt.Sprintf("nonce has length %d, must be between %d and %d", len(req.Nonce), minNonceLength, maxEvidenceNonceLength), http.StatusBadRequest)
+			return
+		}
+		attestation, err := a.AttestationEvidence(req.Nonce)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to get attestation evidence: %v", err), http.StatusInternalServerError)
+			return
+		}
+		evidence, err := proto.Marshal(attestation)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to marshal attestation evidence: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.Write(evidence)
+	}
+}
+
+func validateNonces(nonces []string) error {
+	if len(nonces) > maxNonces {
+		return fmt.Errorf("got %d nonces, at most %d are allowed", len(nonces), maxNonces)
+	}
+	for _, nonce := range nonces {
+		if len(nonce) < minNonceLength || len(nonce) > maxNonceLength {
+			return fmt.Errorf("nonce %q has length %d, must be between %d and %d", nonce, len(nonce), minNonceLength, maxNonceLength)
+		}
+	}
+	return nil
+}
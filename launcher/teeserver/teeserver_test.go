@@ -0,0 +1,336 @@
+package teeserver
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	"github.com/google/go-tpm-tools/launcher/verifier/fake"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm-tools/server/token"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// recordingClient records the last attestation sent to the verifier, and
+// the nonce it was made with.
+type recordingClient struct {
+	verifier.Client
+	attestation *attestpb.Attestation
+	nonce       []byte
+}
+
+func (c *recordingClient) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	c.attestation = request.Attestation
+	c.nonce = request.Challenge.Nonce
+	return c.Client.VerifyAttestation(ctx, request)
+}
+
+func akPublicKey(t *testing.T, tpm io.ReadWriter) crypto.PublicKey {
+	t.Helper()
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatalf("failed to get AK: %v", err)
+	}
+	defer ak.Close()
+	return ak.PublicKey()
+}
+
+func startServer(t *testing.T, a agent.AttestationAgent) *http.Client {
+	t.Helper()
+	sock := path.Join(t.TempDir(), "teeserver.sock")
+	s, err := New(sock, a)
+	if err != nil {
+		t.Fatalf("failed to create TeeServer: %v", err)
+	}
+	done := make(chan error)
+	go func() { done <- s.Serve() }()
+	t.Cleanup(func() {
+		if err := s.Shutdown(context.Background()); err != nil {
+			t.Error(err)
+		}
+		if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("Serve() returned %v", err)
+		}
+	})
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+}
+
+func measure(t *testing.T, c *http.Client, req MeasureRequest) int {
+	t.Helper()
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Post("http://teeserver"+MeasurePath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestMeasureAppearsInAttestation(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	akPub := akPublicKey(t, tpm)
+	verifierClient := &recordingClient{Client: fake.NewClient(fakeSigner, akPub)}
+	a := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient,
+		func(string) ([][]byte, error) { return nil, nil })
+	if err := a.MeasureEvent(cel.CosTlv{EventType: cel.LaunchSeparatorType}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := startServer(t, a)
+	want := []*attestpb.WorkloadMeasurement{
+		{Type: "model_digest", Content: []byte("sha256:abcd")},
+		{Type: "config", Content: []byte("key: value")},
+	}
+	for _, m := range want {
+		if code := measure(t, c, MeasureRequest{Type: m.Type, Content: m.Content}); code != http.StatusNoContent {
+			t.Fatalf("measure request got status %d, want %d", code, http.StatusNoContent)
+		}
+	}
+
+	if _, err := a.Attest(context.Background(), agent.AttestAgentOpts{}); err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	state, err := server.VerifyAttestation(verifierClient.attestation, server.VerifyOpts{
+		Nonce:      verifierClient.nonce,
+		TrustedAKs: []crypto.PublicKey{akPub},
+	})
+	if err != nil {
+		t.Fatalf("failed to verify attestation: %v", err)
+	}
+	if diff := cmp.Diff(state.GetCos().GetWorkloadMeasurements(), want, protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected workload measurements:\n%v", diff)
+	}
+}
+
+type fakeAgent struct {
+	agent.AttestationAgent
+	events    []cel.Content
+	attested  []agent.AttestAgentOpts
+	attestErr error
+}
+
+func (f *fakeAgent) Attest(_ context.Context, opts agent.AttestAgentOpts) ([]byte, error) {
+	f.attested = append(f.attested, opts)
+	if f.attestErr != nil {
+		return nil, f.attestErr
+	}
+	return []byte("token"), nil
+}
+
+func (f *fakeAgent) MeasureEvent(event cel.Content) error {
+	f.events = append(f.events, event)
+	return nil
+}
+
+func TestMeasureBadRequests(t *testing.T) {
+	a := &fakeAgent{}
+	c := startServer(t, a)
+
+	if code := measure(t, c, MeasureRequest{Type: "bad type", Content: []byte("x")}); code != http.StatusBadRequest {
+		t.Errorf("invalid type got status %d, want %d", code, http.StatusBadRequest)
+	}
+	resp, err := c.Post("http://teeserver"+MeasurePath, "application/json", bytes.NewReader([]byte("{")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("malformed JSON got status %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+	resp, err = c.Get("http://teeserver" + MeasurePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET got status %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+	if len(a.events) != 0 {
+		t.Errorf("bad requests measured %d events", len(a.events))
+	}
+}
+
+func requestToken(t *testing.T, c *http.Client, req TokenRequest) (int, []byte) {
+	t.Helper()
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Post("http://teeserver"+TokenPath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+	token, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, token
+}
+
+func TestTokenWithCustomAudienceAndNonces(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, fake.NewClient(fakeSigner, akPublicKey(t, tpm)),
+		func(string) ([][]byte, error) { return nil, nil })
+	c := startServer(t, a)
+
+	req := TokenRequest{Audience: "https://relying.party", Nonces: []string{"0123456789abcdef", "another-nonce"}}
+	code, claimsToken := requestToken(t, c, req)
+	if code != http.StatusOK {
+		t.Fatalf("token request got status %d (%s), want %d", code, claimsToken, http.StatusOK)
+	}
+	claims := &token.Claims{}
+	if _, err := jwt.ParseWithClaims(string(claimsToken), claims, func(*jwt.Token) (interface{}, error) { return fakeSigner.Public(), nil }); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	if !claims.VerifyAudience(req.Audience, true) {
+		t.Errorf("got audience %v, want %v", claims.Audience, req.Audience)
+	}
+	if diff := cmp.Diff(claims.Nonces, req.Nonces); diff != "" {
+		t.Errorf("unexpected nonces:\n%v", diff)
+	}
+}
+
+func TestTokenOptionsUnsupported(t *testing.T) {
+	c := startServer(t, &fakeAgent{attestErr: fmt.Errorf("v1alpha1 API: %w", verifier.ErrTokenOptionsUnsupported)})
+	if code, _ := requestToken(t, c, TokenRequest{Audience: "https://relying.party"}); code != http.StatusNotImplemented {
+		t.Errorf("token request got status %d, want %d", code, http.StatusNotImplemented)
+	}
+}
+
+func TestTokenBadNonces(t *testing.T) {
+	a := &fakeAgent{}
+	c := startServer(t, a)
+
+	for _, nonces := range [][]string{
+		{"short"},
+		{strings.Repeat("n", maxNonceLength+1)},
+		{"nonce-01", "nonce-02", "nonce-03", "nonce-04", "nonce-05", "nonce-06", "nonce-07"},
+	} {
+		if code, _ := requestToken(t, c, TokenRequest{Nonces: nonces}); code != http.StatusBadRequest {
+			t.Errorf("nonces %v got status %d, want %d", nonces, code, http.StatusBadRequest)
+		}
+	}
+	if len(a.attested) != 0 {
+		t.Errorf("bad requests attested %d times", len(a.attested))
+	}
+
+	if code, token := requestToken(t, c, TokenRequest{}); code != http.StatusOK || string(token) != "token" {
+		t.Errorf("default token request got status %d and token %q", code, token)
+	}
+}
+
+func requestEvidence(t *testing.T, c *http.Client, nonce []byte) (int, []byte) {
+	t.Helper()
+	body, err := json.Marshal(EvidenceRequest{Nonce: nonce})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Post("http://teeserver"+EvidencePath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+	evidence, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, evidence
+}
+
+func TestEvidenceVerifies(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	a := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, nil,
+		func(string) ([][]byte, error) { return nil, nil })
+	if err := a.MeasureEvent(cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.MeasureEvent(cel.CosTlv{EventType: cel.LaunchSeparatorType}); err != nil {
+		t.Fatal(err)
+	}
+	c := startServer(t, a)
+
+	nonce := []byte("relying party nonce")
+	code, evidence := requestEvidence(t, c, nonce)
+	if code != http.StatusOK {
+		t.Fatalf("evidence request got status %d (%s), want %d", code, evidence, http.StatusOK)
+	}
+	attestation := &attestpb.Attestation{}
+	if err := proto.Unmarshal(evidence, attestation); err != nil {
+		t.Fatalf("failed to unmarshal evidence: %v", err)
+	}
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	state, err := server.VerifyAttestation(attestation, server.VerifyOpts{
+		Nonce:      nonce,
+		TrustedAKs: []crypto.PublicKey{ak.PublicKey()},
+	})
+	if err != nil {
+		t.Fatalf("failed to verify evidence: %v", err)
+	}
+	if got := state.GetCos().GetContainer().GetImageReference(); got != "docker.io/bazel/experimental/test:latest" {
+		t.Errorf("got image reference %q from evidence", got)
+	}
+
+	// The evidence must be bound to the nonce.
+	if _, err := server.VerifyAttestation(attestation, server.VerifyOpts{
+		Nonce:      []byte("another nonce"),
+		TrustedAKs: []crypto.PublicKey{ak.PublicKey()},
+	}); err == nil {
+		t.Error("expected evidence to fail verification with another nonce")
+	}
+}
+
+func TestEvidenceBadNonce(t *testing.T) {
+	c := startServer(t, &fakeAgent{})
+	for _, nonce := range [][]byte{nil, []byte("short"), make([]byte, maxEvidenceNonceLength+1)} {
+		if code, _ := requestEvidence(t, c, nonce); code != http.StatusBadRequest {
+			t.Errorf("nonce of length %d got status %d, want %d", len(nonce), code, http.StatusBadRequest)
+		}
+	}
+}
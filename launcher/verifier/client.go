@@ -4,6 +4,7 @@ package verifier
 
 import (
 	"context"
+	"errors"
 
 	attestpb "github.com/google/go-tpm-tools/proto/attest"
 )
@@ -25,13 +26,30 @@ type Challenge struct {
 
 // VerifyAttestationRequest is passed in on VerifyAttestation. It contains the
 // Challenge from CreateChallenge, optional GcpCredentials linked to the
-// attestation, and the Attestation generated from the TPM.
+// attestation, the Attestation generated from the TPM, and optional
+// TokenOptions customizing the claims token.
 type VerifyAttestationRequest struct {
 	Challenge      *Challenge
 	GcpCredentials [][]byte
 	Attestation    *attestpb.Attestation
+	TokenOptions   TokenOptions
 }
 
+// TokenOptions customize the claims token returned by VerifyAttestation. The
+// zero value requests the verifier's default token.
+type TokenOptions struct {
+	// Audience is the "aud" claim of the token. If empty, the verifier's
+	// default audience is used.
+	Audience string
+	// Nonces are caller-supplied values placed in the "eat_nonce" claim of
+	// the token, so a relying party can check the token is fresh.
+	Nonces []string
+}
+
+// ErrTokenOptionsUnsupported is returned by VerifyAttestation for non-empty
+// TokenOptions if the verifier cannot customize its claims token.
+var ErrTokenOptionsUnsupported = errors.New("custom token audience and nonces are not supported by the verifier")
+
 // VerifyAttestationResponse is the response from a successful
 // VerifyAttestation call.
 type VerifyAttestationResponse struct {
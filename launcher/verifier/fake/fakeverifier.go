@@ -4,63 +4,67 @@ package fake
 import (
 	"context"
 	"crypto"
-	"encoding/binary"
+	"fmt"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/internal/challenge"
 	"github.com/google/go-tpm-tools/launcher/verifier"
+	"github.com/google/go-tpm-tools/server"
 )
 
 type fakeClient struct {
-	signer crypto.Signer
+	issuer     verifier.TokenIssuer
+	trustedAK  crypto.PublicKey
+	challenges *challenge.Store
 }
 
-// NewClient constructs a new fake client given a crypto.Signer.
-func NewClient(signer crypto.Signer) verifier.Client {
-	return &fakeClient{signer}
+// NewClient constructs a new fake client given a crypto.Signer for the
+// tokens and the public key of the AK the attestations must be signed with,
+// e.g. the AK of a TPM simulator.
+func NewClient(signer crypto.Signer, trustedAK crypto.PublicKey) verifier.Client {
+	return &fakeClient{
+		issuer: verifier.TokenIssuer{
+			SigningKey:      signer,
+			Issuer:          "https://confidentialcomputing.googleapis.com/",
+			DefaultAudience: "https://sts.googleapis.com/",
+			Lifetime:        time.Hour,
+		},
+		trustedAK:  trustedAK,
+		challenges: challenge.NewStore("projects/fakeProject/locations/fakeRegion/challenges/", time.Hour, challenge.DefaultMaxChallenges),
+	}
 }
 
-// CreateChallenge returns a hard coded, basic challenge.
-//
-// If you have found this method is insufficient for your tests, this class must be updated to
-// allow for better testing.
+// CreateChallenge returns a challenge with a random nonce. Each challenge can
+// be used once in VerifyAttestation.
 func (fc *fakeClient) CreateChallenge(ctx context.Context) (*verifier.Challenge, error) {
-	bs := make([]byte, 2)
-	binary.LittleEndian.PutUint16(bs, 15)
-	return &verifier.Challenge{
-		Name:  "projects/fakeProject/locations/fakeRegion/challenges/d882c62f-452f-4709-9335-0cccaf64eee1",
-		Nonce: bs,
-	}, nil
+	name, nonce, err := fc.challenges.Create()
+	if err != nil {
+		return nil, err
+	}
+	return &verifier.Challenge{Name: name, Nonce: nonce}, nil
 }
 
-// VerifyAttestation does basic checks and returns a hard coded attestation response.
-//
-// If you have found this method is insufficient for your tests, this class must be updated to
-// allow for better testing.
+// VerifyAttestation verifies the attestation against the challenge and the
+// trusted AK, and returns a token with the claims of the verified state.
 func (fc *fakeClient) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
-	// Determine signing algorithm.
-	signingMethod := jwt.SigningMethodRS256
-	now := jwt.TimeFunc()
-	claims := jwt.RegisteredClaims{
-		IssuedAt:  &jwt.NumericDate{Time: now},
-		NotBefore: &jwt.NumericDate{Time: now},
-		ExpiresAt: &jwt.NumericDate{Time: now.Add(time.Hour)},
-		Audience:  []string{"https://sts.googleapis.com/"},
-		Issuer:    "https://confidentialcomputing.googleapis.com/",
-		Subject:   "https://www.googleapis.com/compute/v1/projects/fakeProject/zones/fakeZone/instances/fakeInstance",
+	if request.Challenge == nil || request.Attestation == nil {
+		return nil, fmt.Errorf("nil value provided in challenge")
 	}
-
-	token := jwt.NewWithClaims(signingMethod, claims)
-
-	// Instead of a private key, provide the signer.
-	signed, err := token.SignedString(fc.signer)
+	nonce, err := fc.challenges.Use(request.Challenge.Name)
 	if err != nil {
 		return nil, err
 	}
-
-	response := verifier.VerifyAttestationResponse{
-		ClaimsToken: []byte(signed),
+	state, err := server.VerifyAttestation(request.Attestation, server.VerifyOpts{
+		Nonce:      nonce,
+		TrustedAKs: []crypto.PublicKey{fc.trustedAK},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify attestation: %v", err)
 	}
 
-	return &response, nil
+	signed, err := fc.issuer.Issue("https://www.googleapis.com/compute/v1/projects/fakeProject/zones/fakeZone/instances/fakeInstance", state, request.TokenOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &verifier.VerifyAttestationResponse{ClaimsToken: []byte(signed)}, nil
 }
@@ -0,0 +1,137 @@
+package fake
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	"github.com/google/go-tpm-tools/server/token"
+)
+
+func TestVerifyAttestation(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	signer, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	untrustedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	attest := func(c verifier.Client) verifier.VerifyAttestationRequest {
+		challenge, err := c.CreateChallenge(ctx)
+		if err != nil {
+			t.Fatalf("CreateChallenge() failed: %v", err)
+		}
+		attestation, err := ak.Attest(client.AttestOpts{Nonce: challenge.Nonce})
+		if err != nil {
+			t.Fatalf("failed to attest: %v", err)
+		}
+		return verifier.VerifyAttestationRequest{Challenge: challenge, Attestation: attestation}
+	}
+
+	c := NewClient(signer, ak.PublicKey())
+	req := attest(c)
+	if _, err := c.VerifyAttestation(ctx, req); err != nil {
+		t.Fatalf("VerifyAttestation() failed: %v", err)
+	}
+	if _, err := c.VerifyAttestation(ctx, req); err == nil {
+		t.Error("VerifyAttestation() with a reused challenge succeeded")
+	}
+
+	otherChallenge, err := NewClient(signer, ak.PublicKey()).CreateChallenge(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.VerifyAttestation(ctx, verifier.VerifyAttestationRequest{Challenge: otherChallenge, Attestation: attest(c).Attestation}); err == nil {
+		t.Error("VerifyAttestation() with an unknown challenge succeeded")
+	}
+
+	mismatched := attest(c)
+	mismatched.Attestation = attest(c).Attestation
+	if _, err := c.VerifyAttestation(ctx, mismatched); err == nil {
+		t.Error("VerifyAttestation() with an attestation for another challenge succeeded")
+	}
+
+	untrusted := NewClient(signer, untrustedKey.Public())
+	if _, err := untrusted.VerifyAttestation(ctx, attest(untrusted)); err == nil {
+		t.Error("VerifyAttestation() with an untrusted AK succeeded")
+	}
+}
+
+func TestTokenVerifies(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	akPub := ak.PublicKey()
+	ak.Close()
+
+	signer, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, NewClient(signer, akPub),
+		func(string) ([][]byte, error) { return nil, nil })
+	if err := a.MeasureEvent(cel.CosTlv{EventType: cel.ImageDigestType, EventContent: []byte("sha256:abcd")}); err != nil {
+		t.Fatal(err)
+	}
+	claimsToken, err := a.Attest(context.Background(), agent.AttestAgentOpts{Aud: "https://relying.party", Nonces: []string{"0123456789abcdef"}})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+
+	v, err := token.NewVerifier(token.Options{
+		KeySet:   token.StaticKeySet(map[string]crypto.PublicKey{"": signer.Public()}),
+		Issuer:   "https://confidentialcomputing.googleapis.com/",
+		Audience: "https://relying.party",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := v.Verify(context.Background(), string(claimsToken))
+	if err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	if claims.Submods.Container == nil || claims.Submods.Container.ImageDigest != "sha256:abcd" {
+		t.Errorf("got container claims %+v, want image digest sha256:abcd", claims.Submods.Container)
+	}
+	if len(claims.Nonces) != 1 || claims.Nonces[0] != "0123456789abcdef" {
+		t.Errorf("got nonces %v", claims.Nonces)
+	}
+}
+
+func TestChallengesAreRandom(t *testing.T) {
+	c := NewClient(nil, nil)
+	first, err := c.CreateChallenge(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.CreateChallenge(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Name == second.Name || bytes.Equal(first.Nonce, second.Nonce) {
+		t.Errorf("got the same challenge twice: %v", first)
+	}
+}
@@ -0,0 +1,107 @@
+// Package grpcverifier contains a verifier.Client for attestation verifiers
+// serving the AttestationVerifier gRPC service defined in
+// proto/verifier.proto.
+package grpcverifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	verifierpb "github.com/google/go-tpm-tools/proto/verifier"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// defaultCallTimeout is the deadline of each call whose context has none.
+const defaultCallTimeout = 30 * time.Second
+
+// retryServiceConfig retries calls rejected with UNAVAILABLE, which the
+// server returns before processing a request (e.g. while starting up), so
+// a retried VerifyAttestation does not reuse a consumed challenge.
+const retryServiceConfig = `{
+  "methodConfig": [{
+    "name": [{"service": "verifier.AttestationVerifier"}],
+    "retryPolicy": {
+      "maxAttempts": 4,
+      "initialBackoff": "0.5s",
+      "maxBackoff": "5s",
+      "backoffMultiplier": 2,
+      "retryableStatusCodes": ["UNAVAILABLE"]
+    }
+  }]
+}`
+
+// Dial connects to the verifier at the given address (e.g.
+// "verifier.example.com:443") over TLS. If tlsConfig is nil, the system
+// roots are used to verify the server. Additional options are applied after
+// the defaults, so they can override the transport credentials.
+func Dial(addr string, tlsConfig *tls.Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	defaults := []grpc.DialOption{
+		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		grpc.WithDefaultServiceConfig(retryServiceConfig),
+	}
+	conn, err := grpc.Dial(addr, append(defaults, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial verifier %q: %v", addr, err)
+	}
+	return conn, nil
+}
+
+type grpcClient struct {
+	client verifierpb.AttestationVerifierClient
+}
+
+// NewClient returns a verifier.Client calling the AttestationVerifier
+// service over the connection.
+func NewClient(conn grpc.ClientConnInterface) verifier.Client {
+	return &grpcClient{verifierpb.NewAttestationVerifierClient(conn)}
+}
+
+// withDefaultTimeout returns a context with the default call timeout, unless
+// ctx already has a deadline.
+func withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, defaultCallTimeout)
+}
+
+// CreateChallenge implements verifier.Client
+func (c *grpcClient) CreateChallenge(ctx context.Context) (*verifier.Challenge, error) {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+	chal, err := c.client.CreateChallenge(ctx, &verifierpb.CreateChallengeRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("calling CreateChallenge: %w", err)
+	}
+	return &verifier.Challenge{Name: chal.GetName(), Nonce: chal.GetNonce()}, nil
+}
+
+// VerifyAttestation implements verifier.Client
+func (c *grpcClient) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	if request.Challenge == nil || request.Attestation == nil {
+		return nil, fmt.Errorf("nil value provided in challenge")
+	}
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+	req := &verifierpb.VerifyAttestationRequest{
+		Challenge:      request.Challenge.Name,
+		Attestation:    request.Attestation,
+		GcpCredentials: request.GcpCredentials,
+		TokenOptions: &verifierpb.TokenOptions{
+			Audience: request.TokenOptions.Audience,
+			Nonces:   request.TokenOptions.Nonces,
+		},
+	}
+	resp, err := c.client.VerifyAttestation(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("calling VerifyAttestation: %w", err)
+	}
+	return &verifier.VerifyAttestationResponse{ClaimsToken: resp.GetClaimsToken()}, nil
+}
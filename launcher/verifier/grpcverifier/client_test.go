@@ -0,0 +1,182 @@
+package grpcverifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	verifierpb "github.com/google/go-tpm-tools/proto/verifier"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type stubServer struct {
+	verifierpb.UnimplementedAttestationVerifierServer
+	mu sync.Mutex
+	// unavailable is the number of calls to reject with UNAVAILABLE.
+	unavailable int
+	calls       int
+	hasDeadline bool
+	block       bool
+	request     *verifierpb.VerifyAttestationRequest
+}
+
+func (s *stubServer) call(ctx context.Context) error {
+	s.mu.Lock()
+	s.calls++
+	_, s.hasDeadline = ctx.Deadline()
+	if s.unavailable > 0 {
+		s.unavailable--
+		s.mu.Unlock()
+		return status.Error(codes.Unavailable, "starting up")
+	}
+	block := s.block
+	s.mu.Unlock()
+	if block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (s *stubServer) CreateChallenge(ctx context.Context, _ *verifierpb.CreateChallengeRequest) (*verifierpb.Challenge, error) {
+	if err := s.call(ctx); err != nil {
+		return nil, err
+	}
+	return &verifierpb.Challenge{Name: "challenges/1", Nonce: []byte("nonce")}, nil
+}
+
+func (s *stubServer) VerifyAttestation(ctx context.Context, req *verifierpb.VerifyAttestationRequest) (*verifierpb.VerifyAttestationResponse, error) {
+	if err := s.call(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.request = req
+	s.mu.Unlock()
+	return &verifierpb.VerifyAttestationResponse{ClaimsToken: []byte("token")}, nil
+}
+
+func startStubServer(t *testing.T, stub *stubServer) verifier.Client {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	verifierpb.RegisterAttestationVerifierServer(s, stub)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := Dial("bufnet", nil,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewClient(conn)
+}
+
+func TestCreateChallenge(t *testing.T) {
+	stub := &stubServer{}
+	c := startStubServer(t, stub)
+	chal, err := c.CreateChallenge(context.Background())
+	if err != nil {
+		t.Fatalf("CreateChallenge() failed: %v", err)
+	}
+	if diff := cmp.Diff(&verifier.Challenge{Name: "challenges/1", Nonce: []byte("nonce")}, chal); diff != "" {
+		t.Errorf("unexpected challenge (-want +got):\n%s", diff)
+	}
+	if !stub.hasDeadline {
+		t.Error("the call had no deadline")
+	}
+}
+
+func TestVerifyAttestation(t *testing.T) {
+	stub := &stubServer{}
+	c := startStubServer(t, stub)
+	attestation := &attestpb.Attestation{AkPub: []byte("ak")}
+	resp, err := c.VerifyAttestation(context.Background(), verifier.VerifyAttestationRequest{
+		Challenge:      &verifier.Challenge{Name: "challenges/1", Nonce: []byte("nonce")},
+		GcpCredentials: [][]byte{[]byte("id token")},
+		Attestation:    attestation,
+		TokenOptions:   verifier.TokenOptions{Audience: "aud", Nonces: []string{"0123456789abcdef"}},
+	})
+	if err != nil {
+		t.Fatalf("VerifyAttestation() failed: %v", err)
+	}
+	if string(resp.ClaimsToken) != "token" {
+		t.Errorf("got token %q", resp.ClaimsToken)
+	}
+
+	want := &verifierpb.VerifyAttestationRequest{
+		Challenge:      "challenges/1",
+		Attestation:    attestation,
+		GcpCredentials: [][]byte{[]byte("id token")},
+		TokenOptions:   &verifierpb.TokenOptions{Audience: "aud", Nonces: []string{"0123456789abcdef"}},
+	}
+	if diff := cmp.Diff(want, stub.request, protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected request (-want +got):\n%s", diff)
+	}
+}
+
+func TestVerifyAttestationNilValues(t *testing.T) {
+	c := startStubServer(t, &stubServer{})
+	if _, err := c.VerifyAttestation(context.Background(), verifier.VerifyAttestationRequest{}); err == nil {
+		t.Error("VerifyAttestation() with nil values succeeded")
+	}
+}
+
+// code returns the gRPC code of the wrapped status error.
+func code(err error) codes.Code {
+	var s interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &s) {
+		return s.GRPCStatus().Code()
+	}
+	return codes.Unknown
+}
+
+func TestRetryUnavailable(t *testing.T) {
+	stub := &stubServer{unavailable: 2}
+	c := startStubServer(t, stub)
+	if _, err := c.CreateChallenge(context.Background()); err != nil {
+		t.Fatalf("CreateChallenge() failed: %v", err)
+	}
+	if stub.calls != 3 {
+		t.Errorf("got %d calls, want 3", stub.calls)
+	}
+
+	stub.unavailable = 10
+	_, err := c.CreateChallenge(context.Background())
+	if code(err) != codes.Unavailable {
+		t.Errorf("CreateChallenge() got err %v, want code %v", err, codes.Unavailable)
+	}
+}
+
+func TestCallsHonorContext(t *testing.T) {
+	c := startStubServer(t, &stubServer{block: true})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := c.VerifyAttestation(ctx, verifier.VerifyAttestationRequest{
+		Challenge:   &verifier.Challenge{},
+		Attestation: &attestpb.Attestation{},
+	})
+	if code(err) != codes.DeadlineExceeded {
+		t.Errorf("VerifyAttestation() got err %v, want code %v", err, codes.DeadlineExceeded)
+	}
+}
+
+func TestRetryServiceConfig(t *testing.T) {
+	service := `"service": "` + verifierpb.AttestationVerifier_ServiceDesc.ServiceName + `"`
+	if !strings.Contains(retryServiceConfig, service) {
+		t.Errorf("retry service config does not apply to the %s service", verifierpb.AttestationVerifier_ServiceDesc.ServiceName)
+	}
+}
@@ -0,0 +1,82 @@
+package verifier
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm-tools/server/token"
+)
+
+// TokenIssuer signs the claims tokens of verified attestations, for verifiers
+// implemented in Go such as the self-hosted and fake verifiers.
+type TokenIssuer struct {
+	// SigningKey signs the tokens. It must be an *rsa.PrivateKey (RS256) or
+	// an *ecdsa.PrivateKey on P-256, P-384 or P-521 (ES256, ES384 or ES512).
+	SigningKey crypto.Signer
+	// KeyID is set as the "kid" header of the tokens, if not empty.
+	KeyID string
+	// Issuer is the "iss" claim of the tokens.
+	Issuer string
+	// DefaultAudience is the "aud" claim of the tokens, unless the
+	// TokenOptions specify an audience.
+	DefaultAudience string
+	// Lifetime is how long the tokens are valid.
+	Lifetime time.Duration
+}
+
+// SigningMethod returns the JWT signing method of the key.
+func SigningMethod(key crypto.Signer) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+		return nil, fmt.Errorf("unsupported ECDSA signing key curve %v", k.Curve.Params().Name)
+	}
+	return nil, fmt.Errorf("unsupported signing key type %T", key)
+}
+
+// Issue returns a signed token for the subject, with the claims of the
+// verified machine state and the audience and nonces of the TokenOptions.
+func (i TokenIssuer) Issue(subject string, state *pb.MachineState, opts TokenOptions) (string, error) {
+	signingMethod, err := SigningMethod(i.SigningKey)
+	if err != nil {
+		return "", err
+	}
+	audience := i.DefaultAudience
+	if opts.Audience != "" {
+		audience = opts.Audience
+	}
+	now := jwt.TimeFunc()
+	claims := token.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.Issuer,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(i.Lifetime)),
+		},
+		Nonces: opts.Nonces,
+		Claims: server.ClaimsFromMachineState(state),
+	}
+	t := jwt.NewWithClaims(signingMethod, claims)
+	if i.KeyID != "" {
+		t.Header["kid"] = i.KeyID
+	}
+	return t.SignedString(i.SigningKey)
+}
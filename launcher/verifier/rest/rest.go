@@ -46,14 +46,14 @@ func NewClient(ctx context.Context, projectID string, region string, opts ...opt
 	projectName := fmt.Sprintf("projects/%s", projectID)
 	locationName := fmt.Sprintf("%s/locations/%v", projectName, region)
 
-	location, getErr := service.Projects.Locations.Get(locationName).Do()
+	location, getErr := service.Projects.Locations.Get(locationName).Context(ctx).Do()
 	if getErr == nil {
 		return &restClient{service, location}, nil
 	}
 
 	// If we can't get the location, try to list the locations. This handles
 	// situations where the projectID is invalid.
-	list, listErr := service.Projects.Locations.List(projectName).Do()
+	list, listErr := service.Projects.Locations.List(projectName).Context(ctx).Do()
 	if listErr != nil {
 		return nil, fmt.Errorf("listing regions in project %q: %w", projectID, listErr)
 	}
@@ -81,7 +81,7 @@ func (c *restClient) CreateChallenge(ctx context.Context) (*verifier.Challenge,
 	chal, err := c.service.Projects.Locations.Challenges.Create(
 		c.location.Name,
 		&v1alpha1.Challenge{},
-	).Do()
+	).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("calling v1alpha1.CreateChallenge: %w", err)
 	}
@@ -93,10 +93,13 @@ func (c *restClient) VerifyAttestation(ctx context.Context, request verifier.Ver
 	if request.Challenge == nil || request.Attestation == nil {
 		return nil, fmt.Errorf("nil value provided in challenge")
 	}
+	if request.TokenOptions.Audience != "" || len(request.TokenOptions.Nonces) != 0 {
+		return nil, fmt.Errorf("v1alpha1 API: %w", verifier.ErrTokenOptionsUnsupported)
+	}
 	response, err := c.service.Projects.Locations.Challenges.VerifyAttestation(
 		request.Challenge.Name,
 		convertRequestToREST(request),
-	).Do()
+	).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("calling v1alpha1.VerifyAttestation: %w", err)
 	}
@@ -1,10 +1,16 @@
 package rest
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-tpm-tools/launcher/verifier"
 	v1alpha1 "google.golang.org/api/confidentialcomputing/v1alpha1"
+	"google.golang.org/api/option"
 )
 
 // Make sure our conversion function can handle empty values.
@@ -17,3 +23,43 @@ func TestConvertEmpty(t *testing.T) {
 		t.Errorf("Converting empty challenge: %v", err)
 	}
 }
+
+func TestCallsHonorContext(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name":"projects/test/locations/us-central1","locationId":"us-central1"}`))
+			return
+		}
+		// Never answer the challenge.
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	client, err := NewClient(context.Background(), "test", "us-central1",
+		option.WithEndpoint(ts.URL), option.WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		_, err := client.CreateChallenge(ctx)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("CreateChallenge() got err %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("CreateChallenge() did not return after the context deadline")
+	}
+}
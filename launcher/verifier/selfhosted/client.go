@@ -0,0 +1,104 @@
+package selfhosted
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	"google.golang.org/protobuf/proto"
+)
+
+// The JSON messages exchanged between the Client and the Server.
+type challengeResponse struct {
+	Name  string `json:"name"`
+	Nonce []byte `json:"nonce"`
+}
+
+type verifyAttestationRequest struct {
+	Challenge string `json:"challenge"`
+	// Attestation is the binary serialized attest.Attestation.
+	Attestation []byte `json:"attestation"`
+	// GcpCredentials are accepted for compatibility with verifier.Client,
+	// but are not verified or used in the claims.
+	GcpCredentials [][]byte `json:"gcp_credentials,omitempty"`
+	Audience       string   `json:"audience,omitempty"`
+	Nonces         []string `json:"nonces,omitempty"`
+}
+
+type verifyAttestationResponse struct {
+	ClaimsToken string `json:"claims_token"`
+}
+
+type verifierClient struct {
+	addr       string
+	httpClient *http.Client
+}
+
+// NewClient returns a verifier.Client for the self-hosted verifier at the
+// given address (e.g. "https://verifier.example.com"). If httpClient is nil,
+// http.DefaultClient is used.
+func NewClient(addr string, httpClient *http.Client) verifier.Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &verifierClient{addr: strings.TrimSuffix(addr, "/"), httpClient: httpClient}
+}
+
+// CreateChallenge implements verifier.Client
+func (c *verifierClient) CreateChallenge(ctx context.Context) (*verifier.Challenge, error) {
+	var resp challengeResponse
+	if err := c.post(ctx, challengesPath, struct{}{}, &resp); err != nil {
+		return nil, fmt.Errorf("calling CreateChallenge: %w", err)
+	}
+	return &verifier.Challenge{Name: resp.Name, Nonce: resp.Nonce}, nil
+}
+
+// VerifyAttestation implements verifier.Client
+func (c *verifierClient) VerifyAttestation(ctx context.Context, request verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	if request.Challenge == nil || request.Attestation == nil {
+		return nil, fmt.Errorf("nil value provided in challenge")
+	}
+	attestation, err := proto.Marshal(request.Attestation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal attestation: %w", err)
+	}
+	req := verifyAttestationRequest{
+		Challenge:      request.Challenge.Name,
+		Attestation:    attestation,
+		GcpCredentials: request.GcpCredentials,
+		Audience:       request.TokenOptions.Audience,
+		Nonces:         request.TokenOptions.Nonces,
+	}
+	var resp verifyAttestationResponse
+	if err := c.post(ctx, verifyAttestationPath, req, &resp); err != nil {
+		return nil, fmt.Errorf("calling VerifyAttestation: %w", err)
+	}
+	return &verifier.VerifyAttestationResponse{ClaimsToken: []byte(resp.ClaimsToken)}, nil
+}
+
+func (c *verifierClient) post(ctx context.Context, path string, req interface{}, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1024))
+		return fmt.Errorf("verifier returned %v: %s", httpResp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
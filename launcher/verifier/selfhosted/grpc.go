@@ -0,0 +1,59 @@
+package selfhosted
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/go-tpm-tools/internal/challenge"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	verifierpb "github.com/google/go-tpm-tools/proto/verifier"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterGRPC registers the verifier as the AttestationVerifier gRPC service
+// with the gRPC server.
+func (s *Server) RegisterGRPC(r grpc.ServiceRegistrar) {
+	verifierpb.RegisterAttestationVerifierServer(r, grpcServer{s: s})
+}
+
+type grpcServer struct {
+	verifierpb.UnimplementedAttestationVerifierServer
+	s *Server
+}
+
+func (g grpcServer) CreateChallenge(ctx context.Context, req *verifierpb.CreateChallengeRequest) (*verifierpb.Challenge, error) {
+	name, nonce, err := g.s.challenges.Create()
+	if errors.Is(err, challenge.ErrTooManyChallenges) {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &verifierpb.Challenge{Name: name, Nonce: nonce}, nil
+}
+
+func (g grpcServer) VerifyAttestation(ctx context.Context, req *verifierpb.VerifyAttestationRequest) (*verifierpb.VerifyAttestationResponse, error) {
+	if req.GetAttestation() == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing attestation")
+	}
+	opts := verifier.TokenOptions{Audience: req.GetTokenOptions().GetAudience(), Nonces: req.GetTokenOptions().GetNonces()}
+	token, err := g.s.verifyAttestation(req.GetChallenge(), req.GetAttestation(), opts)
+	if err != nil {
+		return nil, status.Error(grpcCode(errorStatus(err)), err.Error())
+	}
+	return &verifierpb.VerifyAttestationResponse{ClaimsToken: []byte(token)}, nil
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,84 @@
+package selfhosted
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	"github.com/google/go-tpm-tools/launcher/verifier/grpcverifier"
+	"github.com/google/go-tpm-tools/server/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestAttestOverGRPC(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	newTestVerifier(t, signer, nil, trustedAK(t, tpm)).RegisterGRPC(s)
+	go s.Serve(lis)
+	defer s.Stop()
+	conn, err := grpcverifier.Dial("bufnet", nil,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	verifierClient := grpcverifier.NewClient(conn)
+
+	a := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, placeholderFetcher)
+	tokenBytes, err := a.Attest(context.Background(), agent.AttestAgentOpts{Aud: "my-service"})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	claims := &token.Claims{}
+	if _, err := jwt.ParseWithClaims(string(tokenBytes), claims, func(*jwt.Token) (interface{}, error) { return signer.Public(), nil }); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	if !claims.VerifyAudience("my-service", true) {
+		t.Errorf("got aud %v, want my-service", claims.Audience)
+	}
+
+	// Challenges are single use over gRPC too.
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	challenge, err := verifierClient.CreateChallenge(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: challenge.Nonce})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := verifier.VerifyAttestationRequest{Challenge: challenge, Attestation: attestation}
+	if _, err := verifierClient.VerifyAttestation(context.Background(), req); err != nil {
+		t.Fatalf("VerifyAttestation() failed: %v", err)
+	}
+	_, err = verifierClient.VerifyAttestation(context.Background(), req)
+	var st interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &st) || st.GRPCStatus().Code() != codes.InvalidArgument {
+		t.Errorf("VerifyAttestation() with a used challenge got err %v, want code %v", err, codes.InvalidArgument)
+	}
+}
@@ -0,0 +1,248 @@
+package selfhosted
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm-tools/server/token"
+)
+
+const testIssuer = "https://verifier.example.com"
+
+func placeholderFetcher(audience string) ([][]byte, error) {
+	return [][]byte{}, nil
+}
+
+func trustedAK(t *testing.T, tpm io.ReadWriter) crypto.PublicKey {
+	t.Helper()
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatalf("failed to create AK: %v", err)
+	}
+	defer ak.Close()
+	return ak.PublicKey()
+}
+
+func newTestVerifier(t *testing.T, signer crypto.Signer, policy *pb.Policy, trustedAKs ...crypto.PublicKey) *Server {
+	t.Helper()
+	s, err := NewServer(Config{
+		SigningKey:      signer,
+		KeyID:           "test-key",
+		Issuer:          testIssuer,
+		DefaultAudience: "default-audience",
+		VerifyOpts:      server.VerifyOpts{TrustedAKs: trustedAKs},
+		Policy:          policy,
+	})
+	if err != nil {
+		t.Fatalf("NewServer() failed: %v", err)
+	}
+	return s
+}
+
+func newTestServer(t *testing.T, signer crypto.Signer, policy *pb.Policy, trustedAKs ...crypto.PublicKey) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(newTestVerifier(t, signer, policy, trustedAKs...))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestAttestWithSelfHostedVerifier(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		signer crypto.Signer
+		opts   agent.AttestAgentOpts
+		aud    string
+	}{
+		{"ES256DefaultAudience", ecKey, agent.AttestAgentOpts{}, "default-audience"},
+		{"ES384DefaultAudience", p384Key, agent.AttestAgentOpts{}, "default-audience"},
+		{"RS256CustomToken", rsaKey, agent.AttestAgentOpts{Aud: "my-service", Nonces: []string{"thisIsAcustomNonce"}}, "my-service"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+			ts := newTestServer(t, tc.signer, nil, trustedAK(t, tpm))
+			a := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, NewClient(ts.URL, ts.Client()), placeholderFetcher)
+			if err := a.MeasureEvent(cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/library/hello-world:latest")}); err != nil {
+				t.Fatal(err)
+			}
+			tokenBytes, err := a.Attest(context.Background(), tc.opts)
+			if err != nil {
+				t.Fatalf("failed to attest: %v", err)
+			}
+
+			// The token verifies with the keys served by the verifier.
+			v, err := token.NewVerifier(token.Options{
+				KeySet:   token.NewRemoteKeySet(ts.URL+JWKSPath, ts.Client()),
+				Issuer:   testIssuer,
+				Audience: tc.aud,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := v.Verify(context.Background(), string(tokenBytes))
+			if err != nil {
+				t.Fatalf("Verify() failed: %v", err)
+			}
+			if len(claims.Nonces) != len(tc.opts.Nonces) {
+				t.Errorf("got nonces %v, want %v", claims.Nonces, tc.opts.Nonces)
+			}
+			if !strings.HasPrefix(claims.Subject, "ak:") {
+				t.Errorf("got sub %q, want an AK digest", claims.Subject)
+			}
+			if claims.Submods.Container == nil || claims.Submods.Container.ImageReference != "docker.io/library/hello-world:latest" {
+				t.Errorf("got container claims %+v, want the measured image", claims.Submods.Container)
+			}
+			if claims.HWModel != "NONE" {
+				t.Errorf("got hwmodel %q, want NONE", claims.HWModel)
+			}
+		})
+	}
+}
+
+func TestNewServerSigningKeys(t *testing.T) {
+	p224Key, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewServer(Config{SigningKey: p224Key, Issuer: testIssuer}); err == nil {
+		t.Error("NewServer() with a P-224 signing key succeeded")
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewServer(Config{SigningKey: edKey, Issuer: testIssuer}); err == nil {
+		t.Error("NewServer() with an Ed25519 signing key succeeded")
+	}
+}
+
+func TestVerifyAttestationRejected(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	untrustedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	denyAll := &pb.Policy{Platform: &pb.PlatformPolicy{MinimumTechnology: pb.GCEConfidentialTechnology_AMD_SEV_SNP}}
+
+	for _, tc := range []struct {
+		name    string
+		policy  *pb.Policy
+		trusted bool
+		errMsg  string
+	}{
+		{"UntrustedAK", nil, false, "failed to verify attestation"},
+		{"PolicyMismatch", denyAll, true, "does not satisfy the policy"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+			trusted := untrustedKey.Public()
+			if tc.trusted {
+				trusted = trustedAK(t, tpm)
+			}
+			ts := newTestServer(t, signer, tc.policy, trusted)
+			a := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, NewClient(ts.URL, ts.Client()), placeholderFetcher)
+			_, err := a.Attest(context.Background(), agent.AttestAgentOpts{})
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("Attest() got err %v, want an error containing %q", err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestChallengeSingleUse(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	ts := newTestServer(t, signer, nil, trustedAK(t, tpm))
+	c := NewClient(ts.URL, ts.Client())
+	ctx := context.Background()
+
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+
+	challenge, err := c.CreateChallenge(ctx)
+	if err != nil {
+		t.Fatalf("CreateChallenge() failed: %v", err)
+	}
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: challenge.Nonce})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := verifier.VerifyAttestationRequest{Challenge: challenge, Attestation: attestation}
+	if _, err := c.VerifyAttestation(ctx, req); err != nil {
+		t.Fatalf("VerifyAttestation() failed: %v", err)
+	}
+	if _, err := c.VerifyAttestation(ctx, req); err == nil {
+		t.Error("VerifyAttestation() with a used challenge succeeded, want an error")
+	}
+
+	unknown := &verifier.Challenge{Name: "challenges/unknown", Nonce: challenge.Nonce}
+	if _, err := c.VerifyAttestation(ctx, verifier.VerifyAttestationRequest{Challenge: unknown, Attestation: attestation}); err == nil {
+		t.Error("VerifyAttestation() with an unknown challenge succeeded, want an error")
+	}
+}
+
+func TestMaxChallenges(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(Config{SigningKey: signer, Issuer: testIssuer, MaxChallenges: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	if _, err := NewClient(ts.URL, ts.Client()).CreateChallenge(context.Background()); err != nil {
+		t.Fatalf("CreateChallenge() failed: %v", err)
+	}
+	resp, err := ts.Client().Post(ts.URL+challengesPath, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("CreateChallenge over the maximum got status %v, want %v", resp.Status, http.StatusTooManyRequests)
+	}
+}
@@ -0,0 +1,230 @@
+// Package selfhosted contains a self-hosted attestation verifier, serving
+// CreateChallenge and VerifyAttestation over HTTP, and the verifier.Client
+// for it. Attestations are verified with server.VerifyAttestation, so the
+// verifier can run on-prem or in tests without the Google attestation
+// service.
+package selfhosted
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-tpm-tools/internal/challenge"
+	"github.com/google/go-tpm-tools/launcher/verifier"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm-tools/server/token"
+	"google.golang.org/protobuf/proto"
+)
+
+// HTTP paths served by the verifier.
+const (
+	challengesPath        = "/v1/challenges"
+	verifyAttestationPath = "/v1/verifyAttestation"
+	// JWKSPath serves the JSON Web Key Set of the token signing key, e.g. for
+	// a token.RemoteKeySet.
+	JWKSPath = "/.well-known/jwks.json"
+)
+
+const (
+	defaultChallengeLifetime = 5 * time.Minute
+	defaultTokenLifetime     = time.Hour
+	maxRequestSize           = 10 << 20
+)
+
+// Config configures a verifier Server.
+type Config struct {
+	// SigningKey signs the claims tokens, see verifier.TokenIssuer.
+	SigningKey crypto.Signer
+	// KeyID is set as the "kid" header of the claims tokens, if not empty.
+	KeyID string
+	// Issuer is the "iss" claim of the claims tokens.
+	Issuer string
+	// DefaultAudience is the "aud" claim of the claims tokens, unless the
+	// request specifies an audience.
+	DefaultAudience string
+	// TokenLifetime is how long claims tokens are valid. Defaults to 1 hour.
+	TokenLifetime time.Duration
+	// ChallengeLifetime is how long a challenge can be used after it was
+	// created. Defaults to 5 minutes.
+	ChallengeLifetime time.Duration
+	// MaxChallenges is the number of outstanding challenges, above which
+	// CreateChallenge is refused. Defaults to challenge.DefaultMaxChallenges.
+	MaxChallenges int
+	// VerifyOpts configures which AKs are trusted. The Nonce is set from the
+	// challenge of each request.
+	VerifyOpts server.VerifyOpts
+	// Policy, if not nil, is evaluated against the verified MachineState.
+	Policy *pb.Policy
+}
+
+// Server is a self-hosted attestation verifier. It is an http.Handler, and
+// can also be registered as a gRPC service with RegisterGRPC.
+type Server struct {
+	config     Config
+	issuer     verifier.TokenIssuer
+	jwks       []byte
+	challenges *challenge.Store
+	mux        *http.ServeMux
+}
+
+// NewServer creates a verifier Server with the given config.
+func NewServer(config Config) (*Server, error) {
+	if _, err := verifier.SigningMethod(config.SigningKey); err != nil {
+		return nil, err
+	}
+	if config.TokenLifetime == 0 {
+		config.TokenLifetime = defaultTokenLifetime
+	}
+	if config.ChallengeLifetime == 0 {
+		config.ChallengeLifetime = defaultChallengeLifetime
+	}
+	if config.MaxChallenges == 0 {
+		config.MaxChallenges = challenge.DefaultMaxChallenges
+	}
+	jwks, err := token.MarshalJWKS(map[string]crypto.PublicKey{config.KeyID: config.SigningKey.Public()})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		config: config,
+		issuer: verifier.TokenIssuer{
+			SigningKey:      config.SigningKey,
+			KeyID:           config.KeyID,
+			Issuer:          config.Issuer,
+			DefaultAudience: config.DefaultAudience,
+			Lifetime:        config.TokenLifetime,
+		},
+		jwks:       jwks,
+		challenges: challenge.NewStore("challenges/", config.ChallengeLifetime, config.MaxChallenges),
+		mux:        http.NewServeMux(),
+	}
+	s.mux.HandleFunc(challengesPath, s.handleCreateChallenge)
+	s.mux.HandleFunc(verifyAttestationPath, s.handleVerifyAttestation)
+	s.mux.HandleFunc(JWKSPath, s.handleJWKS)
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleCreateChallenge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name, nonce, err := s.challenges.Create()
+	if errors.Is(err, challenge.ErrTooManyChallenges) {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, challengeResponse{Name: name, Nonce: nonce})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.jwks)
+}
+
+// requestError is an error verifying an attestation, with the HTTP status
+// describing its cause.
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+// errorStatus returns the HTTP status describing the cause of the error.
+func errorStatus(err error) int {
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		return reqErr.status
+	}
+	return http.StatusInternalServerError
+}
+
+func (s *Server) handleVerifyAttestation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req verifyAttestationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode request: %v", err), http.StatusBadRequest)
+		return
+	}
+	attestation := &pb.Attestation{}
+	if err := proto.Unmarshal(req.Attestation, attestation); err != nil {
+		http.Error(w, fmt.Sprintf("failed to unmarshal attestation: %v", err), http.StatusBadRequest)
+		return
+	}
+	token, err := s.verifyAttestation(req.Challenge, attestation, verifier.TokenOptions{Audience: req.Audience, Nonces: req.Nonces})
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+	writeJSON(w, verifyAttestationResponse{ClaimsToken: token})
+}
+
+// verifyAttestation verifies the attestation made with the named challenge,
+// and returns the signed claims token.
+func (s *Server) verifyAttestation(challengeName string, attestation *pb.Attestation, opts verifier.TokenOptions) (string, error) {
+	nonce, err := s.challenges.Use(challengeName)
+	if err != nil {
+		return "", &requestError{http.StatusBadRequest, err}
+	}
+	verifyOpts := s.config.VerifyOpts
+	verifyOpts.Nonce = nonce
+	state, err := server.VerifyAttestation(attestation, verifyOpts)
+	if err != nil {
+		return "", &requestError{http.StatusForbidden, fmt.Errorf("failed to verify attestation: %v", err)}
+	}
+	if s.config.Policy != nil {
+		if err := server.EvaluatePolicy(state, s.config.Policy); err != nil {
+			return "", &requestError{http.StatusForbidden, fmt.Errorf("attestation does not satisfy the policy: %v", err)}
+		}
+	}
+
+	token, err := s.issuer.Issue(subject(attestation, state), state, opts)
+	if err != nil {
+		return "", &requestError{http.StatusInternalServerError, fmt.Errorf("failed to sign claims: %v", err)}
+	}
+	return token, nil
+}
+
+// subject identifies the attester: the GCE instance if the AK certificate
+// contains the instance info, otherwise the digest of the AK public area.
+func subject(attestation *pb.Attestation, state *pb.MachineState) string {
+	if info := state.GetPlatform().GetInstanceInfo(); info != nil {
+		return server.GCEInstanceURL(info)
+	}
+	akDigest := sha256.Sum256(attestation.GetAkPub())
+	return "ak:" + hex.EncodeToString(akDigest[:])
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,211 @@
+// Package main is a self-hosted attestation verifier, which can be used by
+// the launcher instead of the Google attestation service.
+package main
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/google/go-tpm-tools/launcher/verifier/selfhosted"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+var (
+	addr           = flag.String("addr", ":8080", "address to listen on")
+	grpcAddr       = flag.String("grpc-addr", "", "address to serve the gRPC AttestationVerifier service on; disabled if empty")
+	tlsCert        = flag.String("tls-cert", "", "PEM TLS certificate file; serves plain HTTP if empty")
+	tlsKey         = flag.String("tls-key", "", "PEM TLS private key file")
+	signingKey     = flag.String("signing-key", "", "PEM RSA or ECDSA private key file to sign claims tokens (required)")
+	keyID          = flag.String("key-id", "", "key ID set in the claims tokens")
+	issuer         = flag.String("issuer", "", "issuer of the claims tokens (required)")
+	audience       = flag.String("audience", "", "default audience of the claims tokens")
+	trustedAKs     = flag.String("trusted-aks", "", "PEM file of trusted AK public keys")
+	trustedRoots   = flag.String("trusted-roots", "", "PEM file of trusted root CA certificates for AK certificates")
+	intermediates  = flag.String("intermediates", "", "PEM file of intermediate CA certificates for AK certificates")
+	policyFile     = flag.String("policy", "", "text proto attest.Policy file to evaluate against the machine state")
+	allowSHA1      = flag.Bool("allow-sha1", false, "allow SHA-1 PCRs to verify attestations")
+	grubBootloader = flag.Bool("grub", false, "parse GRUB events from the TCG event log")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	if *signingKey == "" || *issuer == "" {
+		return errors.New("--signing-key and --issuer are required")
+	}
+	config := selfhosted.Config{
+		KeyID:           *keyID,
+		Issuer:          *issuer,
+		DefaultAudience: *audience,
+		VerifyOpts:      server.VerifyOpts{AllowSHA1: *allowSHA1},
+	}
+	if *grubBootloader {
+		config.VerifyOpts.Loader = server.GRUB
+	}
+
+	var err error
+	if config.SigningKey, err = readSigningKey(*signingKey); err != nil {
+		return err
+	}
+	if *trustedAKs != "" {
+		if config.VerifyOpts.TrustedAKs, err = readPublicKeys(*trustedAKs); err != nil {
+			return err
+		}
+	}
+	if *trustedRoots != "" {
+		if config.VerifyOpts.TrustedRootCerts, err = readCerts(*trustedRoots); err != nil {
+			return err
+		}
+	}
+	if *intermediates != "" {
+		if config.VerifyOpts.IntermediateCerts, err = readCerts(*intermediates); err != nil {
+			return err
+		}
+	}
+	if *policyFile != "" {
+		data, err := os.ReadFile(*policyFile)
+		if err != nil {
+			return err
+		}
+		config.Policy = &pb.Policy{}
+		if err := prototext.Unmarshal(data, config.Policy); err != nil {
+			return fmt.Errorf("failed to parse policy: %v", err)
+		}
+	}
+
+	verifier, err := selfhosted.NewServer(config)
+	if err != nil {
+		return err
+	}
+	errs := make(chan error, 2)
+	if *grpcAddr != "" {
+		go func() { errs <- serveGRPC(verifier) }()
+	}
+	go func() {
+		log.Printf("verifier listening on %v, serving the token signing keys at %v", *addr, selfhosted.JWKSPath)
+		if *tlsCert != "" {
+			errs <- http.ListenAndServeTLS(*addr, *tlsCert, *tlsKey, verifier)
+			return
+		}
+		errs <- http.ListenAndServe(*addr, verifier)
+	}()
+	return <-errs
+}
+
+func serveGRPC(verifier *selfhosted.Server) error {
+	var opts []grpc.ServerOption
+	if *tlsCert != "" {
+		creds, err := credentials.NewServerTLSFromFile(*tlsCert, *tlsKey)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	lis, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		return err
+	}
+	s := grpc.NewServer(opts...)
+	verifier.RegisterGRPC(s)
+	log.Printf("gRPC verifier listening on %v", *grpcAddr)
+	return s.Serve(lis)
+}
+
+func readPEMBlocks(path string, blockType string) ([][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var blocks [][]byte
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type == blockType {
+			blocks = append(blocks, block.Bytes)
+		}
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no %v PEM blocks found in %v", blockType, path)
+	}
+	return blocks, nil
+}
+
+func readSigningKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %v", path)
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("unsupported PEM block type %q in %v", block.Type, path)
+}
+
+func readPublicKeys(path string) ([]crypto.PublicKey, error) {
+	blocks, err := readPEMBlocks(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]crypto.PublicKey, 0, len(blocks))
+	for _, block := range blocks {
+		key, err := x509.ParsePKIXPublicKey(block)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func readCerts(path string) ([]*x509.Certificate, error) {
+	blocks, err := readPEMBlocks(path, "CERTIFICATE")
+	if err != nil {
+		return nil, err
+	}
+	certs := make([]*x509.Certificate, 0, len(blocks))
+	for _, block := range blocks {
+		cert, err := x509.ParseCertificate(block)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
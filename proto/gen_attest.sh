@@ -1,3 +1,3 @@
 #!/bin/bash
 
-protoc -I. -I`go list -m -f "{{.Dir}}" github.com/google/go-sev-guest` --go_out=. --go_opt=module=github.com/google/go-tpm-tools/proto attest.proto
+protoc -I. -I`go list -m -f "{{.Dir}}" github.com/google/go-sev-guest` --go_out=. --go_opt=module=github.com/google/go-tpm-tools/proto --go-grpc_out=. --go-grpc_opt=module=github.com/google/go-tpm-tools/proto attest.proto verifier.proto
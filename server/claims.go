@@ -0,0 +1,200 @@
+package server
+
+import (
+	"fmt"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// Claims are the claims about a verified machine that attestation verifiers
+// put in their tokens, next to the registered JWT claims (iss, sub, aud, ...).
+// The JSON field names are the claim names. Claims only contain state that
+// was verified, so claims for state missing from the attestation are omitted.
+type Claims struct {
+	// HWModel is the Confidential Computing technology of the machine, one
+	// of the GCEConfidentialTechnology names (e.g. "AMD_SEV_SNP"). It is
+	// "NONE" for machines without memory encryption.
+	HWModel string `json:"hwmodel"`
+	// SecureBoot is whether UEFI Secure Boot was enabled.
+	SecureBoot bool `json:"secboot"`
+	// GCEFirmwareVersion is the version of the virtual GCE firmware, or 0 if
+	// the machine did not boot GCE firmware.
+	GCEFirmwareVersion uint32 `json:"gce_firmware_version,omitempty"`
+	// Submods contains the claims about the components running on the
+	// machine.
+	Submods SubmodClaims `json:"submods"`
+}
+
+// SubmodClaims are the claims about the components running on a machine.
+type SubmodClaims struct {
+	// GCE is the instance identity, if known.
+	GCE *GCEClaims `json:"gce,omitempty"`
+	// ConfidentialSpace is the COS and launcher state, if the attestation
+	// contains a COS eventlog.
+	ConfidentialSpace *ConfidentialSpaceClaims `json:"confidential_space,omitempty"`
+	// Container is the launched container, if the attestation contains a COS
+	// eventlog.
+	Container *ContainerClaims `json:"container,omitempty"`
+	// Sidecars are the containers launched next to the Container, in the
+	// order of the launch spec.
+	Sidecars []ContainerClaims `json:"sidecars,omitempty"`
+}
+
+// GCEClaims identify a GCE instance.
+type GCEClaims struct {
+	Zone          string `json:"zone"`
+	ProjectID     string `json:"project_id"`
+	ProjectNumber uint64 `json:"project_number,string"`
+	InstanceName  string `json:"instance_name"`
+	InstanceID    uint64 `json:"instance_id,string"`
+}
+
+// ConfidentialSpaceClaims are the claims about the COS image and the
+// container launcher.
+type ConfidentialSpaceClaims struct {
+	// COSVersion and LauncherVersion are semantic versions (e.g. "1.2.3"),
+	// omitted if they were not measured.
+	COSVersion      string `json:"cos_version,omitempty"`
+	LauncherVersion string `json:"launcher_version,omitempty"`
+	// LaunchSpecSigner identifies the key that signed the launch spec, see
+	// cel.FormatLaunchSpecSigner. It is omitted if the launch spec was not
+	// signed.
+	LaunchSpecSigner string `json:"launch_spec_signer,omitempty"`
+	// WorkloadMeasurements are the measurements made by the workload after
+	// it was launched, in order.
+	WorkloadMeasurements []WorkloadMeasurementClaims `json:"workload_measurements,omitempty"`
+	// TerminationSignal is the signal the launcher received to stop the
+	// workload (e.g. "SIGTERM"), omitted while it is not being stopped.
+	TerminationSignal string `json:"termination_signal,omitempty"`
+}
+
+// WorkloadMeasurementClaims are the claims about a workload measurement.
+type WorkloadMeasurementClaims struct {
+	Type    string `json:"type"`
+	Content []byte `json:"content"`
+}
+
+// ContainerClaims are the claims about a launched container.
+type ContainerClaims struct {
+	ImageReference string `json:"image_reference"`
+	ImageDigest    string `json:"image_digest"`
+	ImageID        string `json:"image_id"`
+	// ImageSigner identifies the key whose signature on the image digest was
+	// verified, see cel.FormatImageSigner. It is omitted if the image
+	// signature was not verified.
+	ImageSigner string `json:"image_signer,omitempty"`
+	// RestartPolicy is one of the RestartPolicy names (e.g. "Never").
+	RestartPolicy string `json:"restart_policy"`
+	// RestartCount is the number of times the container was restarted after
+	// launch.
+	RestartCount uint32 `json:"restart_count,omitempty"`
+	// Args and Env are the command and environment the container was
+	// launched with.
+	Args []string          `json:"args,omitempty"`
+	Env  map[string]string `json:"env,omitempty"`
+	// ArgsOverride and EnvOverride are the subsets of Args and Env set by the
+	// operator, rather than the image.
+	ArgsOverride []string          `json:"args_override,omitempty"`
+	EnvOverride  map[string]string `json:"env_override,omitempty"`
+	// Mounts are the mounts requested by the operator.
+	Mounts []MountClaims `json:"mounts,omitempty"`
+	// AddedCapabilities are the capabilities added to the container by the
+	// operator (e.g. "CAP_NET_ADMIN").
+	AddedCapabilities []string `json:"added_capabilities,omitempty"`
+	// HostNetwork is whether the container uses the host network.
+	HostNetwork bool `json:"host_network,omitempty"`
+	// MemoryLimitMB is the memory limit of the container in MiB, omitted if
+	// it is not limited.
+	MemoryLimitMB uint64 `json:"memory_limit_mb,omitempty"`
+}
+
+// MountClaims are the claims about a mount of the container.
+type MountClaims struct {
+	// Type is "bind", "tmpfs" or "luks".
+	Type        string `json:"type"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination"`
+	ReadOnly    bool   `json:"read_only,omitempty"`
+	SizeBytes   uint64 `json:"size_bytes,omitempty"`
+}
+
+// ClaimsFromMachineState returns the claims for a MachineState returned by
+// VerifyAttestation.
+func ClaimsFromMachineState(state *pb.MachineState) Claims {
+	platform := state.GetPlatform()
+	claims := Claims{
+		HWModel:            platform.GetTechnology().String(),
+		SecureBoot:         state.GetSecureBoot().GetEnabled(),
+		GCEFirmwareVersion: platform.GetGceVersion(),
+	}
+	if info := platform.GetInstanceInfo(); info != nil {
+		claims.Submods.GCE = &GCEClaims{
+			Zone:          info.GetZone(),
+			ProjectID:     info.GetProjectId(),
+			ProjectNumber: info.GetProjectNumber(),
+			InstanceName:  info.GetInstanceName(),
+			InstanceID:    info.GetInstanceId(),
+		}
+	}
+
+	cos := state.GetCos()
+	if cos == nil {
+		return claims
+	}
+	cs := &ConfidentialSpaceClaims{
+		COSVersion:        semanticVersion(cos.GetCosVersion()),
+		LauncherVersion:   semanticVersion(cos.GetLauncherVersion()),
+		LaunchSpecSigner:  cos.GetLaunchSpecSigner(),
+		TerminationSignal: cos.GetTerminationSignal(),
+	}
+	for _, m := range cos.GetWorkloadMeasurements() {
+		cs.WorkloadMeasurements = append(cs.WorkloadMeasurements, WorkloadMeasurementClaims{m.GetType(), m.GetContent()})
+	}
+	claims.Submods.ConfidentialSpace = cs
+
+	if container := cos.GetContainer(); container != nil {
+		c := containerClaims(container)
+		claims.Submods.Container = &c
+	}
+	if containers := cos.GetContainers(); len(containers) > 1 {
+		for _, container := range containers[1:] {
+			claims.Submods.Sidecars = append(claims.Submods.Sidecars, containerClaims(container))
+		}
+	}
+	return claims
+}
+
+func containerClaims(container *pb.ContainerState) ContainerClaims {
+	claims := ContainerClaims{
+		ImageReference:    container.GetImageReference(),
+		ImageDigest:       container.GetImageDigest(),
+		ImageID:           container.GetImageId(),
+		ImageSigner:       container.GetImageSigner(),
+		RestartPolicy:     container.GetRestartPolicy().String(),
+		RestartCount:      container.GetRestartCount(),
+		Args:              container.GetArgs(),
+		Env:               container.GetEnvVars(),
+		ArgsOverride:      container.GetOverriddenArgs(),
+		EnvOverride:       container.GetOverriddenEnvVars(),
+		AddedCapabilities: container.GetAddedCapabilities(),
+		HostNetwork:       container.GetHostNetwork(),
+		MemoryLimitMB:     container.GetMemoryLimitMb(),
+	}
+	for _, m := range container.GetMounts() {
+		claims.Mounts = append(claims.Mounts, MountClaims{
+			Type:        m.GetType(),
+			Source:      m.GetSource(),
+			Destination: m.GetDestination(),
+			ReadOnly:    m.GetReadOnly(),
+			SizeBytes:   m.GetSizeBytes(),
+		})
+	}
+	return claims
+}
+
+func semanticVersion(v *pb.SemanticVersion) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d", v.GetMajor(), v.GetMinor(), v.GetPatch())
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+func TestClaimsFromMachineState(t *testing.T) {
+	container := &pb.ContainerState{
+		ImageReference:    "docker.io/library/hello-world:latest",
+		ImageDigest:       "sha256:abcd",
+		ImageId:           "sha256:1234",
+		ImageSigner:       "sha256:5678",
+		RestartPolicy:     pb.RestartPolicy_OnFailure,
+		RestartCount:      2,
+		Args:              []string{"/hello", "--flag"},
+		EnvVars:           map[string]string{"FOO": "bar", "BAZ": "qux"},
+		OverriddenArgs:    []string{"--flag"},
+		OverriddenEnvVars: map[string]string{"FOO": "bar"},
+		Mounts: []*pb.Mount{
+			{Type: "bind", Source: "/mnt/disks/data", Destination: "/data", ReadOnly: true},
+			{Type: "tmpfs", Destination: "/scratch", SizeBytes: 1024},
+		},
+		AddedCapabilities: []string{"CAP_NET_ADMIN"},
+		HostNetwork:       true,
+		MemoryLimitMb:     512,
+	}
+	sidecar := &pb.ContainerState{
+		ImageReference: "docker.io/envoyproxy/envoy:v1.27",
+		ImageDigest:    "sha256:ef01",
+		RestartPolicy:  pb.RestartPolicy_Never,
+		Args:           []string{"envoy"},
+	}
+	state := &pb.MachineState{
+		Platform: &pb.PlatformState{
+			Firmware:   &pb.PlatformState_GceVersion{GceVersion: 2},
+			Technology: pb.GCEConfidentialTechnology_AMD_SEV_SNP,
+			InstanceInfo: &pb.GCEInstanceInfo{
+				Zone:          "us-central1-a",
+				ProjectId:     "my-project",
+				ProjectNumber: 1234,
+				InstanceName:  "my-instance",
+				InstanceId:    5678,
+			},
+		},
+		SecureBoot: &pb.SecureBootState{Enabled: true},
+		Cos: &pb.AttestedCosState{
+			Container:            container,
+			Containers:           []*pb.ContainerState{container, sidecar},
+			LauncherVersion:      &pb.SemanticVersion{Major: 1, Minor: 2, Patch: 3},
+			LaunchSpecSigner:     "sha256:0123",
+			WorkloadMeasurements: []*pb.WorkloadMeasurement{{Type: "config", Content: []byte("key: value")}},
+			TerminationSignal:    "SIGTERM",
+		},
+	}
+
+	want := Claims{
+		HWModel:            "AMD_SEV_SNP",
+		SecureBoot:         true,
+		GCEFirmwareVersion: 2,
+		Submods: SubmodClaims{
+			GCE: &GCEClaims{
+				Zone:          "us-central1-a",
+				ProjectID:     "my-project",
+				ProjectNumber: 1234,
+				InstanceName:  "my-instance",
+				InstanceID:    5678,
+			},
+			ConfidentialSpace: &ConfidentialSpaceClaims{
+				LauncherVersion:      "1.2.3",
+				LaunchSpecSigner:     "sha256:0123",
+				WorkloadMeasurements: []WorkloadMeasurementClaims{{"config", []byte("key: value")}},
+				TerminationSignal:    "SIGTERM",
+			},
+			Container: &ContainerClaims{
+				ImageReference: "docker.io/library/hello-world:latest",
+				ImageDigest:    "sha256:abcd",
+				ImageID:        "sha256:1234",
+				ImageSigner:    "sha256:5678",
+				RestartPolicy:  "OnFailure",
+				RestartCount:   2,
+				Args:           []string{"/hello", "--flag"},
+				Env:            map[string]string{"FOO": "bar", "BAZ": "qux"},
+				ArgsOverride:   []string{"--flag"},
+				EnvOverride:    map[string]string{"FOO": "bar"},
+				Mounts: []MountClaims{
+					{Type: "bind", Source: "/mnt/disks/data", Destination: "/data", ReadOnly: true},
+					{Type: "tmpfs", Destination: "/scratch", SizeBytes: 1024},
+				},
+				AddedCapabilities: []string{"CAP_NET_ADMIN"},
+				HostNetwork:       true,
+				MemoryLimitMB:     512,
+			},
+			Sidecars: []ContainerClaims{{
+				ImageReference: "docker.io/envoyproxy/envoy:v1.27",
+				ImageDigest:    "sha256:ef01",
+				RestartPolicy:  "Never",
+				Args:           []string{"envoy"},
+			}},
+		},
+	}
+	got := ClaimsFromMachineState(state)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ClaimsFromMachineState() returned unexpected claims (-want +got):\n%s", diff)
+	}
+
+	// The claims must survive a round trip through the token encoding.
+	encoded, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Claims
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, decoded); diff != "" {
+		t.Errorf("claims changed in JSON round trip (-want +got):\n%s", diff)
+	}
+}
+
+func TestClaimsFromEmptyMachineState(t *testing.T) {
+	got := ClaimsFromMachineState(&pb.MachineState{})
+	want := Claims{HWModel: "NONE"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ClaimsFromMachineState() returned unexpected claims (-want +got):\n%s", diff)
+	}
+
+	encoded, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"hwmodel":"NONE","secboot":false,"submods":{}}` {
+		t.Errorf("got JSON claims %s", encoded)
+	}
+}
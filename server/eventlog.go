@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/x509"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"unicode/utf16"
 
 	"github.com/google/go-attestation/attest"
 	"github.com/google/go-tpm-tools/cel"
@@ -26,6 +29,15 @@ var (
 		// https://src.fedoraproject.org/rpms/grub2/blob/c789522f7cfa19a10cd716a1db24dab5499c6e5c/f/0224-Rework-TPM-measurements.patch
 		oldGrubKernelCmdlinePrefix,
 		[]byte("grub_cmd ")}
+	// The EFI_GUIDs below are in their mixed-endian wire format.
+	// SHIM_LOCK_GUID (605dab50-e046-4300-abb6-3dd810dd8b23), used by shim for
+	// its own variables. See https://github.com/rhboot/shim/blob/main/lib/guid.c.
+	shimLockGUID = []byte{0x50, 0xab, 0x5d, 0x60, 0x46, 0xe0, 0x00, 0x43,
+		0xab, 0xb6, 0x3d, 0xd8, 0x10, 0xdd, 0x8b, 0x23}
+	// EFI_IMAGE_SECURITY_DATABASE_GUID (d719b2cb-3d3a-4596-a3bc-dad00e67656f),
+	// used by shim when measuring its built-in vendor_db.
+	imageSecurityDatabaseGUID = []byte{0xcb, 0xb2, 0x19, 0xd7, 0x3a, 0x3d,
+		0x96, 0x45, 0xa3, 0xbc, 0xda, 0xd0, 0x0e, 0x67, 0x65, 0x6f}
 )
 
 // parsePCClientEventLog parses a raw event log and replays the parsed event
@@ -59,6 +71,10 @@ func parsePCClientEventLog(rawEventLog []byte, pcrs *tpmpb.PCRs, loader Bootload
 	if err != nil {
 		errors = append(errors, err)
 	}
+	shimState, err := getShimState(cryptoHash, rawEvents)
+	if err != nil {
+		errors = append(errors, err)
+	}
 
 	var grub *pb.GrubState
 	var kernel *pb.LinuxKernelState
@@ -76,6 +92,7 @@ func parsePCClientEventLog(rawEventLog []byte, pcrs *tpmpb.PCRs, loader Bootload
 	return &pb.MachineState{
 		Platform:    platform,
 		SecureBoot:  sbState,
+		Shim:        shimState,
 		RawEvents:   rawEvents,
 		Hash:        pcrs.GetHash(),
 		Grub:        grub,
@@ -84,23 +101,37 @@ func parsePCClientEventLog(rawEventLog []byte, pcrs *tpmpb.PCRs, loader Bootload
 }
 
 func parseCanonicalEventLog(rawCanonicalEventLog []byte, pcrs *tpmpb.PCRs) (*pb.MachineState, error) {
-	decodedCEL, err := cel.DecodeToCEL(bytes.NewBuffer(rawCanonicalEventLog))
+	replayer, err := cel.NewReplayer(pcrs)
 	if err != nil {
 		return nil, err
 	}
-	// Validate the COS event log first.
-	if err := decodedCEL.Replay(pcrs); err != nil {
-		return nil, err
+	cosState := newCosStateBuilder()
+	// Records are processed as they are decoded, so the log is never held in
+	// memory as a whole.
+	decoder := cel.NewDecoder(bytes.NewReader(rawCanonicalEventLog))
+	for {
+		record, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := replayer.Extend(record); err != nil {
+			return nil, err
+		}
+		if err := cosState.addRecord(record); err != nil {
+			return nil, err
+		}
 	}
-
-	cosState, err := getVerifiedCosState(decodedCEL, pcrs)
-	if err != nil {
+	// The COS state is only trusted if the whole event log replays.
+	if err := replayer.Verify(); err != nil {
 		return nil, err
 	}
 
 	return &pb.MachineState{
-		Cos: cosState,
-	}, err
+		Cos: cosState.state,
+	}, nil
 }
 
 func contains(set [][]byte, value []byte) bool {
@@ -112,93 +143,212 @@ func contains(set [][]byte, value []byte) bool {
 	return false
 }
 
-func getVerifiedCosState(coscel cel.CEL, pcrs *tpmpb.PCRs) (*pb.AttestedCosState, error) {
+// cosStateBuilder accumulates the AttestedCosState from the COS CEL records.
+type cosStateBuilder struct {
+	state *pb.AttestedCosState
+	// container is the container the container events are of, the last
+	// of state.Containers.
+	container     *pb.ContainerState
+	seenSeparator bool
+	// seenHostNetwork is whether the HostNetwork event of container was
+	// found.
+	seenHostNetwork bool
+}
+
+func newCosStateBuilder() *cosStateBuilder {
 	cosState := &pb.AttestedCosState{}
-	cosState.Container = &pb.ContainerState{}
-	cosState.Container.Args = make([]string, 0)
-	cosState.Container.EnvVars = make(map[string]string)
-	cosState.Container.OverriddenEnvVars = make(map[string]string)
-
-	seenSeparator := false
-	for _, record := range coscel.Records {
-		// COS State only comes from the CosEventPCR
-		if record.PCR != cel.CosEventPCR {
-			return nil, fmt.Errorf("found unexpected PCR %d in CEL log", record.PCR)
-		}
-
-		// The Content.Type is not verified at this point, so we have to fail
-		// if we see any events that we do not understand. This ensures that
-		// we either verify the digest of event event in this PCR, or we fail
-		// to replay the event log.
-		// TODO: See if we can fix this to have the Content Type be verified.
-		cosTlv, err := record.Content.ParseToCosTlv()
-		if err != nil {
-			return nil, err
-		}
+	cosState.Container = newContainerState()
+	cosState.Containers = []*pb.ContainerState{cosState.Container}
+	return &cosStateBuilder{state: cosState, container: cosState.Container}
+}
 
-		// verify digests for the cos cel content
-		if err := cel.VerifyDigests(cosTlv, record.Digests); err != nil {
-			return nil, err
+func newContainerState() *pb.ContainerState {
+	container := &pb.ContainerState{}
+	container.Args = make([]string, 0)
+	container.EnvVars = make(map[string]string)
+	container.OverriddenEnvVars = make(map[string]string)
+	return container
+}
+
+func (b *cosStateBuilder) addRecord(record cel.Record) error {
+	cosState := b.state
+	container := b.container
+	// COS State only comes from the CosEventPCR
+	if record.PCR != cel.CosEventPCR {
+		return fmt.Errorf("found unexpected PCR %d in CEL log", record.PCR)
+	}
+
+	// The Content.Type is not verified at this point, so we have to fail
+	// if we see any events that we do not understand. This ensures that
+	// we either verify the digest of event event in this PCR, or we fail
+	// to replay the event log.
+	// TODO: See if we can fix this to have the Content Type be verified.
+	cosTlv, err := record.Content.ParseToCosTlv()
+	if err != nil {
+		return err
+	}
+
+	// verify digests for the cos cel content
+	if err := cel.VerifyDigests(cosTlv, record.Digests); err != nil {
+		return err
+	}
+
+	// Only the workload measurements, and the restarts and termination of
+	// the container are measured after the LaunchSeparator, and they can
+	// only be measured after it.
+	// TODO: Add support for other post-separator container data
+	postSeparator := cosTlv.EventType == cel.WorkloadMeasurementType || cosTlv.EventType == cel.RestartCountType || cosTlv.EventType == cel.TerminationType
+	if b.seenSeparator != postSeparator {
+		if b.seenSeparator {
+			return fmt.Errorf("found COS Event Type %v after LaunchSeparator event", cosTlv.EventType)
 		}
+		return fmt.Errorf("found COS Event Type %v before LaunchSeparator event", cosTlv.EventType)
+	}
 
-		// TODO: Add support for post-separator container data
-		if seenSeparator {
-			return nil, fmt.Errorf("found COS Event Type %v after LaunchSeparator event", cosTlv.EventType)
+	switch cosTlv.EventType {
+	case cel.ImageRefType:
+		if container.GetImageReference() != "" {
+			return fmt.Errorf("found more than one ImageRef event")
 		}
+		container.ImageReference = string(cosTlv.EventContent)
 
-		switch cosTlv.EventType {
-		case cel.ImageRefType:
-			if cosState.Container.GetImageReference() != "" {
-				return nil, fmt.Errorf("found more than one ImageRef event")
-			}
-			cosState.Container.ImageReference = string(cosTlv.EventContent)
+	case cel.ImageDigestType:
+		if container.GetImageDigest() != "" {
+			return fmt.Errorf("found more than one ImageDigest event")
+		}
+		container.ImageDigest = string(cosTlv.EventContent)
 
-		case cel.ImageDigestType:
-			if cosState.Container.GetImageDigest() != "" {
-				return nil, fmt.Errorf("found more than one ImageDigest event")
-			}
-			cosState.Container.ImageDigest = string(cosTlv.EventContent)
+	case cel.RestartPolicyType:
+		restartPolicy, ok := pb.RestartPolicy_value[string(cosTlv.EventContent)]
+		if !ok {
+			return fmt.Errorf("unknown restart policy in COS eventlog: %s", string(cosTlv.EventContent))
+		}
+		container.RestartPolicy = pb.RestartPolicy(restartPolicy)
 
-		case cel.RestartPolicyType:
-			restartPolicy, ok := pb.RestartPolicy_value[string(cosTlv.EventContent)]
-			if !ok {
-				return nil, fmt.Errorf("unknown restart policy in COS eventlog: %s", string(cosTlv.EventContent))
-			}
-			cosState.Container.RestartPolicy = pb.RestartPolicy(restartPolicy)
+	case cel.ImageIDType:
+		if container.GetImageId() != "" {
+			return fmt.Errorf("found more than one ImageId event")
+		}
+		container.ImageId = string(cosTlv.EventContent)
 
-		case cel.ImageIDType:
-			if cosState.Container.GetImageId() != "" {
-				return nil, fmt.Errorf("found more than one ImageId event")
-			}
-			cosState.Container.ImageId = string(cosTlv.EventContent)
+	case cel.ImageSignerType:
+		if container.GetImageSigner() != "" {
+			return fmt.Errorf("found more than one ImageSigner event")
+		}
+		signer, err := cel.ParseImageSigner(cosTlv.EventContent)
+		if err != nil {
+			return err
+		}
+		container.ImageSigner = signer
 
-		case cel.EnvVarType:
-			envName, envVal, err := cel.ParseEnvVar(string(cosTlv.EventContent))
-			if err != nil {
-				return nil, err
-			}
-			cosState.Container.EnvVars[envName] = envVal
+	case cel.EnvVarType:
+		envName, envVal, err := cel.ParseEnvVar(string(cosTlv.EventContent))
+		if err != nil {
+			return err
+		}
+		container.EnvVars[envName] = envVal
 
-		case cel.ArgType:
-			cosState.Container.Args = append(cosState.Container.Args, string(cosTlv.EventContent))
+	case cel.ArgType:
+		container.Args = append(container.Args, string(cosTlv.EventContent))
 
-		case cel.OverrideArgType:
-			cosState.Container.OverriddenArgs = append(cosState.Container.OverriddenArgs, string(cosTlv.EventContent))
+	case cel.OverrideArgType:
+		container.OverriddenArgs = append(container.OverriddenArgs, string(cosTlv.EventContent))
 
-		case cel.OverrideEnvType:
-			envName, envVal, err := cel.ParseEnvVar(string(cosTlv.EventContent))
-			if err != nil {
-				return nil, err
-			}
-			cosState.Container.OverriddenEnvVars[envName] = envVal
-		case cel.LaunchSeparatorType:
-			seenSeparator = true
-		default:
-			return nil, fmt.Errorf("found unknown COS Event Type %v", cosTlv.EventType)
+	case cel.OverrideEnvType:
+		envName, envVal, err := cel.ParseEnvVar(string(cosTlv.EventContent))
+		if err != nil {
+			return err
 		}
-
+		container.OverriddenEnvVars[envName] = envVal
+	case cel.MountType:
+		m, err := cel.ParseMount(cosTlv.EventContent)
+		if err != nil {
+			return err
+		}
+		container.Mounts = append(container.Mounts, &pb.Mount{
+			Type:        m.Type,
+			Source:      m.Source,
+			Destination: m.Destination,
+			ReadOnly:    m.ReadOnly,
+			SizeBytes:   m.SizeBytes,
+		})
+	case cel.CapabilityType:
+		capability, err := cel.ParseCapability(cosTlv.EventContent)
+		if err != nil {
+			return err
+		}
+		container.AddedCapabilities = append(container.AddedCapabilities, capability)
+	case cel.HostNetworkType:
+		if b.seenHostNetwork {
+			return fmt.Errorf("found more than one HostNetwork event")
+		}
+		hostNetwork, err := cel.ParseHostNetwork(cosTlv.EventContent)
+		if err != nil {
+			return err
+		}
+		container.HostNetwork = hostNetwork
+		b.seenHostNetwork = true
+	case cel.MemoryLimitType:
+		if container.GetMemoryLimitMb() != 0 {
+			return fmt.Errorf("found more than one MemoryLimit event")
+		}
+		limitMB, err := cel.ParseMemoryLimit(cosTlv.EventContent)
+		if err != nil {
+			return err
+		}
+		container.MemoryLimitMb = limitMB
+	case cel.ContainerIndexType:
+		index, err := cel.ParseContainerIndex(cosTlv.EventContent)
+		if err != nil {
+			return err
+		}
+		// Sidecars are measured in order, each at most once.
+		if index != len(cosState.Containers) {
+			return fmt.Errorf("found container index %d, want %d", index, len(cosState.Containers))
+		}
+		b.container = newContainerState()
+		b.seenHostNetwork = false
+		cosState.Containers = append(cosState.Containers, b.container)
+	case cel.LaunchSpecSignerType:
+		if cosState.GetLaunchSpecSigner() != "" {
+			return fmt.Errorf("found more than one LaunchSpecSigner event")
+		}
+		signer, err := cel.ParseLaunchSpecSigner(cosTlv.EventContent)
+		if err != nil {
+			return err
+		}
+		cosState.LaunchSpecSigner = signer
+	case cel.LaunchSeparatorType:
+		b.seenSeparator = true
+	case cel.WorkloadMeasurementType:
+		measurementType, content, err := cel.ParseWorkloadMeasurement(cosTlv.EventContent)
+		if err != nil {
+			return err
+		}
+		cosState.WorkloadMeasurements = append(cosState.WorkloadMeasurements, &pb.WorkloadMeasurement{Type: measurementType, Content: content})
+	case cel.RestartCountType:
+		count, err := cel.ParseRestartCount(cosTlv.EventContent)
+		if err != nil {
+			return err
+		}
+		// Only the main container is restarted, once at a time.
+		if count != int(cosState.Container.GetRestartCount())+1 {
+			return fmt.Errorf("found restart count %d, want %d", count, cosState.Container.GetRestartCount()+1)
+		}
+		cosState.Container.RestartCount = uint32(count)
+	case cel.TerminationType:
+		if cosState.GetTerminationSignal() != "" {
+			return fmt.Errorf("found more than one Termination event")
+		}
+		signal, err := cel.ParseTerminationSignal(cosTlv.EventContent)
+		if err != nil {
+			return err
+		}
+		cosState.TerminationSignal = signal
+	default:
+		return fmt.Errorf("found unknown COS Event Type %v", cosTlv.EventType)
 	}
-	return cosState, nil
+	return nil
 }
 
 func getPlatformState(hash crypto.Hash, events []*pb.Event) (*pb.PlatformState, error) {
@@ -373,6 +523,163 @@ func getSecureBootState(attestEvents []attest.Event) (*pb.SecureBootState, error
 	}, nil
 }
 
+// uefiVariableData is a parsed UEFI_VARIABLE_DATA structure, as defined in
+// the TCG PC Client Platform Firmware Profile Specification.
+type uefiVariableData struct {
+	GUID []byte
+	Name string
+	Data []byte
+}
+
+func parseUEFIVariableData(raw []byte) (*uefiVariableData, error) {
+	var header struct {
+		VariableName       [16]byte
+		UnicodeNameLength  uint64
+		VariableDataLength uint64
+	}
+	r := bytes.NewReader(raw)
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("failed to read UEFI_VARIABLE_DATA header: %v", err)
+	}
+	if header.UnicodeNameLength > uint64(r.Len())/2 {
+		return nil, fmt.Errorf("UEFI variable name length (%d) exceeds event size", header.UnicodeNameLength)
+	}
+	name := make([]uint16, header.UnicodeNameLength)
+	if err := binary.Read(r, binary.LittleEndian, name); err != nil {
+		return nil, fmt.Errorf("failed to read UEFI variable name: %v", err)
+	}
+	// Some versions of shim log an extra trailing byte, so we only require
+	// the data to fit within the event.
+	if header.VariableDataLength > uint64(r.Len()) {
+		return nil, fmt.Errorf("UEFI variable data length (%d) exceeds event size", header.VariableDataLength)
+	}
+	data := make([]byte, header.VariableDataLength)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("failed to read UEFI variable data: %v", err)
+	}
+	return &uefiVariableData{
+		GUID: header.VariableName[:],
+		Name: string(utf16.Decode(name)),
+		Data: data,
+	}, nil
+}
+
+// parseSignatureData parses the EFI_SIGNATURE_DATA measured in an authority
+// event, returning either the certificate or the hash it contains.
+func parseSignatureData(data []byte) (*x509.Certificate, []byte, error) {
+	// Shim measures its built-in vendor certificate without the
+	// SignatureOwner GUID.
+	if cert, err := x509.ParseCertificate(data); err == nil {
+		return cert, nil, nil
+	}
+	const ownerLen = 16
+	if len(data) <= ownerLen {
+		return nil, nil, fmt.Errorf("signature data too short (%d bytes)", len(data))
+	}
+	sig := data[ownerLen:]
+	if cert, err := x509.ParseCertificate(sig); err == nil {
+		return cert, nil, nil
+	}
+	switch len(sig) {
+	case crypto.SHA1.Size(), crypto.SHA256.Size(), crypto.SHA384.Size(), crypto.SHA512.Size():
+		return nil, sig, nil
+	}
+	return nil, nil, fmt.Errorf("signature data is neither a certificate nor a hash (%d bytes)", len(sig))
+}
+
+func getShimState(hash crypto.Hash, events []*pb.Event) (*pb.ShimState, error) {
+	var mokCerts, vendorCerts []x509.Certificate
+	var mokHashes, vendorHashes [][]byte
+	state := &pb.ShimState{}
+	seen := false
+
+	hasher := hash.New()
+	hasher.Write([]byte{1})
+	trustedDigest := hasher.Sum(nil)
+
+	for idx, event := range events {
+		index := event.GetPcrIndex()
+		switch {
+		case index == 14 && event.GetUntrustedType() == IPL:
+			// Shim logs MOK variables into PCR14 with the variable name as the
+			// event data and the digest of the variable contents.
+			switch string(bytes.TrimRight(event.GetData(), "\x00")) {
+			case "MokList":
+				state.MokListDigest = event.GetDigest()
+			case "MokListX":
+				state.MokListXDigest = event.GetDigest()
+			case "MokListTrusted":
+				state.MokListTrusted = bytes.Equal(event.GetDigest(), trustedDigest)
+			default:
+				continue
+			}
+			seen = true
+
+		case index == 7 && event.GetUntrustedType() == EFIVariableAuthority:
+			v, err := parseUEFIVariableData(event.GetData())
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse authority event %d: %v", idx, err)
+			}
+			isShimVar := bytes.Equal(v.GUID, shimLockGUID)
+			isVendorDB := bytes.Equal(v.GUID, imageSecurityDatabaseGUID) && v.Name == "vendor_db"
+			if !isShimVar && !isVendorDB {
+				continue
+			}
+			if !event.GetDigestVerified() && !shimDigestWorkaround(hash, event) {
+				return nil, fmt.Errorf("invalid digest for shim authority %q in event %d", v.Name, idx)
+			}
+			seen = true
+
+			switch {
+			case isShimVar && v.Name == "SbatLevel":
+				state.SbatLevel = string(v.Data)
+			case isShimVar && v.Name == "MokListTrusted":
+				state.MokListTrusted = len(v.Data) == 1 && v.Data[0] == 1
+			case isShimVar && (v.Name == "MokList" || v.Name == "MokListRT"):
+				cert, digest, err := parseSignatureData(v.Data)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse MOK authority in event %d: %v", idx, err)
+				}
+				if cert != nil {
+					mokCerts = append(mokCerts, *cert)
+				} else {
+					mokHashes = append(mokHashes, digest)
+				}
+			case isVendorDB || (isShimVar && v.Name == "Shim"):
+				cert, digest, err := parseSignatureData(v.Data)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse vendor authority in event %d: %v", idx, err)
+				}
+				if cert != nil {
+					vendorCerts = append(vendorCerts, *cert)
+				} else {
+					vendorHashes = append(vendorHashes, digest)
+				}
+			}
+		}
+	}
+	if !seen {
+		return nil, nil
+	}
+	state.MokAuthority = convertToPbDatabase(mokCerts, mokHashes)
+	state.VendorAuthority = convertToPbDatabase(vendorCerts, vendorHashes)
+	return state, nil
+}
+
+// shimDigestWorkaround handles versions of shim which do not carry
+// https://github.com/rhboot/shim/commit/8a27a4809a6a2b40fb6a4049071bf96d6ad71b50
+// and log an erroneous additional byte in authority events, which breaks
+// digest verification.
+func shimDigestWorkaround(hash crypto.Hash, event *pb.Event) bool {
+	data := event.GetData()
+	if len(data) == 0 {
+		return false
+	}
+	hasher := hash.New()
+	hasher.Write(data[:len(data)-1])
+	return bytes.Equal(event.GetDigest(), hasher.Sum(nil))
+}
+
 func getGrubState(hash crypto.Hash, events []*pb.Event) (*pb.GrubState, error) {
 	var files []*pb.GrubFile
 	var commands []string
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/rand"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -509,6 +511,98 @@ func TestParseSecureBootState(t *testing.T) {
 	}
 }
 
+func TestParseShimState(t *testing.T) {
+	logs := []struct {
+		eventLog
+		name          string
+		wantShim      bool
+		vendorCerts   int
+		wantSbatLevel string
+	}{
+		{Debian10GCE, "Debian10GCE", true, 1, ""},
+		{Rhel8GCE, "Rhel8GCE", true, 1, ""},
+		{UbuntuAmdSevGCE, "UbuntuAmdSevGCE", false, 0, ""},
+		{COS101AmdSev, "COS101AmdSev", true, 0, "sbat,1,2021030218\n"},
+	}
+	for _, log := range logs {
+		for _, bank := range log.Banks {
+			hashName := pb.HashAlgo_name[int32(bank.Hash)]
+			subtestName := fmt.Sprintf("%s-%s", log.name, hashName)
+			t.Run(subtestName, func(t *testing.T) {
+				msState, err := parsePCClientEventLog(log.RawLog, bank, UnsupportedLoader)
+				if err != nil {
+					t.Fatalf("failed to parse and replay log: %v", err)
+				}
+				shim := msState.GetShim()
+				if (shim != nil) != log.wantShim {
+					t.Fatalf("got ShimState %v, want present: %v", shim, log.wantShim)
+				}
+				if got := len(shim.GetVendorAuthority().GetCerts()); got != log.vendorCerts {
+					t.Errorf("got %d vendor authority certs, want %d", got, log.vendorCerts)
+				}
+				if len(shim.GetMokAuthority().GetCerts()) != 0 || len(shim.GetMokAuthority().GetHashes()) != 0 {
+					t.Errorf("found unexpected MOK authority: %v", shim.GetMokAuthority())
+				}
+				if shim.GetSbatLevel() != log.wantSbatLevel {
+					t.Errorf("got SBAT level %q, want %q", shim.GetSbatLevel(), log.wantSbatLevel)
+				}
+				if _, ok := bank.GetPcrs()[14]; ok && len(shim.GetMokListDigest()) == 0 {
+					t.Error("expected a MokList digest")
+				}
+			})
+		}
+	}
+}
+
+func TestGetShimStateMokAuthority(t *testing.T) {
+	hash := crypto.SHA256
+	digest := func(data []byte) []byte {
+		h := hash.New()
+		h.Write(data)
+		return h.Sum(nil)
+	}
+	mokHash := digest([]byte("grubx64.efi"))
+	authority := uefiVariableDataBytes(shimLockGUID, "MokListRT", append(make([]byte, 16), mokHash...))
+
+	events := []*attestpb.Event{
+		{PcrIndex: 14, UntrustedType: IPL, Data: []byte("MokList\x00"), Digest: digest([]byte("mok"))},
+		{PcrIndex: 14, UntrustedType: IPL, Data: []byte("MokListTrusted\x00"), Digest: digest([]byte{1})},
+		{PcrIndex: 7, UntrustedType: EFIVariableAuthority, Data: authority, Digest: digest(authority), DigestVerified: true},
+	}
+	shim, err := getShimState(hash, events)
+	if err != nil {
+		t.Fatalf("getShimState() failed: %v", err)
+	}
+	if !shim.GetMokListTrusted() {
+		t.Error("expected MokListTrusted to be set")
+	}
+	if !bytes.Equal(shim.GetMokListDigest(), events[0].Digest) {
+		t.Errorf("got MokList digest %x, want %x", shim.GetMokListDigest(), events[0].Digest)
+	}
+	if diff := cmp.Diff(shim.GetMokAuthority().GetHashes(), [][]byte{mokHash}); diff != "" {
+		t.Errorf("unexpected MOK authority hashes:\n%v", diff)
+	}
+
+	// Tampering with the authority event must be detected.
+	events[2].DigestVerified = false
+	events[2].Digest = digest([]byte("bad"))
+	if _, err := getShimState(hash, events); err == nil {
+		t.Error("expected getShimState to fail on an unverified authority event")
+	}
+}
+
+func uefiVariableDataBytes(guid []byte, name string, data []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(guid)
+	binary.Write(&buf, binary.LittleEndian, uint64(len(name)))
+	binary.Write(&buf, binary.LittleEndian, uint64(len(data)))
+	for _, c := range name {
+		binary.Write(&buf, binary.LittleEndian, uint16(c))
+	}
+	buf.Write(data)
+	return buf.Bytes()
+}
+
 func TestParsingCELEventLog(t *testing.T) {
 	test.SkipForRealTPM(t)
 	tpm := test.GetTPM(t)
@@ -549,6 +643,22 @@ func TestParsingCELEventLog(t *testing.T) {
 	}
 
 	// Secondly, append some real COS events to the CEL. This time we should get content in the CosState.
+	bindMount, err := cel.FormatMount(cel.Mount{Type: "bind", Source: "/mnt/disks/data", Destination: "/data", ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpfsMount, err := cel.FormatMount(cel.Mount{Type: "tmpfs", Destination: "/scratch", SizeBytes: 1 << 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	capability, err := cel.FormatCapability("CAP_NET_ADMIN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	memoryLimit, err := cel.FormatMemoryLimit(512)
+	if err != nil {
+		t.Fatal(err)
+	}
 	testCELEvents := []struct {
 		cosNestedEventType cel.CosType
 		pcr                int
@@ -565,6 +675,11 @@ func TestParsingCELEventLog(t *testing.T) {
 		{cel.ArgType, cel.CosEventPCR, []byte("--x")},
 		{cel.ArgType, cel.CosEventPCR, []byte("--y")},
 		{cel.ArgType, cel.CosEventPCR, []byte("")},
+		{cel.MountType, cel.CosEventPCR, bindMount},
+		{cel.MountType, cel.CosEventPCR, tmpfsMount},
+		{cel.CapabilityType, cel.CosEventPCR, capability},
+		{cel.HostNetworkType, cel.CosEventPCR, cel.FormatHostNetwork(true)},
+		{cel.MemoryLimitType, cel.CosEventPCR, memoryLimit},
 	}
 
 	expectedEnvVars := make(map[string]string)
@@ -580,6 +695,13 @@ func TestParsingCELEventLog(t *testing.T) {
 		ImageId:        string(testCELEvents[3].eventPayload),
 		EnvVars:        expectedEnvVars,
 		Args:           []string{string(testCELEvents[8].eventPayload), string(testCELEvents[9].eventPayload), string(testCELEvents[10].eventPayload)},
+		Mounts: []*attestpb.Mount{
+			{Type: "bind", Source: "/mnt/disks/data", Destination: "/data", ReadOnly: true},
+			{Type: "tmpfs", Destination: "/scratch", SizeBytes: 1 << 30},
+		},
+		AddedCapabilities: []string{"CAP_NET_ADMIN"},
+		HostNetwork:       true,
+		MemoryLimitMb:     512,
 	}
 	for _, testEvent := range testCELEvents {
 		cos := cel.CosTlv{EventType: testEvent.cosNestedEventType, EventContent: testEvent.eventPayload}
@@ -639,6 +761,497 @@ func TestParsingCELEventLog(t *testing.T) {
 	}
 }
 
+func TestParsingCELWorkloadMeasurements(t *testing.T) {
+	test.SkipForRealTPM(t)
+	hashes := []crypto.Hash{crypto.SHA1, crypto.SHA256}
+	modelDigest, err := cel.FormatWorkloadMeasurement("model_digest", []byte("sha256:abcd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile, err := cel.FormatWorkloadMeasurement("config", []byte("key: value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		events  []cel.CosTlv
+		want    []*attestpb.WorkloadMeasurement
+		wantErr bool
+	}{
+		{
+			"AfterSeparator",
+			[]cel.CosTlv{
+				{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")},
+				{EventType: cel.LaunchSeparatorType},
+				{EventType: cel.WorkloadMeasurementType, EventContent: modelDigest},
+				{EventType: cel.WorkloadMeasurementType, EventContent: configFile},
+			},
+			[]*attestpb.WorkloadMeasurement{
+				{Type: "model_digest", Content: []byte("sha256:abcd")},
+				{Type: "config", Content: []byte("key: value")},
+			},
+			false,
+		},
+		{
+			"BeforeSeparator",
+			[]cel.CosTlv{
+				{EventType: cel.WorkloadMeasurementType, EventContent: modelDigest},
+				{EventType: cel.LaunchSeparatorType},
+			},
+			nil,
+			true,
+		},
+		{
+			"Malformed",
+			[]cel.CosTlv{
+				{EventType: cel.LaunchSeparatorType},
+				{EventType: cel.WorkloadMeasurementType, EventContent: []byte("model_digest=sha256:abcd")},
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			coscel := &cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEvent(tpm, cel.CosEventPCR, hashes, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := coscel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseCanonicalEventLog() got err %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(msState.GetCos().GetWorkloadMeasurements(), tc.want, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected workload measurements:\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParsingCELSigners(t *testing.T) {
+	test.SkipForRealTPM(t)
+	hashes := []crypto.Hash{crypto.SHA1, crypto.SHA256}
+	signer := "sha256:" + strings.Repeat("ab", 32)
+
+	signerTypes := []struct {
+		name      string
+		eventType cel.CosType
+		get       func(*attestpb.AttestedCosState) string
+	}{
+		{"LaunchSpecSigner", cel.LaunchSpecSignerType, (*attestpb.AttestedCosState).GetLaunchSpecSigner},
+		{"ImageSigner", cel.ImageSignerType, func(s *attestpb.AttestedCosState) string { return s.GetContainer().GetImageSigner() }},
+	}
+	for _, st := range signerTypes {
+		tests := []struct {
+			name    string
+			events  []cel.CosTlv
+			want    string
+			wantErr bool
+		}{
+			{
+				"Signed",
+				[]cel.CosTlv{
+					{EventType: st.eventType, EventContent: []byte(signer)},
+					{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")},
+					{EventType: cel.LaunchSeparatorType},
+				},
+				signer,
+				false,
+			},
+			{
+				"Unsigned",
+				[]cel.CosTlv{
+					{EventType: cel.ImageRefType, EventContent: []byte("docker.io/bazel/experimental/test:latest")},
+					{EventType: cel.LaunchSeparatorType},
+				},
+				"",
+				false,
+			},
+			{
+				"AfterSeparator",
+				[]cel.CosTlv{
+					{EventType: cel.LaunchSeparatorType},
+					{EventType: st.eventType, EventContent: []byte(signer)},
+				},
+				"",
+				true,
+			},
+			{
+				"MoreThanOne",
+				[]cel.CosTlv{
+					{EventType: st.eventType, EventContent: []byte(signer)},
+					{EventType: st.eventType, EventContent: []byte(signer)},
+					{EventType: cel.LaunchSeparatorType},
+				},
+				"",
+				true,
+			},
+			{
+				"Malformed",
+				[]cel.CosTlv{
+					{EventType: st.eventType, EventContent: []byte("operator@example.com")},
+					{EventType: cel.LaunchSeparatorType},
+				},
+				"",
+				true,
+			},
+		}
+		for _, tc := range tests {
+			t.Run(st.name+"/"+tc.name, func(t *testing.T) {
+				tpm := test.GetTPM(t)
+				defer client.CheckedClose(t, tpm)
+
+				coscel := &cel.CEL{}
+				for _, event := range tc.events {
+					if err := coscel.AppendEvent(tpm, cel.CosEventPCR, hashes, event); err != nil {
+						t.Fatal(err)
+					}
+				}
+				var buf bytes.Buffer
+				if err := coscel.EncodeCEL(&buf); err != nil {
+					t.Fatal(err)
+				}
+				pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs)
+				if (err != nil) != tc.wantErr {
+					t.Fatalf("parseCanonicalEventLog() got err %v, wantErr %v", err, tc.wantErr)
+				}
+				if got := st.get(msState.GetCos()); got != tc.want {
+					t.Errorf("got %s %q, want %q", st.name, got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestParsingCELSidecars(t *testing.T) {
+	test.SkipForRealTPM(t)
+	hashes := []crypto.Hash{crypto.SHA1, crypto.SHA256}
+	index := func(i int) []byte {
+		content, err := cel.FormatContainerIndex(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+
+	tests := []struct {
+		name    string
+		events  []cel.CosTlv
+		want    []string
+		wantErr bool
+	}{
+		{
+			"NoSidecars",
+			[]cel.CosTlv{
+				{EventType: cel.ImageRefType, EventContent: []byte("docker.io/library/workload:latest")},
+				{EventType: cel.LaunchSeparatorType},
+			},
+			[]string{"docker.io/library/workload:latest"},
+			false,
+		},
+		{
+			"Sidecars",
+			[]cel.CosTlv{
+				{EventType: cel.ImageRefType, EventContent: []byte("docker.io/library/workload:latest")},
+				{EventType: cel.ContainerIndexType, EventContent: index(1)},
+				{EventType: cel.ImageRefType, EventContent: []byte("docker.io/envoyproxy/envoy:v1.27")},
+				{EventType: cel.ContainerIndexType, EventContent: index(2)},
+				{EventType: cel.ImageRefType, EventContent: []byte("docker.io/fluent/fluent-bit:2.1")},
+				{EventType: cel.LaunchSeparatorType},
+			},
+			[]string{"docker.io/library/workload:latest", "docker.io/envoyproxy/envoy:v1.27", "docker.io/fluent/fluent-bit:2.1"},
+			false,
+		},
+		{
+			"SkippedIndex",
+			[]cel.CosTlv{
+				{EventType: cel.ContainerIndexType, EventContent: index(2)},
+				{EventType: cel.LaunchSeparatorType},
+			},
+			nil,
+			true,
+		},
+		{
+			"RepeatedIndex",
+			[]cel.CosTlv{
+				{EventType: cel.ContainerIndexType, EventContent: index(1)},
+				{EventType: cel.ContainerIndexType, EventContent: index(1)},
+				{EventType: cel.LaunchSeparatorType},
+			},
+			nil,
+			true,
+		},
+		{
+			"MoreThanOneImageRef",
+			[]cel.CosTlv{
+				{EventType: cel.ContainerIndexType, EventContent: index(1)},
+				{EventType: cel.ImageRefType, EventContent: []byte("docker.io/envoyproxy/envoy:v1.27")},
+				{EventType: cel.ImageRefType, EventContent: []byte("docker.io/envoyproxy/envoy:v1.28")},
+				{EventType: cel.LaunchSeparatorType},
+			},
+			nil,
+			true,
+		},
+		{
+			"AfterSeparator",
+			[]cel.CosTlv{
+				{EventType: cel.LaunchSeparatorType},
+				{EventType: cel.ContainerIndexType, EventContent: index(1)},
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			coscel := &cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEvent(tpm, cel.CosEventPCR, hashes, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := coscel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseCanonicalEventLog() got err %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var got []string
+			for _, container := range msState.GetCos().GetContainers() {
+				got = append(got, container.GetImageReference())
+			}
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("unexpected container image references:\n%v", diff)
+			}
+			if msState.GetCos().GetContainers()[0] != msState.GetCos().GetContainer() {
+				t.Error("the first container is not the main container")
+			}
+		})
+	}
+}
+
+func TestParsingCELRestartCount(t *testing.T) {
+	test.SkipForRealTPM(t)
+	hashes := []crypto.Hash{crypto.SHA1, crypto.SHA256}
+	count := func(n int) []byte {
+		content, err := cel.FormatRestartCount(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+	sidecarIndex, err := cel.FormatContainerIndex(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		events  []cel.CosTlv
+		want    uint32
+		wantErr bool
+	}{
+		{
+			"NotRestarted",
+			[]cel.CosTlv{
+				{EventType: cel.LaunchSeparatorType},
+			},
+			0,
+			false,
+		},
+		{
+			"Restarted",
+			[]cel.CosTlv{
+				{EventType: cel.ContainerIndexType, EventContent: sidecarIndex},
+				{EventType: cel.LaunchSeparatorType},
+				{EventType: cel.RestartCountType, EventContent: count(1)},
+				{EventType: cel.RestartCountType, EventContent: count(2)},
+			},
+			2,
+			false,
+		},
+		{
+			"SkippedCount",
+			[]cel.CosTlv{
+				{EventType: cel.LaunchSeparatorType},
+				{EventType: cel.RestartCountType, EventContent: count(2)},
+			},
+			0,
+			true,
+		},
+		{
+			"BeforeSeparator",
+			[]cel.CosTlv{
+				{EventType: cel.RestartCountType, EventContent: count(1)},
+				{EventType: cel.LaunchSeparatorType},
+			},
+			0,
+			true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			coscel := &cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEvent(tpm, cel.CosEventPCR, hashes, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := coscel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseCanonicalEventLog() got err %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := msState.GetCos().GetContainer().GetRestartCount(); got != tc.want {
+				t.Errorf("got restart count %d, want %d", got, tc.want)
+			}
+			for _, sidecar := range msState.GetCos().GetContainers()[1:] {
+				if sidecar.GetRestartCount() != 0 {
+					t.Errorf("got sidecar restart count %d, want 0", sidecar.GetRestartCount())
+				}
+			}
+		})
+	}
+}
+
+func TestParsingCELTermination(t *testing.T) {
+	test.SkipForRealTPM(t)
+	hashes := []crypto.Hash{crypto.SHA1, crypto.SHA256}
+	sigterm, err := cel.FormatTerminationSignal("SIGTERM")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		events  []cel.CosTlv
+		want    string
+		wantErr bool
+	}{
+		{
+			"Running",
+			[]cel.CosTlv{
+				{EventType: cel.LaunchSeparatorType},
+			},
+			"",
+			false,
+		},
+		{
+			"Terminated",
+			[]cel.CosTlv{
+				{EventType: cel.LaunchSeparatorType},
+				{EventType: cel.TerminationType, EventContent: sigterm},
+			},
+			"SIGTERM",
+			false,
+		},
+		{
+			"BeforeSeparator",
+			[]cel.CosTlv{
+				{EventType: cel.TerminationType, EventContent: sigterm},
+				{EventType: cel.LaunchSeparatorType},
+			},
+			"",
+			true,
+		},
+		{
+			"MoreThanOne",
+			[]cel.CosTlv{
+				{EventType: cel.LaunchSeparatorType},
+				{EventType: cel.TerminationType, EventContent: sigterm},
+				{EventType: cel.TerminationType, EventContent: sigterm},
+			},
+			"",
+			true,
+		},
+		{
+			"Malformed",
+			[]cel.CosTlv{
+				{EventType: cel.LaunchSeparatorType},
+				{EventType: cel.TerminationType, EventContent: []byte("15")},
+			},
+			"",
+			true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tpm := test.GetTPM(t)
+			defer client.CheckedClose(t, tpm)
+
+			coscel := &cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEvent(tpm, cel.CosEventPCR, hashes, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := coscel.EncodeCEL(&buf); err != nil {
+				t.Fatal(err)
+			}
+			pcrs, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msState, err := parseCanonicalEventLog(buf.Bytes(), pcrs)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseCanonicalEventLog() got err %v, wantErr %v", err, tc.wantErr)
+			}
+			if got := msState.GetCos().GetTerminationSignal(); got != tc.want {
+				t.Errorf("got termination signal %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func generateNonCosCelEvent(hashAlgoList []crypto.Hash) (cel.Record, error) {
 	randRecord := cel.Record{}
 	randRecord.RecNum = 0
@@ -0,0 +1,426 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// IMAPCR is the PCR that Linux IMA extends its measurements into.
+const IMAPCR = 10
+
+// IMA template names supported when parsing the runtime measurement log.
+const (
+	imaTemplate    = "ima"
+	imaNGTemplate  = "ima-ng"
+	imaSigTemplate = "ima-sig"
+)
+
+const (
+	// The "ima" template hashes the file name as a zero-padded buffer of
+	// IMA_EVENT_NAME_LEN_MAX + 1 bytes.
+	imaEventNameLen = 256
+	// Sanity limits for lengths read from the binary log.
+	maxIMATemplateNameLen = 255
+	maxIMATemplateDataLen = 1 << 20
+)
+
+// imaEntry is a single entry of the IMA runtime measurement list.
+type imaEntry struct {
+	pcr uint32
+	// The SHA-1 template hash recorded in the log. It is all zeros for
+	// measurement violations.
+	templateHash []byte
+	templateName string
+	// The data hashed to compute the template hash.
+	templateData []byte
+	// The parsed template data, nil for unsupported templates.
+	file *pb.IMAFile
+}
+
+func (e *imaEntry) isViolation() bool {
+	return bytes.Equal(e.templateHash, make([]byte, sha1.Size))
+}
+
+// parseIMAEventLog parses a Linux IMA runtime measurement log (in either the
+// binary or ASCII format) and replays it against PCR10 of the given PCRs. It
+// returns a MachineState containing the verified IMAState.
+//
+// As the IMA log keeps growing after the PCRs are quoted, only the entries up
+// to the point where the replay matches PCR10 are verified, later entries are
+// dropped. An empty log returns an empty MachineState.
+func parseIMAEventLog(rawIMALog []byte, pcrs *tpmpb.PCRs) (*pb.MachineState, error) {
+	if len(rawIMALog) == 0 {
+		return &pb.MachineState{}, nil
+	}
+	entries, err := parseIMAEntries(rawIMALog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse IMA event log: %v", err)
+	}
+	verified, err := replayIMAEntries(entries, pcrs)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &pb.IMAState{}
+	for _, entry := range verified {
+		if entry.isViolation() || entry.file == nil {
+			continue
+		}
+		state.Files = append(state.Files, entry.file)
+	}
+	return &pb.MachineState{Ima: state}, nil
+}
+
+// replayIMAEntries replays the IMA entries against PCR10 and returns the
+// entries covered by the PCR value.
+//
+// Kernels since 5.8 extend each PCR bank with the template data hashed using
+// the bank's algorithm, while older kernels extend all banks with the SHA-1
+// template hash, zero-padded to the bank's digest size. Both are attempted.
+func replayIMAEntries(entries []imaEntry, pcrs *tpmpb.PCRs) ([]imaEntry, error) {
+	pcrValue, ok := pcrs.GetPcrs()[IMAPCR]
+	if !ok {
+		return nil, fmt.Errorf("PCR%d is missing from the provided PCRs", IMAPCR)
+	}
+	hash, err := tpm2.Algorithm(pcrs.GetHash()).Hash()
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		if entry.pcr != IMAPCR {
+			return nil, fmt.Errorf("IMA entry %d was measured into PCR%d, only PCR%d is supported", i, entry.pcr, IMAPCR)
+		}
+		if entry.isViolation() {
+			continue
+		}
+		if templateHash := sha1.Sum(entry.templateData); !bytes.Equal(templateHash[:], entry.templateHash) {
+			return nil, fmt.Errorf("IMA entry %d template hash %x does not match its template data", i, entry.templateHash)
+		}
+	}
+
+	nativeDigest := func(e *imaEntry) []byte {
+		hasher := hash.New()
+		hasher.Write(e.templateData)
+		return hasher.Sum(nil)
+	}
+	paddedDigest := func(e *imaEntry) []byte {
+		digest := make([]byte, hash.Size())
+		copy(digest, e.templateHash)
+		return digest
+	}
+	for _, digestFn := range []func(*imaEntry) []byte{nativeDigest, paddedDigest} {
+		if n, ok := replayIMADigests(hash, entries, pcrValue, digestFn); ok {
+			return entries[:n], nil
+		}
+	}
+	return nil, fmt.Errorf("IMA event log replay failed to match PCR%d: %x", IMAPCR, pcrValue)
+}
+
+// replayIMADigests returns the number of entries needed for the replay to
+// match pcrValue, and whether the replay ever matched.
+func replayIMADigests(hash crypto.Hash, entries []imaEntry, pcrValue []byte, digestFn func(*imaEntry) []byte) (int, bool) {
+	violationDigest := bytes.Repeat([]byte{0xff}, hash.Size())
+	current := make([]byte, hash.Size())
+	if bytes.Equal(current, pcrValue) {
+		return 0, true
+	}
+	for i := range entries {
+		digest := violationDigest
+		if !entries[i].isViolation() {
+			digest = digestFn(&entries[i])
+		}
+		hasher := hash.New()
+		hasher.Write(current)
+		hasher.Write(digest)
+		current = hasher.Sum(nil)
+		if bytes.Equal(current, pcrValue) {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// parseIMAEntries detects the format of the IMA log and parses its entries.
+func parseIMAEntries(rawIMALog []byte) ([]imaEntry, error) {
+	// The binary format starts with a little-endian PCR index, while the
+	// ASCII format starts with the PCR index as a decimal string.
+	if len(rawIMALog) >= 4 && binary.LittleEndian.Uint32(rawIMALog) < 24 {
+		return parseBinaryIMAEntries(rawIMALog)
+	}
+	return parseASCIIIMAEntries(rawIMALog)
+}
+
+func parseBinaryIMAEntries(rawIMALog []byte) ([]imaEntry, error) {
+	var entries []imaEntry
+	r := bytes.NewReader(rawIMALog)
+	for r.Len() > 0 {
+		var header struct {
+			PCR          uint32
+			TemplateHash [sha1.Size]byte
+			NameLen      uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+			return nil, fmt.Errorf("entry %d: failed to read header: %v", len(entries), err)
+		}
+		if header.NameLen > maxIMATemplateNameLen {
+			return nil, fmt.Errorf("entry %d: template name length (%d) is too large", len(entries), header.NameLen)
+		}
+		name := make([]byte, header.NameLen)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return nil, fmt.Errorf("entry %d: failed to read template name: %v", len(entries), err)
+		}
+		entry := imaEntry{
+			pcr:          header.PCR,
+			templateHash: header.TemplateHash[:],
+			templateName: string(name),
+		}
+
+		var err error
+		if entry.templateName == imaTemplate {
+			err = readLegacyIMATemplate(r, &entry)
+		} else {
+			err = readIMATemplate(r, &entry)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %v", len(entries), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// readLegacyIMATemplate reads the data of an "ima" template entry, which
+// (unlike all other templates) has no template data length, and a digest
+// without a field length.
+func readLegacyIMATemplate(r *bytes.Reader, entry *imaEntry) error {
+	digest := make([]byte, sha1.Size)
+	if _, err := io.ReadFull(r, digest); err != nil {
+		return fmt.Errorf("failed to read file digest: %v", err)
+	}
+	var nameLen uint32
+	if err := binary.Read(r, binary.LittleEndian, &nameLen); err != nil {
+		return fmt.Errorf("failed to read file name length: %v", err)
+	}
+	if nameLen >= imaEventNameLen {
+		return fmt.Errorf("file name length (%d) is too large", nameLen)
+	}
+	fileName := make([]byte, nameLen)
+	if _, err := io.ReadFull(r, fileName); err != nil {
+		return fmt.Errorf("failed to read file name: %v", err)
+	}
+	entry.templateData, entry.file = legacyIMATemplateData(digest, string(fileName))
+	return nil
+}
+
+func legacyIMATemplateData(digest []byte, fileName string) ([]byte, *pb.IMAFile) {
+	data := make([]byte, len(digest)+imaEventNameLen)
+	copy(data, digest)
+	copy(data[len(digest):], fileName)
+	return data, &pb.IMAFile{
+		TemplateName:    imaTemplate,
+		DigestAlgorithm: "sha1",
+		Digest:          digest,
+		Path:            fileName,
+	}
+}
+
+func readIMATemplate(r *bytes.Reader, entry *imaEntry) error {
+	var dataLen uint32
+	if err := binary.Read(r, binary.LittleEndian, &dataLen); err != nil {
+		return fmt.Errorf("failed to read template data length: %v", err)
+	}
+	if dataLen > maxIMATemplateDataLen || int64(dataLen) > int64(r.Len()) {
+		return fmt.Errorf("template data length (%d) is too large", dataLen)
+	}
+	entry.templateData = make([]byte, dataLen)
+	if _, err := io.ReadFull(r, entry.templateData); err != nil {
+		return fmt.Errorf("failed to read template data: %v", err)
+	}
+	if entry.templateName != imaNGTemplate && entry.templateName != imaSigTemplate {
+		// Other templates are replayed, but their contents are not parsed.
+		return nil
+	}
+
+	fields, err := splitIMATemplateFields(entry.templateData)
+	if err != nil {
+		return err
+	}
+	wantFields := 2
+	if entry.templateName == imaSigTemplate {
+		wantFields = 3
+	}
+	if len(fields) != wantFields {
+		return fmt.Errorf("%s template has %d fields, expected %d", entry.templateName, len(fields), wantFields)
+	}
+	algo, digest, err := parseIMADigestNG(fields[0])
+	if err != nil {
+		return err
+	}
+	entry.file = &pb.IMAFile{
+		TemplateName:    entry.templateName,
+		DigestAlgorithm: algo,
+		Digest:          digest,
+		Path:            string(bytes.TrimSuffix(fields[1], []byte{0})),
+	}
+	if entry.templateName == imaSigTemplate && len(fields[2]) != 0 {
+		entry.file.Signature = fields[2]
+	}
+	return nil
+}
+
+// splitIMATemplateFields splits template data into its length-prefixed fields.
+func splitIMATemplateFields(data []byte) ([][]byte, error) {
+	var fields [][]byte
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, errors.New("truncated template field length")
+		}
+		fieldLen := binary.LittleEndian.Uint32(data)
+		data = data[4:]
+		if uint64(fieldLen) > uint64(len(data)) {
+			return nil, fmt.Errorf("template field length (%d) exceeds template data", fieldLen)
+		}
+		fields = append(fields, data[:fieldLen])
+		data = data[fieldLen:]
+	}
+	return fields, nil
+}
+
+// parseIMADigestNG parses a "d-ng" template field, which is the hash
+// algorithm name followed by ":\x00" and the raw digest.
+func parseIMADigestNG(field []byte) (string, []byte, error) {
+	sep := bytes.Index(field, []byte(":\x00"))
+	if sep < 0 {
+		// Digests without an algorithm prefix are SHA-1.
+		if len(field) == sha1.Size {
+			return "sha1", field, nil
+		}
+		return "", nil, errors.New("d-ng field is missing the hash algorithm")
+	}
+	return string(field[:sep]), field[sep+2:], nil
+}
+
+// imaTemplateField encodes a single length-prefixed template field.
+func imaTemplateField(data []byte) []byte {
+	field := make([]byte, 4, 4+len(data))
+	binary.LittleEndian.PutUint32(field, uint32(len(data)))
+	return append(field, data...)
+}
+
+func imaNGTemplateData(algo string, digest []byte, fileName string, sig []byte, withSig bool) []byte {
+	var data []byte
+	data = append(data, imaTemplateField(append([]byte(algo+":\x00"), digest...))...)
+	data = append(data, imaTemplateField(append([]byte(fileName), 0))...)
+	if withSig {
+		data = append(data, imaTemplateField(sig)...)
+	}
+	return data
+}
+
+// parseASCIIIMAEntries parses the ASCII IMA log, where each line is:
+//
+//	<PCR> <template hash> <template name> <template specific fields...>
+//
+// As file names can contain spaces, the template data is reconstructed for
+// each possible split of the line and checked against the template hash.
+func parseASCIIIMAEntries(rawIMALog []byte) ([]imaEntry, error) {
+	var entries []imaEntry
+	scanner := bufio.NewScanner(bytes.NewReader(rawIMALog))
+	scanner.Buffer(nil, maxIMATemplateDataLen)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		entry, err := parseASCIIIMAEntry(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", len(entries)+1, err)
+		}
+		entries = append(entries, *entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func parseASCIIIMAEntry(line string) (*imaEntry, error) {
+	parts := strings.SplitN(line, " ", 5)
+	if len(parts) != 5 {
+		return nil, errors.New("too few fields")
+	}
+	pcr, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PCR index: %v", err)
+	}
+	templateHash, err := hex.DecodeString(parts[1])
+	if err != nil || len(templateHash) != sha1.Size {
+		return nil, fmt.Errorf("invalid template hash %q", parts[1])
+	}
+	entry := &imaEntry{
+		pcr:          uint32(pcr),
+		templateHash: templateHash,
+		templateName: parts[2],
+	}
+	fileName := parts[4]
+
+	switch entry.templateName {
+	case imaTemplate:
+		digest, err := hex.DecodeString(parts[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid file digest: %v", err)
+		}
+		entry.templateData, entry.file = legacyIMATemplateData(digest, fileName)
+		return entry, nil
+	case imaNGTemplate, imaSigTemplate:
+		algoAndDigest := strings.SplitN(parts[3], ":", 2)
+		if len(algoAndDigest) != 2 {
+			return nil, fmt.Errorf("invalid file digest %q", parts[3])
+		}
+		algo := algoAndDigest[0]
+		digest, err := hex.DecodeString(algoAndDigest[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid file digest: %v", err)
+		}
+		entry.file = &pb.IMAFile{
+			TemplateName:    entry.templateName,
+			DigestAlgorithm: algo,
+			Digest:          digest,
+			Path:            fileName,
+		}
+		if entry.templateName == imaNGTemplate {
+			entry.templateData = imaNGTemplateData(algo, digest, fileName, nil, false)
+			return entry, nil
+		}
+
+		// An ima-sig entry may have a hex-encoded signature after the file
+		// name. Find the interpretation matching the template hash.
+		entry.templateData = imaNGTemplateData(algo, digest, fileName, nil, true)
+		if sep := strings.LastIndex(fileName, " "); sep >= 0 && !entry.isViolation() {
+			if sig, err := hex.DecodeString(fileName[sep+1:]); err == nil {
+				data := imaNGTemplateData(algo, digest, fileName[:sep], sig, true)
+				if hash := sha1.Sum(data); bytes.Equal(hash[:], templateHash) {
+					entry.templateData = data
+					entry.file.Path = fileName[:sep]
+					entry.file.Signature = sig
+				}
+			}
+		}
+		return entry, nil
+	default:
+		return nil, fmt.Errorf("unsupported IMA template %q in ASCII log", entry.templateName)
+	}
+}
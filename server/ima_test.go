@@ -0,0 +1,270 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type testIMAEntry struct {
+	template  string
+	algo      string
+	digest    []byte
+	path      string
+	sig       []byte
+	violation bool
+}
+
+var testIMAEntries = []testIMAEntry{
+	{template: "ima-ng", algo: "sha256", digest: getDigestHash("boot_aggregate"), path: "boot_aggregate"},
+	{template: "ima-sig", algo: "sha256", digest: getDigestHash("/usr/bin/bash"), path: "/usr/bin/bash"},
+	{template: "ima-sig", algo: "sha256", digest: getDigestHash("/usr/bin/my app"), path: "/usr/bin/my app", sig: []byte{0x03, 0x02, 0x04, 0xaa, 0xbb}},
+	{template: "ima", algo: "sha1", digest: sha1Digest("/etc/passwd"), path: "/etc/passwd"},
+	{template: "ima-ng", violation: true, algo: "sha256", digest: make([]byte, sha256.Size), path: "/var/log/messages"},
+	{template: "ima-ng", algo: "sha1", digest: sha1Digest("/usr/lib/libc.so"), path: "/usr/lib/libc.so"},
+}
+
+func sha1Digest(input string) []byte {
+	digest := sha1.Sum([]byte(input))
+	return digest[:]
+}
+
+func (e testIMAEntry) templateData() []byte {
+	if e.template == imaTemplate {
+		data, _ := legacyIMATemplateData(e.digest, e.path)
+		return data
+	}
+	return imaNGTemplateData(e.algo, e.digest, e.path, e.sig, e.template == imaSigTemplate)
+}
+
+func (e testIMAEntry) templateHash() []byte {
+	if e.violation {
+		return make([]byte, sha1.Size)
+	}
+	return sha1Digest(string(e.templateData()))
+}
+
+func (e testIMAEntry) file() *attestpb.IMAFile {
+	return &attestpb.IMAFile{TemplateName: e.template, DigestAlgorithm: e.algo, Digest: e.digest, Path: e.path, Signature: e.sig}
+}
+
+func encodeBinaryIMALog(entries []testIMAEntry) []byte {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		binary.Write(&buf, binary.LittleEndian, uint32(IMAPCR))
+		buf.Write(e.templateHash())
+		binary.Write(&buf, binary.LittleEndian, uint32(len(e.template)))
+		buf.WriteString(e.template)
+		if e.template == imaTemplate {
+			buf.Write(e.digest)
+			binary.Write(&buf, binary.LittleEndian, uint32(len(e.path)))
+			buf.WriteString(e.path)
+			continue
+		}
+		data := e.templateData()
+		binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+		buf.Write(data)
+	}
+	return buf.Bytes()
+}
+
+func encodeASCIIIMALog(entries []testIMAEntry) []byte {
+	var sb strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&sb, "%d %x %s ", IMAPCR, e.templateHash(), e.template)
+		if e.template == imaTemplate {
+			fmt.Fprintf(&sb, "%x %s\n", e.digest, e.path)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:%x %s", e.algo, e.digest, e.path)
+		if len(e.sig) != 0 {
+			fmt.Fprintf(&sb, " %s", hex.EncodeToString(e.sig))
+		}
+		sb.WriteString("\n")
+	}
+	return []byte(sb.String())
+}
+
+// imaPCRs computes the PCR10 value after extending the entries. If padded is
+// set, the zero-padded SHA-1 template hash is extended (as done by older
+// kernels) instead of the template data hashed with the bank's algorithm.
+func imaPCRs(hash crypto.Hash, entries []testIMAEntry, padded bool) *pb.PCRs {
+	pcr := make([]byte, hash.Size())
+	for _, e := range entries {
+		digest := make([]byte, hash.Size())
+		switch {
+		case e.violation:
+			digest = bytes.Repeat([]byte{0xff}, hash.Size())
+		case padded:
+			copy(digest, e.templateHash())
+		default:
+			hasher := hash.New()
+			hasher.Write(e.templateData())
+			digest = hasher.Sum(nil)
+		}
+		hasher := hash.New()
+		hasher.Write(pcr)
+		hasher.Write(digest)
+		pcr = hasher.Sum(nil)
+	}
+	alg, _ := tpm2.HashToAlgorithm(hash)
+	return &pb.PCRs{Hash: pb.HashAlgo(alg), Pcrs: map[uint32][]byte{IMAPCR: pcr}}
+}
+
+func wantIMAFiles(entries []testIMAEntry) []*attestpb.IMAFile {
+	var files []*attestpb.IMAFile
+	for _, e := range entries {
+		if !e.violation {
+			files = append(files, e.file())
+		}
+	}
+	return files
+}
+
+func TestParseIMAEventLog(t *testing.T) {
+	formats := []struct {
+		name   string
+		encode func([]testIMAEntry) []byte
+	}{
+		{"Binary", encodeBinaryIMALog},
+		{"ASCII", encodeASCIIIMALog},
+	}
+	for _, format := range formats {
+		for _, hash := range []crypto.Hash{crypto.SHA1, crypto.SHA256} {
+			for _, padded := range []bool{false, true} {
+				t.Run(fmt.Sprintf("%s-%v-padded=%v", format.name, hash, padded), func(t *testing.T) {
+					pcrs := imaPCRs(hash, testIMAEntries, padded)
+					state, err := parseIMAEventLog(format.encode(testIMAEntries), pcrs)
+					if err != nil {
+						t.Fatalf("parseIMAEventLog() failed: %v", err)
+					}
+					if diff := cmp.Diff(state.GetIma().GetFiles(), wantIMAFiles(testIMAEntries), protocmp.Transform()); diff != "" {
+						t.Errorf("unexpected IMA files:\n%v", diff)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestParseIMAEventLogIgnoresUnquotedEntries(t *testing.T) {
+	// Entries measured after the quote are present in the log, but are not
+	// covered by the PCR value.
+	quoted := testIMAEntries[:3]
+	pcrs := imaPCRs(crypto.SHA256, quoted, false)
+	state, err := parseIMAEventLog(encodeBinaryIMALog(testIMAEntries), pcrs)
+	if err != nil {
+		t.Fatalf("parseIMAEventLog() failed: %v", err)
+	}
+	if diff := cmp.Diff(state.GetIma().GetFiles(), wantIMAFiles(quoted), protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected IMA files:\n%v", diff)
+	}
+}
+
+func TestParseIMAEventLogFailures(t *testing.T) {
+	pcrs := imaPCRs(crypto.SHA256, testIMAEntries, false)
+
+	tampered := make([]testIMAEntry, len(testIMAEntries))
+	copy(tampered, testIMAEntries)
+	tampered[1].digest = getDigestHash("/usr/bin/evil")
+	// Keep the original template hash, so only the template data changes.
+	tamperedLog := encodeBinaryIMALog(tampered)
+	copy(tamperedLog[bytes.Index(tamperedLog, tampered[1].templateHash()):], testIMAEntries[1].templateHash())
+
+	cases := []struct {
+		name   string
+		rawLog []byte
+		pcrs   *pb.PCRs
+	}{
+		{"WrongPCR", encodeBinaryIMALog(testIMAEntries), imaPCRs(crypto.SHA256, testIMAEntries[1:], false)},
+		{"MissingPCR", encodeBinaryIMALog(testIMAEntries), &pb.PCRs{Hash: pb.HashAlgo_SHA256}},
+		{"TamperedTemplateData", tamperedLog, pcrs},
+		{"Truncated", encodeBinaryIMALog(testIMAEntries)[:100], pcrs},
+		{"BadASCII", []byte("10 not-a-hash ima-ng sha256:00 /bin/sh\n"), pcrs},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := parseIMAEventLog(c.rawLog, c.pcrs); err == nil {
+				t.Error("expected parseIMAEventLog() to fail")
+			}
+		})
+	}
+}
+
+type imaTPM struct {
+	io.ReadWriteCloser
+	eventLog []byte
+	imaLog   []byte
+}
+
+func (t imaTPM) EventLog() ([]byte, error) {
+	return t.eventLog, nil
+}
+
+func (t imaTPM) IMAEventLog() ([]byte, error) {
+	return t.imaLog, nil
+}
+
+func TestVerifyAttestationWithIMA(t *testing.T) {
+	test.SkipForRealTPM(t)
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	eventLog, err := client.GetEventLog(rwc)
+	if err != nil {
+		t.Fatalf("failed to get event log: %v", err)
+	}
+
+	for _, e := range testIMAEntries {
+		for _, hash := range measuredHashes {
+			hasher := hash.New()
+			hasher.Write(e.templateData())
+			digest := hasher.Sum(nil)
+			if e.violation {
+				digest = bytes.Repeat([]byte{0xff}, hash.Size())
+			}
+			alg, _ := tpm2.HashToAlgorithm(hash)
+			if err := tpm2.PCRExtend(rwc, tpmutil.Handle(IMAPCR), alg, digest, ""); err != nil {
+				t.Fatalf("failed to extend PCR%d: %v", IMAPCR, err)
+			}
+		}
+	}
+
+	tpm := imaTPM{rwc, eventLog, encodeBinaryIMALog(testIMAEntries)}
+	ak, err := client.AttestationKeyRSA(tpm)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+
+	nonce := []byte("super secret nonce")
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce, IncludeIMAEventLog: true})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	state, err := VerifyAttestation(attestation, VerifyOpts{
+		Nonce:      nonce,
+		TrustedAKs: []crypto.PublicKey{ak.PublicKey()},
+	})
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if diff := cmp.Diff(state.GetIma().GetFiles(), wantIMAFiles(testIMAEntries), protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected IMA files:\n%v", diff)
+	}
+}
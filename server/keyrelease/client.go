@@ -0,0 +1,159 @@
+package keyrelease
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-tpm-tools/client"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"google.golang.org/protobuf/proto"
+)
+
+// The JSON messages exchanged between the Client and the Server.
+type challengeResponse struct {
+	Name  string `json:"name"`
+	Nonce []byte `json:"nonce"`
+}
+
+type releaseRequest struct {
+	Challenge string `json:"challenge"`
+	// Secret is the name of the requested secret.
+	Secret string `json:"secret"`
+	// Attestation is the binary serialized attest.Attestation.
+	Attestation []byte `json:"attestation"`
+	// EKCert is the DER certificate of the EK the secret is released to.
+	EKCert []byte `json:"ek_cert"`
+}
+
+type releaseResponse struct {
+	// ImportBlob is the binary serialized tpm.ImportBlob.
+	ImportBlob []byte `json:"import_blob"`
+}
+
+// Client requests secrets from a key release Server.
+type Client struct {
+	addr       string
+	httpClient *http.Client
+}
+
+// NewClient returns a Client for the key release service at the given
+// address (e.g. "https://keys.example.com"). If httpClient is nil,
+// http.DefaultClient is used.
+func NewClient(addr string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{addr: strings.TrimSuffix(addr, "/"), httpClient: httpClient}
+}
+
+// Attester attests a TPM for the Client, and imports the secrets released to
+// its EK.
+type Attester interface {
+	// AttestationEvidence attests the TPM with its AK for the nonce.
+	AttestationEvidence(nonce []byte) (*attestpb.Attestation, error)
+	// EKCert returns the DER certificate of the EK.
+	EKCert() ([]byte, error)
+	// ImportSecret imports a secret released to the EK.
+	ImportSecret(*tpmpb.ImportBlob) ([]byte, error)
+}
+
+type keyAttester struct {
+	ak   *client.Key
+	ek   *client.Key
+	opts client.AttestOpts
+}
+
+// NewKeyAttester returns an Attester attesting with the AK and importing with
+// the EK. Both keys must have certificates. The opts.Nonce is set from the
+// challenge of the service, and the other options are passed to ak.Attest.
+func NewKeyAttester(ak *client.Key, ek *client.Key, opts client.AttestOpts) Attester {
+	return &keyAttester{ak, ek, opts}
+}
+
+func (a *keyAttester) AttestationEvidence(nonce []byte) (*attestpb.Attestation, error) {
+	opts := a.opts
+	opts.Nonce = nonce
+	return a.ak.Attest(opts)
+}
+
+func (a *keyAttester) EKCert() ([]byte, error) {
+	ekCert := a.ek.CertDERBytes()
+	if ekCert == nil {
+		return nil, errors.New("EK has no certificate")
+	}
+	return ekCert, nil
+}
+
+func (a *keyAttester) ImportSecret(blob *tpmpb.ImportBlob) ([]byte, error) {
+	return a.ek.Import(blob)
+}
+
+// ReleaseSecret attests the TPM for a challenge of the service, requests the
+// named secret for its EK, and imports it.
+func (c *Client) ReleaseSecret(ctx context.Context, name string, attester Attester) ([]byte, error) {
+	ekCert, err := attester.EKCert()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the EK certificate: %w", err)
+	}
+	var challenge challengeResponse
+	if err := c.post(ctx, challengesPath, struct{}{}, &challenge); err != nil {
+		return nil, fmt.Errorf("failed to create a challenge: %w", err)
+	}
+	attestation, err := attester.AttestationEvidence(challenge.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attest: %w", err)
+	}
+	attestationData, err := proto.Marshal(attestation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal attestation: %w", err)
+	}
+
+	req := releaseRequest{
+		Challenge:   challenge.Name,
+		Secret:      name,
+		Attestation: attestationData,
+		EKCert:      ekCert,
+	}
+	var resp releaseResponse
+	if err := c.post(ctx, releasePath, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to release secret %q: %w", name, err)
+	}
+	blob := &tpmpb.ImportBlob{}
+	if err := proto.Unmarshal(resp.ImportBlob, blob); err != nil {
+		return nil, fmt.Errorf("malformed import blob: %w", err)
+	}
+	secret, err := attester.ImportSecret(blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import secret %q: %w", name, err)
+	}
+	return secret, nil
+}
+
+func (c *Client) post(ctx context.Context, path string, req interface{}, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1024))
+		return fmt.Errorf("key release service returned %v: %s", httpResp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
@@ -0,0 +1,177 @@
+package keyrelease
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+)
+
+// certify sets the certificate of the key, issued by the CA for the
+// instance ID with the key usage.
+func certify(t *testing.T, ca *test.CA, key *client.Key, instanceID int64, usage x509.KeyUsage) {
+	t.Helper()
+	if err := key.SetCert(ca.IssueGCECert(t, key.PublicKey(), instanceID, usage)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// measureImage measures the image digest in the returned canonical event log.
+func measureImage(t *testing.T, tpm io.ReadWriteCloser, digest string) []byte {
+	t.Helper()
+	c := &cel.CEL{}
+	if err := c.AppendEvent(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256, crypto.SHA1}, cel.CosTlv{EventType: cel.ImageDigestType, EventContent: []byte(digest)}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := c.EncodeCEL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newTestServer(t *testing.T, ca *test.CA, secrets map[string]Secret) *httptest.Server {
+	t.Helper()
+	s, err := NewServer(Config{
+		Secrets:    secrets,
+		VerifyOpts: server.VerifyOpts{TrustedRootCerts: []*x509.Certificate{ca.Cert}},
+	})
+	if err != nil {
+		t.Fatalf("NewServer() failed: %v", err)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestReleaseSecret(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	ek, err := client.EndorsementKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+
+	ca := test.NewCA(t)
+	certify(t, ca, ak, 1, x509.KeyUsageDigitalSignature)
+	certify(t, ca, ek, 1, x509.KeyUsageKeyAgreement)
+	eventLog := measureImage(t, tpm, "sha256:good")
+	ts := newTestServer(t, ca, map[string]Secret{
+		"any-workload": {Value: []byte("first secret")},
+		"good-image":   {Value: []byte("second secret"), ImageDigests: []string{"sha256:good"}},
+		"other-image":  {Value: []byte("third secret"), ImageDigests: []string{"sha256:other"}},
+	})
+	c := NewClient(ts.URL, ts.Client())
+	attester := NewKeyAttester(ak, ek, client.AttestOpts{CanonicalEventLog: eventLog})
+
+	for name, want := range map[string]string{
+		"any-workload": "first secret",
+		"good-image":   "second secret",
+	} {
+		secret, err := c.ReleaseSecret(context.Background(), name, attester)
+		if err != nil {
+			t.Fatalf("ReleaseSecret(%q) failed: %v", name, err)
+		}
+		if string(secret) != want {
+			t.Errorf("ReleaseSecret(%q) got %q, want %q", name, secret, want)
+		}
+	}
+
+	for name, wantErr := range map[string]string{
+		"other-image": "403",
+		"unknown":     "404",
+	} {
+		_, err := c.ReleaseSecret(context.Background(), name, attester)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("ReleaseSecret(%q) got error %v, want a %s error", name, err, wantErr)
+		}
+	}
+}
+
+func TestReleaseSecretToOtherInstance(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	ek, err := client.EndorsementKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+
+	// The EK of another instance cannot receive the secrets attested by the
+	// AK.
+	ca := test.NewCA(t)
+	certify(t, ca, ak, 1, x509.KeyUsageDigitalSignature)
+	certify(t, ca, ek, 2, x509.KeyUsageKeyAgreement)
+	ts := newTestServer(t, ca, map[string]Secret{"secret": {Value: []byte("secret")}})
+	if _, err := NewClient(ts.URL, ts.Client()).ReleaseSecret(context.Background(), "secret", NewKeyAttester(ak, ek, client.AttestOpts{})); err == nil {
+		t.Error("ReleaseSecret() to the EK of another instance succeeded")
+	}
+}
+
+func TestReleaseSecretChallengeReuse(t *testing.T) {
+	ca := test.NewCA(t)
+	s, err := NewServer(Config{VerifyOpts: server.VerifyOpts{TrustedRootCerts: []*x509.Certificate{ca.Cert}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, _, err := s.challenges.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, rerr := s.release(name, "secret", &attestpb.Attestation{}, ca.Cert); rerr == nil || rerr.status != http.StatusNotFound {
+		t.Fatalf("release() got error %v, want an unknown secret error", rerr)
+	}
+	if _, rerr := s.release(name, "secret", &attestpb.Attestation{}, ca.Cert); rerr == nil || rerr.status != http.StatusBadRequest {
+		t.Errorf("release() with a used challenge got error %v, want a challenge error", rerr)
+	}
+
+	if _, err := NewServer(Config{}); err == nil {
+		t.Error("NewServer() without TrustedRootCerts succeeded")
+	}
+}
+
+func TestMaxChallenges(t *testing.T) {
+	ca := test.NewCA(t)
+	s, err := NewServer(Config{
+		VerifyOpts:    server.VerifyOpts{TrustedRootCerts: []*x509.Certificate{ca.Cert}},
+		MaxChallenges: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		resp, err := ts.Client().Post(ts.URL+challengesPath, "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("creating challenge %d got status %v, want %v", i, resp.Status, want)
+		}
+	}
+}
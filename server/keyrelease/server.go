@@ -0,0 +1,197 @@
+// Package keyrelease contains a key release service, releasing secrets to
+// attested TPMs without a cloud KMS, and the Client importing them. Secrets
+// are released with server.CreateAttestedImportBlob, as an ImportBlob only the
+// attested TPM can import, while it has the attested PCR values.
+package keyrelease
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-tpm-tools/internal/challenge"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"google.golang.org/protobuf/proto"
+)
+
+// HTTP paths served by the key release service.
+const (
+	challengesPath = "/v1/challenges"
+	releasePath    = "/v1/release"
+)
+
+const (
+	defaultChallengeLifetime = 5 * time.Minute
+	maxRequestSize           = 10 << 20
+)
+
+// Secret is a secret released by the Server.
+type Secret struct {
+	Value []byte
+	// Policy, if not nil, is evaluated against the verified MachineState.
+	Policy *pb.Policy
+	// ImageDigests, if not empty, are the container image digests (e.g.
+	// "sha256:...") of the workloads the secret is released to.
+	ImageDigests []string
+	// PCRs are the PCRs the secret is bound to, see server.ReleaseOpts.
+	PCRs []uint32
+}
+
+// Config configures a key release Server.
+type Config struct {
+	// Secrets are the secrets released by the Server, by name.
+	Secrets map[string]Secret
+	// VerifyOpts configures which AKs and EKs are trusted, and must set the
+	// TrustedRootCerts. The Nonce is set from the challenge of each request.
+	VerifyOpts server.VerifyOpts
+	// ChallengeLifetime is how long a challenge can be used after it was
+	// created. Defaults to 5 minutes.
+	ChallengeLifetime time.Duration
+	// MaxChallenges is the number of outstanding challenges, above which
+	// creating a challenge is refused. Defaults to
+	// challenge.DefaultMaxChallenges.
+	MaxChallenges int
+}
+
+// Server is a key release service. It is an http.Handler.
+type Server struct {
+	config     Config
+	mux        *http.ServeMux
+	challenges *challenge.Store
+}
+
+// NewServer creates a key release Server with the given config.
+func NewServer(config Config) (*Server, error) {
+	if len(config.VerifyOpts.TrustedRootCerts) == 0 {
+		return nil, errors.New("TrustedRootCerts are required to verify the EK certificates")
+	}
+	if config.ChallengeLifetime == 0 {
+		config.ChallengeLifetime = defaultChallengeLifetime
+	}
+	if config.MaxChallenges == 0 {
+		config.MaxChallenges = challenge.DefaultMaxChallenges
+	}
+
+	s := &Server{
+		config:     config,
+		mux:        http.NewServeMux(),
+		challenges: challenge.NewStore("challenges/", config.ChallengeLifetime, config.MaxChallenges),
+	}
+	s.mux.HandleFunc(challengesPath, s.handleCreateChallenge)
+	s.mux.HandleFunc(releasePath, s.handleRelease)
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleCreateChallenge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name, nonce, err := s.challenges.Create()
+	if errors.Is(err, challenge.ErrTooManyChallenges) {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, challengeResponse{Name: name, Nonce: nonce})
+}
+
+// requestError is an error releasing a secret, with the HTTP status
+// describing its cause.
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (s *Server) handleRelease(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req releaseRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode request: %v", err), http.StatusBadRequest)
+		return
+	}
+	attestation := &pb.Attestation{}
+	if err := proto.Unmarshal(req.Attestation, attestation); err != nil {
+		http.Error(w, fmt.Sprintf("failed to unmarshal attestation: %v", err), http.StatusBadRequest)
+		return
+	}
+	ekCert, err := x509.ParseCertificate(req.EKCert)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse EK certificate: %v", err), http.StatusBadRequest)
+		return
+	}
+	blob, rerr := s.release(req.Challenge, req.Secret, attestation, ekCert)
+	if rerr != nil {
+		http.Error(w, rerr.Error(), rerr.status)
+		return
+	}
+	writeJSON(w, releaseResponse{ImportBlob: blob})
+}
+
+// release releases the named secret to the TPM attested with the named
+// challenge, and returns the serialized tpm.ImportBlob.
+func (s *Server) release(challengeName string, secretName string, attestation *pb.Attestation, ekCert *x509.Certificate) ([]byte, *requestError) {
+	nonce, err := s.challenges.Use(challengeName)
+	if err != nil {
+		return nil, &requestError{http.StatusBadRequest, err}
+	}
+	secret, ok := s.config.Secrets[secretName]
+	if !ok {
+		return nil, &requestError{http.StatusNotFound, fmt.Errorf("unknown secret %q", secretName)}
+	}
+
+	opts := server.ReleaseOpts{
+		VerifyOpts: s.config.VerifyOpts,
+		Policy:     secret.Policy,
+		PCRs:       secret.PCRs,
+	}
+	opts.VerifyOpts.Nonce = nonce
+	blob, state, err := server.CreateAttestedImportBlob(attestation, ekCert, secret.Value, opts)
+	if err != nil {
+		return nil, &requestError{http.StatusForbidden, err}
+	}
+	if len(secret.ImageDigests) > 0 && !contains(secret.ImageDigests, state.GetCos().GetContainer().GetImageDigest()) {
+		return nil, &requestError{http.StatusForbidden, fmt.Errorf("secret %q is not released to image %q", secretName, state.GetCos().GetContainer().GetImageDigest())}
+	}
+
+	data, err := proto.Marshal(blob)
+	if err != nil {
+		return nil, &requestError{http.StatusInternalServerError, fmt.Errorf("failed to marshal import blob: %v", err)}
+	}
+	return data, nil
+}
+
+func contains(strs []string, target string) bool {
+	for _, s := range strs {
+		if s == target {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
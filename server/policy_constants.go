@@ -16,11 +16,12 @@ import (
 // Taken from TCG PC Client Platform Firmware Profile Specification,
 // Table 14 Events.
 const (
-	NoAction     uint32 = 0x00000003
-	Separator    uint32 = 0x00000004
-	SCRTMVersion uint32 = 0x00000008
-	NonhostInfo  uint32 = 0x00000011
-	IPL          uint32 = 0x0000000D
+	NoAction             uint32 = 0x00000003
+	Separator            uint32 = 0x00000004
+	SCRTMVersion         uint32 = 0x00000008
+	NonhostInfo          uint32 = 0x00000011
+	IPL                  uint32 = 0x0000000D
+	EFIVariableAuthority uint32 = 0x800000E0
 )
 
 var (
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-tpm/tpm2"
+
+	"github.com/google/go-tpm-tools/internal"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultReleasePCRs are the PCRs a released secret is bound to by default:
+// the firmware and boot PCRs, and the PCR of the COS events (13).
+var DefaultReleasePCRs = []uint32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 13}
+
+// tcgKpEKCertificateOID is the extended key usage of EK certificates, see the
+// TCG EK Credential Profile: https://oidref.com/2.23.133.8.1
+var tcgKpEKCertificateOID = asn1.ObjectIdentifier{2, 23, 133, 8, 1}
+
+// ReleaseOpts configures CreateAttestedImportBlob.
+type ReleaseOpts struct {
+	// VerifyOpts verifies the attestation. The TrustedRootCerts must be set,
+	// as they also verify the EK certificate.
+	VerifyOpts VerifyOpts
+	// Policy, if not nil, is evaluated against the verified MachineState.
+	Policy *pb.Policy
+	// PCRs are the attested PCRs the secret is bound to. If empty,
+	// DefaultReleasePCRs are used. The TPM must have the same PCR values
+	// when importing the secret.
+	PCRs []uint32
+}
+
+// CreateAttestedImportBlob releases the sensitive data to an attested TPM. It
+// performs the following checks:
+//   - the attestation verifies with opts.VerifyOpts (see VerifyAttestation)
+//   - the verified MachineState satisfies opts.Policy
+//   - the EK certificate chains to the TrustedRootCerts
+//   - the EK certificate is of an EK: a decryption key with the TCG EK
+//     extended key usage, and not the AK
+//   - the EK and AK certificates have the same GCE instance info, so the EK
+//     is of the attested TPM
+//
+// It returns the sensitive data in an ImportBlob bound to the EK and the
+// attested values of opts.PCRs (see CreateImportBlob), and the verified
+// MachineState.
+func CreateAttestedImportBlob(attestation *pb.Attestation, ekCert *x509.Certificate, sensitive []byte, opts ReleaseOpts) (*tpmpb.ImportBlob, *pb.MachineState, error) {
+	if len(opts.VerifyOpts.TrustedRootCerts) == 0 {
+		return nil, nil, errors.New("bad options: TrustedRootCerts are required to verify the EK certificate")
+	}
+	state, err := VerifyAttestation(attestation, opts.VerifyOpts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify attestation: %w", err)
+	}
+	if opts.Policy != nil {
+		if err := EvaluatePolicy(state, opts.Policy); err != nil {
+			return nil, nil, fmt.Errorf("attestation does not satisfy the policy: %w", err)
+		}
+	}
+
+	akInfo := state.GetPlatform().GetInstanceInfo()
+	if akInfo == nil {
+		return nil, nil, errors.New("AK certificate has no GCE instance info to bind the EK to")
+	}
+	ekOpts := opts.VerifyOpts
+	intermediates, err := parseCerts(attestation.GetIntermediateCerts())
+	if err != nil {
+		return nil, nil, fmt.Errorf("attestation intermediates: %w", err)
+	}
+	ekOpts.IntermediateCerts = append(intermediates, opts.VerifyOpts.IntermediateCerts...)
+	ekState, err := validateAKCert(ekCert, ekOpts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to validate EK certificate: %w", err)
+	}
+	akCert, err := x509.ParseCertificate(attestation.GetAkCert())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse AK certificate: %w", err)
+	}
+	if err := checkEKCert(ekCert, akCert); err != nil {
+		return nil, nil, err
+	}
+	if !proto.Equal(ekState.GetPlatform().GetInstanceInfo(), akInfo) {
+		return nil, nil, errors.New("EK certificate is not of the attested instance")
+	}
+
+	pcrs, err := attestedPCRs(attestation, akCert, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	blob, err := CreateImportBlob(ekCert.PublicKey.(crypto.PublicKey), sensitive, pcrs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create import blob: %w", err)
+	}
+	return blob, state, nil
+}
+
+// checkEKCert checks that the certificate is of an EK, and not of a signing
+// key like the AK. GCE certifies its AKs with the TCG EK extended key usage
+// too, so their key usage and public key tell them apart.
+func checkEKCert(ekCert *x509.Certificate, akCert *x509.Certificate) error {
+	hasEKUsage := false
+	for _, usage := range ekCert.UnknownExtKeyUsage {
+		if usage.Equal(tcgKpEKCertificateOID) {
+			hasEKUsage = true
+		}
+	}
+	if !hasEKUsage {
+		return fmt.Errorf("EK certificate does not have the EK extended key usage %v", tcgKpEKCertificateOID)
+	}
+	if ekCert.KeyUsage&x509.KeyUsageDigitalSignature != 0 {
+		return errors.New("EK certificate is of a signing key")
+	}
+	switch ekCert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if ekCert.KeyUsage&x509.KeyUsageKeyEncipherment == 0 {
+			return errors.New("RSA EK certificate does not have the key encipherment key usage")
+		}
+	case *ecdsa.PublicKey:
+		if ekCert.KeyUsage&x509.KeyUsageKeyAgreement == 0 {
+			return errors.New("ECC EK certificate does not have the key agreement key usage")
+		}
+	default:
+		return fmt.Errorf("unsupported EK type: %T", ekCert.PublicKey)
+	}
+	if internal.PubKeysEqual(ekCert.PublicKey, akCert.PublicKey) {
+		return errors.New("EK certificate is of the AK")
+	}
+	return nil
+}
+
+// attestedPCRs returns the values of the opts.PCRs from the first quote of
+// the attestation verified with the AK, in order of hash preference.
+func attestedPCRs(attestation *pb.Attestation, akCert *x509.Certificate, opts ReleaseOpts) (*tpmpb.PCRs, error) {
+	indexes := opts.PCRs
+	if len(indexes) == 0 {
+		indexes = DefaultReleasePCRs
+	}
+	for _, quote := range supportedQuotes(attestation.GetQuotes()) {
+		if !opts.VerifyOpts.AllowSHA1 && tpm2.Algorithm(quote.GetPcrs().GetHash()) == tpm2.AlgSHA1 {
+			continue
+		}
+		if err := internal.VerifyQuote(quote, akCert.PublicKey, opts.VerifyOpts.Nonce); err != nil {
+			continue
+		}
+		pcrs := &tpmpb.PCRs{Hash: quote.GetPcrs().GetHash(), Pcrs: make(map[uint32][]byte)}
+		for _, idx := range indexes {
+			val, ok := quote.GetPcrs().GetPcrs()[idx]
+			if !ok {
+				return nil, fmt.Errorf("attestation does not quote PCR %d", idx)
+			}
+			pcrs.Pcrs[idx] = val
+		}
+		return pcrs, nil
+	}
+	return nil, errors.New("attestation does not contain a verified quote")
+}
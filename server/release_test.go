@@ -0,0 +1,124 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"io"
+	"testing"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// attestWithCert returns an attestation of the TPM made with an AK
+// certified by the CA for the instance ID.
+func attestWithCert(t *testing.T, tpm io.ReadWriter, ca *test.CA, instanceID int64, nonce []byte) *attestpb.Attestation {
+	t.Helper()
+	ak, err := client.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	if err := ak.SetCert(ca.IssueGCECert(t, ak.PublicKey(), instanceID, x509.KeyUsageDigitalSignature)); err != nil {
+		t.Fatal(err)
+	}
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	return attestation
+}
+
+func TestCreateAttestedImportBlob(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	ek, err := client.EndorsementKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+
+	ca := test.NewCA(t)
+	nonce := []byte("super secret nonce")
+	attestation := attestWithCert(t, tpm, ca, 1, nonce)
+	secret := []byte("released secret")
+	opts := ReleaseOpts{VerifyOpts: VerifyOpts{Nonce: nonce, TrustedRootCerts: []*x509.Certificate{ca.Cert}}}
+
+	blob, state, err := CreateAttestedImportBlob(attestation, ca.IssueGCECert(t, ek.PublicKey(), 1, x509.KeyUsageKeyAgreement), secret, opts)
+	if err != nil {
+		t.Fatalf("CreateAttestedImportBlob() failed: %v", err)
+	}
+	if got := state.GetPlatform().GetInstanceInfo().GetInstanceId(); got != 1 {
+		t.Errorf("got instance ID %d, want 1", got)
+	}
+	if len(blob.GetPcrs().GetPcrs()) != len(DefaultReleasePCRs) {
+		t.Errorf("got blob bound to PCRs %v, want %v", blob.GetPcrs().GetPcrs(), DefaultReleasePCRs)
+	}
+	output, err := ek.Import(blob)
+	if err != nil {
+		t.Fatalf("failed to import the released secret: %v", err)
+	}
+	if !bytes.Equal(output, secret) {
+		t.Errorf("got secret %q, want %q", output, secret)
+	}
+
+	// A change of the bound PCRs after the attestation prevents the import.
+	if err := tpm2.PCREvent(tpm, tpmutil.Handle(13), []byte("other workload")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ek.Import(blob); err == nil {
+		t.Error("imported the released secret after PCR 13 changed")
+	}
+}
+
+func TestCreateAttestedImportBlobRefused(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	ek, err := client.EndorsementKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ek.Close()
+
+	ca := test.NewCA(t)
+	otherCA := test.NewCA(t)
+	nonce := []byte("super secret nonce")
+	attestation := attestWithCert(t, tpm, ca, 1, nonce)
+	ekCert := ca.IssueGCECert(t, ek.PublicKey(), 1, x509.KeyUsageKeyAgreement)
+	akCert, err := x509.ParseCertificate(attestation.GetAkCert())
+	if err != nil {
+		t.Fatal(err)
+	}
+	noEKUsage := ca.IssueGCECert(t, ek.PublicKey(), 1, x509.KeyUsageKeyAgreement)
+	noEKUsage.UnknownExtKeyUsage = nil
+	verifyOpts := VerifyOpts{Nonce: nonce, TrustedRootCerts: []*x509.Certificate{ca.Cert}}
+
+	testCases := []struct {
+		name        string
+		attestation *attestpb.Attestation
+		ekCert      *x509.Certificate
+		opts        ReleaseOpts
+	}{
+		{"WrongNonce", attestation, ekCert, ReleaseOpts{VerifyOpts: VerifyOpts{Nonce: []byte("other nonce"), TrustedRootCerts: []*x509.Certificate{ca.Cert}}}},
+		{"TrustedAKs", attestation, ekCert, ReleaseOpts{VerifyOpts: VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{ekCert.PublicKey}}}},
+		{"PolicyViolation", attestation, ekCert, ReleaseOpts{VerifyOpts: verifyOpts, Policy: &attestpb.Policy{Platform: &attestpb.PlatformPolicy{MinimumTechnology: attestpb.GCEConfidentialTechnology_AMD_SEV}}}},
+		{"UntrustedEKCert", attestation, otherCA.IssueGCECert(t, ek.PublicKey(), 1, x509.KeyUsageKeyAgreement), ReleaseOpts{VerifyOpts: verifyOpts}},
+		{"EKOfOtherInstance", attestation, ca.IssueGCECert(t, ek.PublicKey(), 2, x509.KeyUsageKeyAgreement), ReleaseOpts{VerifyOpts: verifyOpts}},
+		{"AKCert", attestation, akCert, ReleaseOpts{VerifyOpts: verifyOpts}},
+		{"SigningKeyCert", attestation, ca.IssueGCECert(t, ek.PublicKey(), 1, x509.KeyUsageDigitalSignature|x509.KeyUsageKeyAgreement), ReleaseOpts{VerifyOpts: verifyOpts}},
+		{"NoEKUsage", attestation, noEKUsage, ReleaseOpts{VerifyOpts: verifyOpts}},
+		{"UnquotedPCR", attestation, ekCert, ReleaseOpts{VerifyOpts: verifyOpts, PCRs: []uint32{24}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := CreateAttestedImportBlob(tc.attestation, tc.ekCert, []byte("secret"), tc.opts); err == nil {
+				t.Error("CreateAttestedImportBlob() succeeded")
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultKeySetTTL = time.Hour
+	// minRefreshInterval limits how often tokens with an unknown key ID can
+	// cause the key set to be fetched again.
+	minRefreshInterval = 10 * time.Second
+	maxJWKSSize        = 1 << 20
+)
+
+// KeySet provides the public keys that can sign claims tokens.
+type KeySet interface {
+	// Key returns the public key with the given key ID (the "kid" header of
+	// the token, which may be empty).
+	Key(ctx context.Context, keyID string) (crypto.PublicKey, error)
+}
+
+type staticKeySet map[string]crypto.PublicKey
+
+// StaticKeySet returns a KeySet with fixed keys, indexed by key ID. If it
+// contains a single key, that key is also used for tokens without a key ID.
+func StaticKeySet(keys map[string]crypto.PublicKey) KeySet {
+	return staticKeySet(keys)
+}
+
+func (s staticKeySet) Key(_ context.Context, keyID string) (crypto.PublicKey, error) {
+	if key, ok := s[keyID]; ok {
+		return key, nil
+	}
+	if keyID == "" && len(s) == 1 {
+		for _, key := range s {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key ID %q", keyID)
+}
+
+// RemoteKeySet is a KeySet fetched from a JWKS (RFC 7517) URL, such as the
+// jwks_uri of the issuer's OpenID configuration. Keys are cached, and the key
+// set is fetched again when the cache expires or a token is signed with an
+// unknown key.
+type RemoteKeySet struct {
+	url        string
+	httpClient *http.Client
+	ttl        time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	// fetching is the fetch in progress, if any. Concurrent lookups wait for
+	// it instead of fetching the key set again.
+	fetching *keySetFetch
+}
+
+type keySetFetch struct {
+	done chan struct{}
+	keys map[string]crypto.PublicKey
+	err  error
+}
+
+// NewRemoteKeySet returns a RemoteKeySet fetching the JWKS at the given URL.
+// If httpClient is nil, http.DefaultClient is used.
+func NewRemoteKeySet(jwksURL string, httpClient *http.Client) *RemoteKeySet {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &RemoteKeySet{url: jwksURL, httpClient: httpClient, ttl: defaultKeySetTTL}
+}
+
+// Key implements KeySet. The key set is fetched without holding the cache
+// lock, so lookups of cached keys are not blocked by a slow JWKS endpoint.
+func (r *RemoteKeySet) Key(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	r.mu.Lock()
+	age := time.Since(r.fetchedAt)
+	keys := r.keys
+	if _, ok := keys[keyID]; (ok && age < r.ttl) || (keys != nil && age < minRefreshInterval) {
+		r.mu.Unlock()
+		return lookupKey(keys, keyID)
+	}
+	f := r.fetching
+	if f == nil {
+		f = &keySetFetch{done: make(chan struct{})}
+		r.fetching = f
+		r.mu.Unlock()
+
+		f.keys, f.err = r.fetch(ctx)
+		r.mu.Lock()
+		if f.err == nil {
+			r.keys = f.keys
+			r.fetchedAt = time.Now()
+		}
+		r.fetching = nil
+		r.mu.Unlock()
+		close(f.done)
+	} else {
+		r.mu.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if f.err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS from %s: %v", r.url, f.err)
+	}
+	return lookupKey(f.keys, keyID)
+}
+
+func lookupKey(keys map[string]crypto.PublicKey, keyID string) (crypto.PublicKey, error) {
+	key, ok := keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", keyID)
+	}
+	return key, nil
+}
+
+func (r *RemoteKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %v", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// ParseJWKS parses the RSA and EC signing keys of a JSON Web Key Set,
+// indexed by key ID. Keys of other types or uses are ignored.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %v", err)
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsaKey()
+		case "EC":
+			key, err = k.ecKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK %q: %v", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+// MarshalJWKS returns the JSON Web Key Set of the RSA and EC public keys,
+// indexed by key ID, as parsed by ParseJWKS.
+func MarshalJWKS(keys map[string]crypto.PublicKey) ([]byte, error) {
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	jwks.Keys = []jwk{}
+	for kid, key := range keys {
+		k := jwk{Kid: kid, Use: "sig"}
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			k.Kty = "RSA"
+			k.N = encodeBigInt(key.N)
+			k.E = encodeBigInt(big.NewInt(int64(key.E)))
+		case *ecdsa.PublicKey:
+			k.Kty = "EC"
+			k.Crv = key.Curve.Params().Name
+			// The coordinates are padded to the size of the curve.
+			size := (key.Curve.Params().BitSize + 7) / 8
+			k.X = base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size)))
+			k.Y = base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size)))
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+		jwks.Keys = append(jwks.Keys, k)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return json.Marshal(jwks)
+}
+
+func (k jwk) rsaKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %v", err)
+	}
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %v", err)
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("unsupported exponent %v", e)
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jwk) ecKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("x coordinate: %v", err)
+	}
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y coordinate: %v", err)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("missing value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
@@ -0,0 +1,232 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func marshalJWKS(t *testing.T, keys map[string]crypto.PublicKey) []byte {
+	t.Helper()
+	data, err := MarshalJWKS(keys)
+	if err != nil {
+		t.Fatalf("MarshalJWKS() failed: %v", err)
+	}
+	return data
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]crypto.PublicKey{"rsa": rsaKey.Public(), "ec": ecKey.Public()}
+	keys, err := ParseJWKS(marshalJWKS(t, want))
+	if err != nil {
+		t.Fatalf("ParseJWKS() failed: %v", err)
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("got %d keys, want %d", len(keys), len(want))
+	}
+	for kid, key := range want {
+		if got, ok := keys[kid].(interface{ Equal(crypto.PublicKey) bool }); !ok || !got.Equal(key) {
+			t.Errorf("key %q was not parsed correctly", kid)
+		}
+	}
+}
+
+func TestParseJWKSIgnoresOtherKeys(t *testing.T) {
+	keys, err := ParseJWKS([]byte(`{"keys":[
+		{"kty":"oct","kid":"symmetric","k":"c2VjcmV0"},
+		{"kty":"RSA","kid":"encryption","use":"enc","n":"AQAB","e":"AQAB"}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseJWKS() failed: %v", err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("got keys %v, want none", keys)
+	}
+}
+
+func TestParseJWKSInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"NotJSON":        `keys`,
+		"MissingModulus": `{"keys":[{"kty":"RSA","kid":"a","e":"AQAB"}]}`,
+		"BadBase64":      `{"keys":[{"kty":"RSA","kid":"a","n":"!!","e":"AQAB"}]}`,
+		"SmallExponent":  `{"keys":[{"kty":"RSA","kid":"a","n":"AQAB","e":"AQ"}]}`,
+		"UnknownCurve":   `{"keys":[{"kty":"EC","kid":"a","crv":"P-192","x":"AQ","y":"AQ"}]}`,
+		"NotOnCurve":     `{"keys":[{"kty":"EC","kid":"a","crv":"P-256","x":"AQ","y":"AQ"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseJWKS([]byte(data)); err == nil {
+				t.Error("ParseJWKS() succeeded, want an error")
+			}
+		})
+	}
+}
+
+// jwksServer serves a JWKS which can be rotated, and counts the requests.
+type jwksServer struct {
+	mu       sync.Mutex
+	jwks     []byte
+	requests int
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.jwks)
+}
+
+func (s *jwksServer) set(jwks []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jwks = jwks
+}
+
+func (s *jwksServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &jwksServer{jwks: marshalJWKS(t, map[string]crypto.PublicKey{"old": oldKey.Public()})}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	keySet := NewRemoteKeySet(ts.URL, ts.Client())
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		key, err := keySet.Key(ctx, "old")
+		if err != nil {
+			t.Fatalf("Key() failed: %v", err)
+		}
+		if !oldKey.PublicKey.Equal(key) {
+			t.Errorf("got the wrong key")
+		}
+	}
+	if got := s.count(); got != 1 {
+		t.Errorf("got %d JWKS requests, want the keys to be cached", got)
+	}
+
+	// Rotate the keys. Unknown keys are only fetched again after the minimum
+	// refresh interval.
+	s.set(marshalJWKS(t, map[string]crypto.PublicKey{"new": newKey.Public()}))
+	if _, err := keySet.Key(ctx, "new"); err == nil {
+		t.Error("Key() returned a key before the refresh interval passed")
+	}
+	keySet.fetchedAt = keySet.fetchedAt.Add(-minRefreshInterval)
+	key, err := keySet.Key(ctx, "new")
+	if err != nil {
+		t.Fatalf("Key() failed after key rotation: %v", err)
+	}
+	if !newKey.PublicKey.Equal(key) {
+		t.Errorf("got the wrong key")
+	}
+	if got := s.count(); got != 2 {
+		t.Errorf("got %d JWKS requests, want 2", got)
+	}
+
+	// Expired key sets are fetched again.
+	keySet.fetchedAt = time.Now().Add(-defaultKeySetTTL)
+	if _, err := keySet.Key(ctx, "new"); err != nil {
+		t.Fatalf("Key() failed: %v", err)
+	}
+	if got := s.count(); got != 3 {
+		t.Errorf("got %d JWKS requests, want 3", got)
+	}
+}
+
+func TestRemoteKeySetFetchError(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	if _, err := NewRemoteKeySet(ts.URL, ts.Client()).Key(context.Background(), "kid"); err == nil {
+		t.Error("Key() succeeded, want an error")
+	}
+}
+
+func TestRemoteKeySetConcurrentFetch(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &jwksServer{jwks: marshalJWKS(t, map[string]crypto.PublicKey{"kid": key.Public()})}
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		s.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	keySet := NewRemoteKeySet(ts.URL, ts.Client())
+	ctx := context.Background()
+
+	const lookups = 5
+	errs := make(chan error, lookups)
+	for i := 0; i < lookups; i++ {
+		go func() {
+			_, err := keySet.Key(ctx, "kid")
+			errs <- err
+		}()
+	}
+	// Lookups waiting for the fetch give up when their context is done.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := keySet.Key(cancelled, "kid"); err == nil {
+		t.Error("Key() succeeded with a cancelled context while the JWKS was being fetched")
+	}
+
+	close(release)
+	for i := 0; i < lookups; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Key() failed: %v", err)
+		}
+	}
+	if got := s.count(); got != 1 {
+		t.Errorf("got %d JWKS requests, want concurrent lookups to share one", got)
+	}
+}
+
+func TestStaticKeySet(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	single := StaticKeySet(map[string]crypto.PublicKey{"kid": key.Public()})
+	for _, kid := range []string{"kid", ""} {
+		if _, err := single.Key(ctx, kid); err != nil {
+			t.Errorf("Key(%q) failed: %v", kid, err)
+		}
+	}
+	if _, err := single.Key(ctx, "other"); err == nil {
+		t.Error("Key() with an unknown key ID succeeded")
+	}
+
+	multiple := StaticKeySet(map[string]crypto.PublicKey{"a": key.Public(), "b": key.Public()})
+	if _, err := multiple.Key(ctx, ""); err == nil {
+		t.Error("Key() without a key ID succeeded with multiple keys")
+	}
+}
@@ -0,0 +1,100 @@
+// Package token verifies the claims tokens issued by attestation verifiers,
+// for services relying on the attestation of a launched container. It checks
+// the token signature against a key set, the issuer, audience and validity
+// period, and decodes the claims about the attested machine and container.
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/server"
+)
+
+// Claims are the claims of a verified token.
+type Claims struct {
+	jwt.RegisteredClaims
+	// Nonces supplied by the attester when it requested the token.
+	Nonces []string `json:"eat_nonce,omitempty"`
+	server.Claims
+}
+
+// signingMethods are the token signature algorithms accepted by a Verifier.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Options configure a Verifier.
+type Options struct {
+	// KeySet provides the keys the tokens can be signed with.
+	KeySet KeySet
+	// Issuer is the required "iss" claim.
+	Issuer string
+	// Audience is the required "aud" claim.
+	Audience string
+	// Leeway is the allowed clock skew when checking the validity period.
+	Leeway time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Verifier verifies claims tokens.
+type Verifier struct {
+	opts   Options
+	parser *jwt.Parser
+}
+
+// NewVerifier returns a Verifier with the given options.
+func NewVerifier(opts Options) (*Verifier, error) {
+	if opts.KeySet == nil {
+		return nil, errors.New("no key set provided")
+	}
+	if opts.Issuer == "" || opts.Audience == "" {
+		return nil, errors.New("the issuer and audience must be provided")
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Verifier{
+		opts:   opts,
+		parser: jwt.NewParser(jwt.WithValidMethods(signingMethods), jwt.WithoutClaimsValidation()),
+	}, nil
+}
+
+// Verify verifies the token and returns its claims. Checking the claims about
+// the machine and container (e.g. the image digest) is up to the caller.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	claims := &Claims{}
+	keyFunc := func(t *jwt.Token) (interface{}, error) {
+		keyID, _ := t.Header["kid"].(string)
+		return v.opts.KeySet.Key(ctx, keyID)
+	}
+	if _, err := v.parser.ParseWithClaims(token, claims, keyFunc); err != nil {
+		return nil, fmt.Errorf("invalid token: %v", err)
+	}
+	if err := v.validate(claims); err != nil {
+		return nil, fmt.Errorf("invalid token: %v", err)
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validate(claims *Claims) error {
+	if !claims.VerifyIssuer(v.opts.Issuer, true) {
+		return fmt.Errorf("got issuer %q, want %q", claims.Issuer, v.opts.Issuer)
+	}
+	if !claims.VerifyAudience(v.opts.Audience, true) {
+		return fmt.Errorf("got audience %v, want %q", claims.Audience, v.opts.Audience)
+	}
+	now := v.opts.Now()
+	if !claims.VerifyExpiresAt(now.Add(-v.opts.Leeway), true) {
+		return errors.New("token is expired or has no expiry")
+	}
+	if !claims.VerifyNotBefore(now.Add(v.opts.Leeway), false) {
+		return errors.New("token is not valid yet")
+	}
+	if !claims.VerifyIssuedAt(now.Add(v.opts.Leeway), false) {
+		return errors.New("token was issued in the future")
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testIssuer   = "https://confidentialcomputing.googleapis.com/"
+	testAudience = "https://relying.party"
+)
+
+func validClaims(now time.Time) *Claims {
+	return &Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Issuer:    testIssuer,
+		Audience:  jwt.ClaimStrings{testAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}}
+}
+
+func TestVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	v, err := NewVerifier(Options{
+		KeySet:   StaticKeySet(map[string]crypto.PublicKey{"kid": key.Public()}),
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		Leeway:   time.Minute,
+		Now:      func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(modify func(*Claims), kid string, signer *ecdsa.PrivateKey) string {
+		claims := validClaims(now)
+		modify(claims)
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	noop := func(*Claims) {}
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims(now)).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		token  string
+		errMsg string
+	}{
+		{"Valid", sign(noop, "kid", key), ""},
+		{"WithinLeeway", sign(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-30 * time.Second)) }, "kid", key), ""},
+		{"Expired", sign(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Hour)) }, "kid", key), "expired"},
+		{"NoExpiry", sign(func(c *Claims) { c.ExpiresAt = nil }, "kid", key), "no expiry"},
+		{"NotYetValid", sign(func(c *Claims) { c.NotBefore = jwt.NewNumericDate(now.Add(time.Hour)) }, "kid", key), "not valid yet"},
+		{"IssuedInFuture", sign(func(c *Claims) { c.IssuedAt = jwt.NewNumericDate(now.Add(time.Hour)) }, "kid", key), "issued in the future"},
+		{"WrongIssuer", sign(func(c *Claims) { c.Issuer = "https://evil.example.com" }, "kid", key), "issuer"},
+		{"WrongAudience", sign(func(c *Claims) { c.Audience = jwt.ClaimStrings{"https://other.party"} }, "kid", key), "audience"},
+		{"UnknownKeyID", sign(noop, "other", key), "unknown key ID"},
+		{"WrongKey", sign(noop, "kid", otherKey), "verification error"},
+		{"HMAC", hmacToken, "signing method HS256 is invalid"},
+		{"Malformed", "not.a.token", "invalid token"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), tc.token)
+			if tc.errMsg == "" {
+				if err != nil {
+					t.Errorf("Verify() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("Verify() got err %v, want an error containing %q", err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestNewVerifierRequiresOptions(t *testing.T) {
+	keySet := StaticKeySet(nil)
+	for _, opts := range []Options{
+		{Issuer: testIssuer, Audience: testAudience},
+		{KeySet: keySet, Audience: testAudience},
+		{KeySet: keySet, Issuer: testIssuer},
+	} {
+		if _, err := NewVerifier(opts); err == nil {
+			t.Errorf("NewVerifier(%+v) succeeded, want an error", opts)
+		}
+	}
+}
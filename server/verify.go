@@ -92,6 +92,7 @@ type gceInstanceInfo struct {
 //   - the provided PCR values match the quote data internal digest
 //   - the provided opts.Nonce matches that in the quote data
 //   - the provided eventlog matches the provided PCR values
+//   - the provided IMA event log (if any) matches the provided PCR10 value
 //
 // After this, the eventlog is parsed and the corresponding MachineState is
 // returned. This design prevents unverified MachineStates from being used.
@@ -164,6 +165,12 @@ func VerifyAttestation(attestation *pb.Attestation, opts VerifyOpts) (*pb.Machin
 			continue
 		}
 
+		imaState, err := parseIMAEventLog(attestation.GetImaEventLog(), pcrs)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to validate the IMA event log: %w", err)
+			continue
+		}
+
 		// Verify the PCR hash algorithm. We have this check here (instead of at
 		// the start of the loop) so that the user gets a "SHA-1 not supported"
 		// error only if allowing SHA-1 support would actually allow the log
@@ -174,6 +181,7 @@ func VerifyAttestation(attestation *pb.Attestation, opts VerifyOpts) (*pb.Machin
 		}
 
 		proto.Merge(machineState, celState)
+		proto.Merge(machineState, imaState)
 		proto.Merge(machineState, state)
 
 		return machineState, nil